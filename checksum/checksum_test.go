@@ -5,9 +5,11 @@ import (
 	"hash/crc32"
 	"hash/crc64"
 	"hash/fnv"
+	"hash/maphash"
 	"testing"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/skerkour/go-benchmarks/crypto/siphash"
 	"github.com/skerkour/go-benchmarks/utils"
 	"github.com/zeebo/xxh3"
 )
@@ -53,6 +55,91 @@ func benchmarkChecksumer[C Checksumer](size int64, algorithm string, checksumer
 	})
 }
 
+// KeyHasher hashes the keys of a hash table with a secret seed. The result is returned so the
+// compiler can't drop the call, which matters when a call lasts a few nanoseconds.
+type KeyHasher interface {
+	HashKey(key []byte) uint64
+}
+
+var hashKeySink uint64
+
+// BenchmarkHashKeys compares keyed hashes that resist hash flooding on the short inputs of
+// hash-table workloads, where the per-call latency matters more than the throughput.
+func BenchmarkHashKeys(b *testing.B) {
+	benchmarks := []int64{}
+	for size := int64(1); size <= 64; size++ {
+		benchmarks = append(benchmarks, size)
+	}
+	benchmarks = append(benchmarks, 96, 128)
+
+	key := utils.RandBytes(b, siphash.KeySize)
+
+	for _, size := range benchmarks {
+		benchmarkKeyHasher(size, "siphash-2-4", newSipHash24KeyHasher(key), b)
+		benchmarkKeyHasher(size, "siphash-1-3", newSipHash13KeyHasher(key), b)
+		benchmarkKeyHasher(size, "maphash", maphashKeyHasher{seed: maphash.MakeSeed()}, b)
+		benchmarkKeyHasher(size, "xxh3_seed", xxh3SeedKeyHasher{seed: 3}, b)
+	}
+}
+
+func benchmarkKeyHasher[H KeyHasher](size int64, algorithm string, hasher H, b *testing.B) {
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+		buf := utils.RandBytes(b, size)
+		var sum uint64
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			sum += hasher.HashKey(buf)
+		}
+		hashKeySink = sum
+	})
+}
+
+type sipHash24KeyHasher struct {
+	k0, k1 uint64
+}
+
+func newSipHash24KeyHasher(key []byte) sipHash24KeyHasher {
+	k0, k1 := siphash.Key(key)
+	return sipHash24KeyHasher{k0: k0, k1: k1}
+}
+
+func (hasher sipHash24KeyHasher) HashKey(key []byte) uint64 {
+	return siphash.Sum24(hasher.k0, hasher.k1, key)
+}
+
+type sipHash13KeyHasher struct {
+	k0, k1 uint64
+}
+
+func newSipHash13KeyHasher(key []byte) sipHash13KeyHasher {
+	k0, k1 := siphash.Key(key)
+	return sipHash13KeyHasher{k0: k0, k1: k1}
+}
+
+func (hasher sipHash13KeyHasher) HashKey(key []byte) uint64 {
+	return siphash.Sum13(hasher.k0, hasher.k1, key)
+}
+
+type maphashKeyHasher struct {
+	seed maphash.Seed
+}
+
+func (hasher maphashKeyHasher) HashKey(key []byte) uint64 {
+	return maphash.Bytes(hasher.seed, key)
+}
+
+// xxh3SeedKeyHasher is xxh3SeedChecksumer returning the hash. xxh3 is not a PRF: the seed makes
+// collisions harder to predict but doesn't prevent seed-independent collisions.
+type xxh3SeedKeyHasher struct {
+	seed uint64
+}
+
+func (hasher xxh3SeedKeyHasher) HashKey(key []byte) uint64 {
+	return xxh3.HashSeed(key, hasher.seed)
+}
+
 type crc32Checksumer struct{}
 
 func (crc32Checksumer) Checksum(input []byte) {
@@ -0,0 +1,123 @@
+package aesmac
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func mustDecode(s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+// Examples of section 4 of RFC 4493 (AES-128) and of appendix D of NIST SP 800-38B (AES-256).
+var cmacMessage = mustDecode("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+var cmacVectors = []struct {
+	key    string
+	msgLen int
+	tag    string
+}{
+	{"2b7e151628aed2a6abf7158809cf4f3c", 0, "bb1d6929e95937287fa37d129b756746"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 16, "070a16b46b4d4144f79bdd9dd04a287c"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 40, "dfa66747de9ae63030ca32611497c827"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 0, "028962f61b7bf89efc6b551f4667d983"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 16, "28a7023f452e8f82bd4bf28d8c37c35c"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 40, "aaf3d8f1de5640c232f5b169b9c911e6"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 64, "e1992190549f6ed5696a2c056c315410"},
+}
+
+func TestCMACVectors(t *testing.T) {
+	for i, v := range cmacVectors {
+		mac, err := NewAESCMAC(mustDecode(v.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := mustDecode(v.tag)
+		for _, writeSize := range []int{1, 15, 16, 17, 64} {
+			mac.Reset()
+			writeInChunks(mac, cmacMessage[:v.msgLen], writeSize)
+			if got := mac.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("#%d (write size %d): expected %x, got %x", i+1, writeSize, want, got)
+			}
+		}
+	}
+}
+
+func TestCMACSubkeys(t *testing.T) {
+	mac, err := NewAESCMAC(mustDecode("2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := mac.(*cmac)
+	if want := mustDecode("fbeed618357133667c85e08f7236a8de"); !bytes.Equal(c.k1[:], want) {
+		t.Errorf("K1: expected %x, got %x", want, c.k1)
+	}
+	if want := mustDecode("f7ddac306ae266ccf90bc11ee46d513b"); !bytes.Equal(c.k2[:], want) {
+		t.Errorf("K2: expected %x, got %x", want, c.k2)
+	}
+}
+
+func TestGMACVectors(t *testing.T) {
+	for i, v := range []struct {
+		key, nonce, data, tag string
+	}{
+		// test case 1 of the GCM specification
+		{"00000000000000000000000000000000", "000000000000000000000000", "", "58e2fccefa7e3061367f1d57a4e7455a"},
+		// from gcmEncryptExtIV128.rsp of the NIST CAVP
+		{"77be63708971c4e240d1cb79e8d77feb", "e0e00f19fed7ba0136a797f3", "7a43ec1d9c0a5a78a0b16533a6213cab", "209fcc8d3675ed938e9c7166709dd946"},
+	} {
+		mac, err := NewAESGMAC(mustDecode(v.key), mustDecode(v.nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mac.Write(mustDecode(v.data))
+		if got, want := mac.Sum(nil), mustDecode(v.tag); !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %x, got %x", i+1, want, got)
+		}
+	}
+}
+
+// TestGMACMatchesGCM checks GMAC against the tag of crypto/cipher GCM with an empty plaintext.
+func TestGMACMatchesGCM(t *testing.T) {
+	key := mustDecode("603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4")
+	nonce := mustDecode("cafebabefacedbaddecaf888")
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	mac, err := NewGMAC(block, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := range data {
+		want := gcm.Seal(nil, nonce, nil, data[:n])
+		for _, writeSize := range []int{1, 7, 16, 100} {
+			mac.Reset()
+			writeInChunks(mac, data[:n], writeSize)
+			if got := mac.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("len %d (write size %d): expected %x, got %x", n, writeSize, want, got)
+			}
+		}
+	}
+}
+
+func writeInChunks(h hash.Hash, data []byte, size int) {
+	for len(data) > 0 {
+		n := min(size, len(data))
+		h.Write(data[:n])
+		data = data[n:]
+	}
+}
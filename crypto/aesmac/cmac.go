@@ -0,0 +1,122 @@
+// Package aesmac implements the block cipher based MACs CMAC [1] and GMAC [2] as streaming
+// hash.Hash, on top of any 128-bit block cipher such as crypto/aes.
+//
+// [1] https://www.rfc-editor.org/rfc/rfc4493.html
+// [2] https://nvlpubs.nist.gov/nistpubs/Legacy/SP/nistspecialpublication800-38d.pdf
+package aesmac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"hash"
+)
+
+// Size is the size in bytes of CMAC and GMAC tags.
+const Size = 16
+
+const blockSize = 16
+
+var errBlockSize = errors.New("aesmac: the block cipher must have a 128-bit block size")
+
+type cmac struct {
+	block  cipher.Block
+	k1, k2 [blockSize]byte
+	x      [blockSize]byte
+	// buf holds the last block written: it is only processed when more data is written because
+	// the final block is masked with k1 or k2.
+	buf [blockSize]byte
+	n   int
+}
+
+var _ hash.Hash = (*cmac)(nil)
+
+// NewCMAC returns a CMAC computed with the given block cipher.
+//
+// specified in 2.3 of [1].
+func NewCMAC(block cipher.Block) (hash.Hash, error) {
+	if block.BlockSize() != blockSize {
+		return nil, errBlockSize
+	}
+	c := &cmac{block: block}
+
+	// subkeys generation, 2.3 of [1]
+	block.Encrypt(c.k1[:], c.k1[:])
+	msb := shiftLeft(&c.k1)
+	c.k1[blockSize-1] ^= msb * 0x87
+	c.k2 = c.k1
+	msb = shiftLeft(&c.k2)
+	c.k2[blockSize-1] ^= msb * 0x87
+
+	return c, nil
+}
+
+// NewAESCMAC returns an AES-CMAC using a 16, 24 or 32 bytes key.
+func NewAESCMAC(key []byte) (hash.Hash, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewCMAC(block)
+}
+
+func (c *cmac) Size() int {
+	return Size
+}
+
+func (c *cmac) BlockSize() int {
+	return blockSize
+}
+
+func (c *cmac) Reset() {
+	c.x = [blockSize]byte{}
+	c.n = 0
+}
+
+func (c *cmac) Write(p []byte) (int, error) {
+	written := len(p)
+
+	for len(p) > 0 {
+		if c.n == blockSize {
+			subtle.XORBytes(c.x[:], c.x[:], c.buf[:])
+			c.block.Encrypt(c.x[:], c.x[:])
+			c.n = 0
+		}
+		// full blocks that are not the last one are processed without copying them
+		for c.n == 0 && len(p) > blockSize {
+			subtle.XORBytes(c.x[:], c.x[:], p[:blockSize])
+			c.block.Encrypt(c.x[:], c.x[:])
+			p = p[blockSize:]
+		}
+		n := copy(c.buf[c.n:], p)
+		c.n += n
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+func (c *cmac) Sum(b []byte) []byte {
+	x := c.x
+	last := c.buf
+	if c.n == blockSize {
+		subtle.XORBytes(last[:], last[:], c.k1[:])
+	} else {
+		clear(last[c.n:])
+		last[c.n] = 0x80
+		subtle.XORBytes(last[:], last[:], c.k2[:])
+	}
+	subtle.XORBytes(x[:], x[:], last[:])
+	c.block.Encrypt(x[:], x[:])
+	return append(b, x[:]...)
+}
+
+// shiftLeft sets x to x << 1, and returns the most significant bit of x.
+func shiftLeft(x *[blockSize]byte) byte {
+	var msb byte
+	for i := len(x) - 1; i >= 0; i-- {
+		msb, x[i] = x[i]>>7, x[i]<<1|msb
+	}
+	return msb
+}
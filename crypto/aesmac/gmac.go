@@ -0,0 +1,122 @@
+package aesmac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// NonceSize is the size in bytes of GMAC nonces.
+const NonceSize = 12
+
+type gmac struct {
+	h [4]uint32
+	// tagMask is the encryption of the initial counter block, J0 in [2]
+	tagMask [blockSize]byte
+	y       [4]uint32
+	buf     [blockSize]byte
+	n       int
+	length  uint64
+}
+
+var _ hash.Hash = (*gmac)(nil)
+
+// NewGMAC returns a GMAC computed with the given block cipher and nonce. GMAC is GCM
+// authenticating the data as additional data, with an empty plaintext, so a tag is the tag of
+// cipher.NewGCM(block).Seal(nil, nonce, nil, data).
+//
+// Like with GCM, a nonce must never be reused with the same key.
+//
+// specified in 7 of [2].
+func NewGMAC(block cipher.Block, nonce []byte) (hash.Hash, error) {
+	if block.BlockSize() != blockSize {
+		return nil, errBlockSize
+	}
+	if len(nonce) != NonceSize {
+		return nil, errors.New("aesmac: bad nonce length")
+	}
+	g := &gmac{}
+
+	var key [blockSize]byte
+	block.Encrypt(key[:], key[:])
+	for i := range 4 {
+		g.h[3-i] = binary.BigEndian.Uint32(key[i*4 : (i*4)+4])
+	}
+
+	copy(g.tagMask[:], nonce)
+	g.tagMask[blockSize-1] = 1
+	block.Encrypt(g.tagMask[:], g.tagMask[:])
+
+	return g, nil
+}
+
+// NewAESGMAC returns an AES-GMAC using a 16, 24 or 32 bytes key.
+func NewAESGMAC(key, nonce []byte) (hash.Hash, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewGMAC(block, nonce)
+}
+
+func (g *gmac) Size() int {
+	return Size
+}
+
+func (g *gmac) BlockSize() int {
+	return blockSize
+}
+
+// Reset resets the GMAC to its initial state, keeping the same nonce.
+func (g *gmac) Reset() {
+	g.y = [4]uint32{}
+	g.n = 0
+	g.length = 0
+}
+
+func (g *gmac) Write(p []byte) (int, error) {
+	written := len(p)
+	g.length += uint64(len(p))
+
+	if g.n > 0 {
+		n := copy(g.buf[g.n:], p)
+		g.n += n
+		p = p[n:]
+		if g.n < blockSize {
+			return written, nil
+		}
+		ghashBlock(&g.y, &g.h, g.buf[:])
+		g.n = 0
+	}
+	for len(p) >= blockSize {
+		ghashBlock(&g.y, &g.h, p[:blockSize])
+		p = p[blockSize:]
+	}
+	g.n = copy(g.buf[:], p)
+
+	return written, nil
+}
+
+func (g *gmac) Sum(b []byte) []byte {
+	y := g.y
+	if g.n > 0 {
+		var last [blockSize]byte
+		copy(last[:], g.buf[:g.n])
+		ghashBlock(&y, &g.h, last[:])
+	}
+
+	// lengths block: the bit lengths of the additional data and of the (empty) ciphertext
+	var lengths [blockSize]byte
+	binary.BigEndian.PutUint64(lengths[:8], g.length*8)
+	ghashBlock(&y, &g.h, lengths[:])
+
+	var tag [blockSize]byte
+	for i := range 4 {
+		binary.BigEndian.PutUint32(tag[i*4:(i*4)+4], y[3-i])
+	}
+	subtle.XORBytes(tag[:], tag[:], g.tagMask[:])
+	return append(b, tag[:]...)
+}
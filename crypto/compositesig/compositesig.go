@@ -0,0 +1,280 @@
+// Package compositesig implements composite signatures which combine an ML-DSA parameter set with
+// Ed25519 or ECDSA P-256, following the composite ML-DSA of the IETF LAMPS working group [1]:
+// a signature is valid only if both component signatures are, so it stays secure as long as one
+// of the two algorithms is.
+//
+// Both components sign the same message representative
+//
+//	M' = Prefix || Label || len(ctx) || ctx || PH(M)
+//
+// where Prefix is the same for all the composite algorithms, Label identifies the composite
+// algorithm and PH is its pre-hash function. The ML-DSA component also uses Label as its context
+// string, so a component signature can't be stripped and passed off as a standalone one.
+//
+// Public keys and signatures are the concatenation of the ML-DSA one and the traditional one:
+// a raw Ed25519 public key or an uncompressed P-256 point, and an Ed25519 signature or a DER
+// ECDSA signature. Private keys are the ML-DSA seed followed by the Ed25519 seed or the P-256
+// scalar.
+//
+// [1] https://datatracker.ietf.org/doc/draft-ietf-lamps-pq-composite-sigs/
+package compositesig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+
+	"github.com/skerkour/go-benchmarks/crypto/mldsa"
+)
+
+const (
+	// MaxContextSize is the maximum size of a context string.
+	MaxContextSize = 255
+
+	// prefix is the prefix of the message representatives of all the composite algorithms.
+	prefix = "CompositeAlgorithmSignatures2025"
+
+	// p256PublicKeySize is the size of an uncompressed P-256 point.
+	p256PublicKeySize = 65
+	// p256MaxSignatureSize is the size of the longest DER ECDSA P-256 signature, of two 33-byte
+	// integers.
+	p256MaxSignatureSize = 72
+)
+
+// A Scheme is a composite signature algorithm.
+type Scheme struct {
+	name    string
+	label   string
+	mldsa   *mldsa.Parameters
+	p256    bool
+	prehash func() hash.Hash
+}
+
+var (
+	// MLDSA44Ed25519 is ML-DSA-44 with Ed25519 and SHA-512 as pre-hash.
+	MLDSA44Ed25519 = &Scheme{name: "MLDSA44-Ed25519-SHA512", label: "COMPSIG-MLDSA44-Ed25519-SHA512", mldsa: mldsa.MLDSA44, prehash: sha512.New}
+	// MLDSA44P256 is ML-DSA-44 with ECDSA P-256 and SHA-256 as pre-hash.
+	MLDSA44P256 = &Scheme{name: "MLDSA44-ECDSA-P256-SHA256", label: "COMPSIG-MLDSA44-ECDSA-P256-SHA256", mldsa: mldsa.MLDSA44, p256: true, prehash: sha256.New}
+	// MLDSA65Ed25519 is ML-DSA-65 with Ed25519 and SHA-512 as pre-hash.
+	MLDSA65Ed25519 = &Scheme{name: "MLDSA65-Ed25519-SHA512", label: "COMPSIG-MLDSA65-Ed25519-SHA512", mldsa: mldsa.MLDSA65, prehash: sha512.New}
+	// MLDSA65P256 is ML-DSA-65 with ECDSA P-256 and SHA-512 as pre-hash.
+	MLDSA65P256 = &Scheme{name: "MLDSA65-ECDSA-P256-SHA512", label: "COMPSIG-MLDSA65-ECDSA-P256-SHA512", mldsa: mldsa.MLDSA65, p256: true, prehash: sha512.New}
+)
+
+// String returns the name of the scheme, e.g. MLDSA44-Ed25519-SHA512.
+func (scheme *Scheme) String() string {
+	return scheme.name
+}
+
+// PublicKeySize returns the size of the encoded public keys.
+func (scheme *Scheme) PublicKeySize() int {
+	if scheme.p256 {
+		return scheme.mldsa.PublicKeySize() + p256PublicKeySize
+	}
+	return scheme.mldsa.PublicKeySize() + ed25519.PublicKeySize
+}
+
+// PrivateKeySize returns the size of the encoded private keys.
+func (scheme *Scheme) PrivateKeySize() int {
+	return mldsa.SeedSize + 32
+}
+
+// SignatureSize returns the size of the signatures. With ECDSA, it is the maximum size: DER
+// signatures are usually a byte or two shorter.
+func (scheme *Scheme) SignatureSize() int {
+	if scheme.p256 {
+		return scheme.mldsa.SignatureSize() + p256MaxSignatureSize
+	}
+	return scheme.mldsa.SignatureSize() + ed25519.SignatureSize
+}
+
+// messageRepresentative returns M' for message and context.
+func (scheme *Scheme) messageRepresentative(message, context []byte) ([]byte, error) {
+	if len(context) > MaxContextSize {
+		return nil, errors.New("compositesig: context too long")
+	}
+	h := scheme.prehash()
+	h.Write(message)
+	representative := make([]byte, 0, len(prefix)+len(scheme.label)+1+len(context)+h.Size())
+	representative = append(representative, prefix...)
+	representative = append(representative, scheme.label...)
+	representative = append(representative, byte(len(context)))
+	representative = append(representative, context...)
+	return h.Sum(representative), nil
+}
+
+// A PublicKey is a composite public key.
+type PublicKey struct {
+	scheme  *Scheme
+	mldsa   *mldsa.PublicKey
+	ed25519 ed25519.PublicKey
+	p256    *ecdsa.PublicKey
+}
+
+// A PrivateKey is a composite private key.
+type PrivateKey struct {
+	scheme  *Scheme
+	mldsa   *mldsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+	p256    *ecdsa.PrivateKey
+	pk      *PublicKey
+}
+
+// GenerateKey generates a new private key, drawing random bytes from crypto/rand.
+func (scheme *Scheme) GenerateKey() (*PrivateKey, error) {
+	sk := &PrivateKey{scheme: scheme}
+	var err error
+	if sk.mldsa, err = mldsa.GenerateKey(scheme.mldsa); err != nil {
+		return nil, err
+	}
+	if scheme.p256 {
+		sk.p256, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	} else {
+		_, sk.ed25519, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sk.setPublicKey()
+	return sk, nil
+}
+
+// NewPrivateKey decodes an encoded private key: the ML-DSA seed followed by the Ed25519 seed or
+// the P-256 scalar.
+func (scheme *Scheme) NewPrivateKey(privateKey []byte) (*PrivateKey, error) {
+	if len(privateKey) != scheme.PrivateKeySize() {
+		return nil, errors.New("compositesig: invalid private key length")
+	}
+	sk := &PrivateKey{scheme: scheme}
+	var err error
+	if sk.mldsa, err = mldsa.NewPrivateKey(scheme.mldsa, privateKey[:mldsa.SeedSize]); err != nil {
+		return nil, err
+	}
+	if scheme.p256 {
+		if sk.p256, err = ecdsa.ParseRawPrivateKey(elliptic.P256(), privateKey[mldsa.SeedSize:]); err != nil {
+			return nil, err
+		}
+	} else {
+		sk.ed25519 = ed25519.NewKeyFromSeed(privateKey[mldsa.SeedSize:])
+	}
+	sk.setPublicKey()
+	return sk, nil
+}
+
+func (sk *PrivateKey) setPublicKey() {
+	sk.pk = &PublicKey{scheme: sk.scheme, mldsa: sk.mldsa.PublicKey()}
+	if sk.scheme.p256 {
+		sk.pk.p256 = &sk.p256.PublicKey
+	} else {
+		sk.pk.ed25519 = sk.ed25519.Public().(ed25519.PublicKey)
+	}
+}
+
+// NewPublicKey decodes an encoded public key: the ML-DSA public key followed by the Ed25519
+// public key or the uncompressed P-256 point.
+func (scheme *Scheme) NewPublicKey(publicKey []byte) (*PublicKey, error) {
+	if len(publicKey) != scheme.PublicKeySize() {
+		return nil, errors.New("compositesig: invalid public key length")
+	}
+	pk := &PublicKey{scheme: scheme}
+	mldsaSize := scheme.mldsa.PublicKeySize()
+	var err error
+	if pk.mldsa, err = mldsa.NewPublicKey(scheme.mldsa, publicKey[:mldsaSize]); err != nil {
+		return nil, err
+	}
+	if scheme.p256 {
+		if pk.p256, err = ecdsa.ParseUncompressedPublicKey(elliptic.P256(), publicKey[mldsaSize:]); err != nil {
+			return nil, err
+		}
+	} else {
+		pk.ed25519 = ed25519.PublicKey(append([]byte{}, publicKey[mldsaSize:]...))
+	}
+	return pk, nil
+}
+
+// Bytes returns the encoded private key.
+func (sk *PrivateKey) Bytes() ([]byte, error) {
+	if sk.scheme.p256 {
+		scalar, err := sk.p256.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		return append(sk.mldsa.Bytes(), scalar...), nil
+	}
+	return append(sk.mldsa.Bytes(), sk.ed25519.Seed()...), nil
+}
+
+// PublicKey returns the public key of the private key.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return sk.pk
+}
+
+// Bytes returns the encoded public key.
+func (pk *PublicKey) Bytes() ([]byte, error) {
+	if pk.scheme.p256 {
+		point, err := pk.p256.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		return append(pk.mldsa.Bytes(), point...), nil
+	}
+	return append(pk.mldsa.Bytes(), pk.ed25519...), nil
+}
+
+// Scheme returns the scheme of the public key.
+func (pk *PublicKey) Scheme() *Scheme {
+	return pk.scheme
+}
+
+// Sign signs message with the context string context, which is at most MaxContextSize bytes and
+// must be the same to verify the signature. The ML-DSA signature is hedged and the ECDSA one
+// randomized.
+func (sk *PrivateKey) Sign(message, context []byte) ([]byte, error) {
+	representative, err := sk.scheme.messageRepresentative(message, context)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sk.mldsa.Sign(representative, []byte(sk.scheme.label))
+	if err != nil {
+		return nil, err
+	}
+	if sk.scheme.p256 {
+		digest := sha256.Sum256(representative)
+		ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, sk.p256, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		return append(signature, ecdsaSignature...), nil
+	}
+	return append(signature, ed25519.Sign(sk.ed25519, representative)...), nil
+}
+
+// Verify reports whether signature is a valid signature of message with the context string
+// context by pk, which requires both component signatures to be valid.
+func Verify(pk *PublicKey, message, signature, context []byte) bool {
+	scheme := pk.scheme
+	representative, err := scheme.messageRepresentative(message, context)
+	if err != nil {
+		return false
+	}
+	mldsaSize := scheme.mldsa.SignatureSize()
+	if len(signature) < mldsaSize {
+		return false
+	}
+	mldsaSignature, traditionalSignature := signature[:mldsaSize], signature[mldsaSize:]
+
+	mldsaValid := mldsa.Verify(pk.mldsa, representative, mldsaSignature, []byte(scheme.label))
+	var traditionalValid bool
+	if scheme.p256 {
+		digest := sha256.Sum256(representative)
+		traditionalValid = ecdsa.VerifyASN1(pk.p256, digest[:], traditionalSignature)
+	} else {
+		traditionalValid = ed25519.Verify(pk.ed25519, representative, traditionalSignature)
+	}
+	return mldsaValid && traditionalValid
+}
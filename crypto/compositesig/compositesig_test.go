@@ -0,0 +1,127 @@
+package compositesig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/mldsa"
+)
+
+var schemes = []*Scheme{MLDSA44Ed25519, MLDSA44P256, MLDSA65Ed25519, MLDSA65P256}
+
+func TestRoundTrip(t *testing.T) {
+	message := []byte("message")
+	context := []byte("context")
+	for _, scheme := range schemes {
+		t.Run(scheme.String(), func(t *testing.T) {
+			sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := sk.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(encoded) != scheme.PrivateKeySize() {
+				t.Errorf("expected a %d-byte private key, got %d bytes", scheme.PrivateKeySize(), len(encoded))
+			}
+			parsed, err := scheme.NewPrivateKey(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encodedPK, err := sk.PublicKey().Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsedPKBytes, err := parsed.PublicKey().Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encodedPK, parsedPKBytes) {
+				t.Error("the decoded private key has another public key")
+			}
+			pk, err := scheme.NewPublicKey(encodedPK)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signature, err := parsed.Sign(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(signature) > scheme.SignatureSize() {
+				t.Errorf("expected at most %d bytes, got a %d-byte signature", scheme.SignatureSize(), len(signature))
+			}
+			if !Verify(pk, message, signature, context) {
+				t.Error("valid signature rejected")
+			}
+			if Verify(pk, message, signature, nil) {
+				t.Error("signature accepted with another context")
+			}
+			if Verify(pk, []byte("other message"), signature, context) {
+				t.Error("signature of another message accepted")
+			}
+			if _, err := sk.Sign(message, make([]byte, MaxContextSize+1)); err == nil {
+				t.Error("context too long accepted")
+			}
+		})
+	}
+}
+
+// TestComponents checks that both component signatures are required, and that they aren't valid
+// standalone signatures of the message.
+func TestComponents(t *testing.T) {
+	message := []byte("message")
+	for _, scheme := range schemes {
+		t.Run(scheme.String(), func(t *testing.T) {
+			sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk := sk.PublicKey()
+			signature, err := sk.Sign(message, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := sk.Sign([]byte("other message"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mldsaSize := scheme.mldsa.SignatureSize()
+
+			mixed := append(bytes.Clone(signature[:mldsaSize]), other[mldsaSize:]...)
+			if Verify(pk, message, mixed, nil) {
+				t.Error("signature with the traditional component of another message accepted")
+			}
+			mixed = append(bytes.Clone(other[:mldsaSize]), signature[mldsaSize:]...)
+			if Verify(pk, message, mixed, nil) {
+				t.Error("signature with the ML-DSA component of another message accepted")
+			}
+			if Verify(pk, message, signature[:mldsaSize], nil) {
+				t.Error("signature without the traditional component accepted")
+			}
+
+			if mldsa.Verify(pk.mldsa, message, signature[:mldsaSize], nil) {
+				t.Error("ML-DSA component accepted as an ML-DSA signature")
+			}
+			if !scheme.p256 && ed25519.Verify(pk.ed25519, message, signature[mldsaSize:]) {
+				t.Error("Ed25519 component accepted as an Ed25519 signature")
+			}
+		})
+	}
+}
+
+func TestMessageRepresentative(t *testing.T) {
+	representative, err := MLDSA44Ed25519.messageRepresentative([]byte("message"), []byte("ctx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPrefix := "CompositeAlgorithmSignatures2025COMPSIG-MLDSA44-Ed25519-SHA512\x03ctx"
+	if string(representative[:len(expectedPrefix)]) != expectedPrefix {
+		t.Errorf("unexpected message representative %q", representative)
+	}
+	if len(representative) != len(expectedPrefix)+64 {
+		t.Errorf("expected a SHA-512 pre-hash, got %d bytes", len(representative)-len(expectedPrefix))
+	}
+}
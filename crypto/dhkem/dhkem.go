@@ -0,0 +1,279 @@
+// Package dhkem implements DHKEM, the Diffie-Hellman based KEM of HPKE [1], over the X25519,
+// P-256, P-384 and P-521 curves of crypto/ecdh.
+//
+// The ciphertext of DHKEM is an ephemeral public key and its shared secret is derived with the
+// HKDF of the scheme from the Diffie-Hellman output, the ephemeral public key and the public key of
+// the recipient. It also supports the authenticated mode of HPKE, in which the sender's static
+// key is mixed into the shared secret.
+//
+// [1] https://www.rfc-editor.org/rfc/rfc9180.html#section-4.1
+package dhkem
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/hpkelabel"
+)
+
+// A Scheme is a DHKEM instance, the combination of a curve and an HKDF hash function.
+type Scheme struct {
+	id    uint16
+	name  string
+	curve ecdh.Curve
+	hash  func() hash.Hash
+
+	// sharedSecretSize is Nsecret, privateKeySize Nsk and publicKeySize Npk in RFC 9180
+	sharedSecretSize int
+	privateKeySize   int
+	publicKeySize    int
+	// privateKeyMask is the bitmask of the first byte of the candidate private keys of
+	// DeriveKeyPair, 0 for X25519 which has no candidates
+	privateKeyMask byte
+}
+
+var (
+	// P256HKDFSHA256 is DHKEM(P-256, HKDF-SHA256).
+	P256HKDFSHA256 = &Scheme{0x0010, "DHKEM-P-256-HKDF-SHA256", ecdh.P256(), sha256.New, 32, 32, 65, 0xff}
+	// P384HKDFSHA384 is DHKEM(P-384, HKDF-SHA384).
+	P384HKDFSHA384 = &Scheme{0x0011, "DHKEM-P-384-HKDF-SHA384", ecdh.P384(), sha512.New384, 48, 48, 97, 0xff}
+	// P521HKDFSHA512 is DHKEM(P-521, HKDF-SHA512).
+	P521HKDFSHA512 = &Scheme{0x0012, "DHKEM-P-521-HKDF-SHA512", ecdh.P521(), sha512.New, 64, 66, 133, 0x01}
+	// X25519HKDFSHA256 is DHKEM(X25519, HKDF-SHA256).
+	X25519HKDFSHA256 = &Scheme{0x0020, "DHKEM-X25519-HKDF-SHA256", ecdh.X25519(), sha256.New, 32, 32, 32, 0}
+)
+
+// ID returns the KEM identifier of the scheme in the HPKE registry.
+func (scheme *Scheme) ID() uint16 {
+	return scheme.id
+}
+
+// String returns the name of the scheme, e.g. DHKEM-X25519-HKDF-SHA256.
+func (scheme *Scheme) String() string {
+	return scheme.name
+}
+
+// SharedSecretSize returns the size of the shared secrets.
+func (scheme *Scheme) SharedSecretSize() int {
+	return scheme.sharedSecretSize
+}
+
+// EncapsulationKeySize returns the size of the encapsulation keys, which is also the size of the
+// ciphertexts.
+func (scheme *Scheme) EncapsulationKeySize() int {
+	return scheme.publicKeySize
+}
+
+// DecapsulationKeySize returns the size of the encoded decapsulation keys.
+func (scheme *Scheme) DecapsulationKeySize() int {
+	return scheme.privateKeySize
+}
+
+// A DecapsulationKey is the private key of a recipient, or of a sender in the authenticated mode.
+type DecapsulationKey struct {
+	scheme *Scheme
+	key    *ecdh.PrivateKey
+	ek     *EncapsulationKey
+}
+
+// An EncapsulationKey is the public key of a recipient, or of a sender in the authenticated mode.
+type EncapsulationKey struct {
+	scheme *Scheme
+	key    *ecdh.PublicKey
+}
+
+// GenerateKey generates a new decapsulation key, drawing random bytes from crypto/rand.
+func (scheme *Scheme) GenerateKey() (*DecapsulationKey, error) {
+	key, err := scheme.curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.newDecapsulationKey(key), nil
+}
+
+// DeriveKeyPair deterministically derives a decapsulation key from the input keying material ikm,
+// which must have at least as much entropy as the private keys.
+func (scheme *Scheme) DeriveKeyPair(ikm []byte) (*DecapsulationKey, error) {
+	suiteID := scheme.suiteID()
+	prk := hpkelabel.Extract(scheme.hash, suiteID, nil, "dkp_prk", ikm)
+
+	if scheme.privateKeyMask == 0 {
+		sk, err := hpkelabel.Expand(scheme.hash, suiteID, prk, "sk", nil, scheme.privateKeySize)
+		if err != nil {
+			return nil, err
+		}
+		key, err := scheme.curve.NewPrivateKey(sk)
+		if err != nil {
+			return nil, err
+		}
+		return scheme.newDecapsulationKey(key), nil
+	}
+
+	for counter := range 256 {
+		candidate, err := hpkelabel.Expand(scheme.hash, suiteID, prk, "candidate", []byte{byte(counter)}, scheme.privateKeySize)
+		if err != nil {
+			return nil, err
+		}
+		candidate[0] &= scheme.privateKeyMask
+		// NewPrivateKey rejects zero and the scalars above the order of the curve
+		if key, err := scheme.curve.NewPrivateKey(candidate); err == nil {
+			return scheme.newDecapsulationKey(key), nil
+		}
+	}
+	return nil, errors.New("dhkem: key derivation failed")
+}
+
+// NewDecapsulationKey parses an encoded decapsulation key.
+func (scheme *Scheme) NewDecapsulationKey(decapsulationKey []byte) (*DecapsulationKey, error) {
+	key, err := scheme.curve.NewPrivateKey(decapsulationKey)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.newDecapsulationKey(key), nil
+}
+
+func (scheme *Scheme) newDecapsulationKey(key *ecdh.PrivateKey) *DecapsulationKey {
+	return &DecapsulationKey{
+		scheme: scheme,
+		key:    key,
+		ek:     &EncapsulationKey{scheme: scheme, key: key.PublicKey()},
+	}
+}
+
+// NewEncapsulationKey parses an encapsulation key. It returns an error if it is not a valid point.
+func (scheme *Scheme) NewEncapsulationKey(encapsulationKey []byte) (*EncapsulationKey, error) {
+	key, err := scheme.curve.NewPublicKey(encapsulationKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EncapsulationKey{scheme: scheme, key: key}, nil
+}
+
+// Bytes returns the encoded decapsulation key.
+func (dk *DecapsulationKey) Bytes() []byte {
+	return dk.key.Bytes()
+}
+
+// EncapsulationKey returns the encapsulation key of the decapsulation key.
+func (dk *DecapsulationKey) EncapsulationKey() *EncapsulationKey {
+	return dk.ek
+}
+
+// Bytes returns the encoded encapsulation key.
+func (ek *EncapsulationKey) Bytes() []byte {
+	return ek.key.Bytes()
+}
+
+// Encapsulate generates a shared secret and an associated ciphertext, drawing random bytes from
+// crypto/rand. It returns an error if the Diffie-Hellman output is all zeros, which happens with
+// X25519 public keys of small order.
+func (ek *EncapsulationKey) Encapsulate() (ciphertext, sharedSecret []byte, err error) {
+	return ek.AuthEncapsulate(nil)
+}
+
+// AuthEncapsulate is the authenticated version of Encapsulate: the shared secret can only be
+// decapsulated with the encapsulation key of sender, which proves that the ciphertext was
+// produced by the holder of sender. A nil sender is the unauthenticated mode.
+func (ek *EncapsulationKey) AuthEncapsulate(sender *DecapsulationKey) (ciphertext, sharedSecret []byte, err error) {
+	ephemeralKey, err := ek.scheme.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ek.encapsulate(ephemeralKey, sender)
+}
+
+// EncapsulateDerand is the derandomized version of Encapsulate, deriving the ephemeral key from
+// the input keying material ikmE with DeriveKeyPair. It must only be used for known-answer tests.
+func (ek *EncapsulationKey) EncapsulateDerand(ikmE []byte) (ciphertext, sharedSecret []byte, err error) {
+	return ek.AuthEncapsulateDerand(ikmE, nil)
+}
+
+// AuthEncapsulateDerand is the derandomized version of AuthEncapsulate. It must only be used for
+// known-answer tests.
+func (ek *EncapsulationKey) AuthEncapsulateDerand(ikmE []byte, sender *DecapsulationKey) (ciphertext, sharedSecret []byte, err error) {
+	ephemeralKey, err := ek.scheme.DeriveKeyPair(ikmE)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ek.encapsulate(ephemeralKey, sender)
+}
+
+func (ek *EncapsulationKey) encapsulate(ephemeralKey, sender *DecapsulationKey) (ciphertext, sharedSecret []byte, err error) {
+	if sender != nil && sender.scheme != ek.scheme {
+		return nil, nil, errors.New("dhkem: mismatched sender key")
+	}
+
+	dh, err := ephemeralKey.key.ECDH(ek.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext = ephemeralKey.ek.Bytes()
+	kemContext := append(bytes.Clone(ciphertext), ek.Bytes()...)
+
+	if sender != nil {
+		dhS, err := sender.key.ECDH(ek.key)
+		if err != nil {
+			return nil, nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, sender.ek.Bytes()...)
+	}
+
+	sharedSecret, err = ek.scheme.extractAndExpand(dh, kemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, sharedSecret, nil
+}
+
+// Decapsulate generates a shared secret from a ciphertext. It returns an error if the ciphertext
+// is not a valid point or if the Diffie-Hellman output is all zeros.
+func (dk *DecapsulationKey) Decapsulate(ciphertext []byte) (sharedSecret []byte, err error) {
+	return dk.AuthDecapsulate(ciphertext, nil)
+}
+
+// AuthDecapsulate is the authenticated version of Decapsulate, for ciphertexts produced by
+// AuthEncapsulate with the decapsulation key of sender. A nil sender is the unauthenticated mode.
+func (dk *DecapsulationKey) AuthDecapsulate(ciphertext []byte, sender *EncapsulationKey) (sharedSecret []byte, err error) {
+	if sender != nil && sender.scheme != dk.scheme {
+		return nil, errors.New("dhkem: mismatched sender key")
+	}
+
+	ephemeralKey, err := dk.scheme.curve.NewPublicKey(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dh, err := dk.key.ECDH(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(bytes.Clone(ciphertext), dk.ek.Bytes()...)
+
+	if sender != nil {
+		dhS, err := dk.key.ECDH(sender.key)
+		if err != nil {
+			return nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, sender.Bytes()...)
+	}
+
+	return dk.scheme.extractAndExpand(dh, kemContext)
+}
+
+// suiteID is "KEM" || I2OSP(kem_id, 2).
+func (scheme *Scheme) suiteID() []byte {
+	return binary.BigEndian.AppendUint16([]byte("KEM"), scheme.id)
+}
+
+func (scheme *Scheme) extractAndExpand(dh, kemContext []byte) ([]byte, error) {
+	suiteID := scheme.suiteID()
+	prk := hpkelabel.Extract(scheme.hash, suiteID, nil, "eae_prk", dh)
+	return hpkelabel.Expand(scheme.hash, suiteID, prk, "shared_secret", kemContext, scheme.sharedSecretSize)
+}
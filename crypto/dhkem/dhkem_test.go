@@ -0,0 +1,200 @@
+package dhkem
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+var schemes = []*Scheme{P256HKDFSHA256, P384HKDFSHA384, P521HKDFSHA512, X25519HKDFSHA256}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	*h = decoded
+	return err
+}
+
+// testdata/vectors.json holds the KEM fields of the base (mode 0) and auth (mode 2) vectors of
+// test-vectors.json of https://github.com/cfrg/draft-irtf-cfrg-hpke at commit 5f503c5, the
+// vectors of RFC 9180, for the HKDF-SHA256 and AES-128-GCM suites. The shared secret doesn't
+// depend on the KDF and the AEAD of the suite. There are no P-384 vectors.
+type vector struct {
+	Mode         int      `json:"mode"`
+	KEMID        uint16   `json:"kem_id"`
+	IKMR         hexBytes `json:"ikmR"`
+	IKME         hexBytes `json:"ikmE"`
+	IKMS         hexBytes `json:"ikmS"`
+	SKRm         hexBytes `json:"skRm"`
+	SKEm         hexBytes `json:"skEm"`
+	SKSm         hexBytes `json:"skSm"`
+	PKRm         hexBytes `json:"pkRm"`
+	PKEm         hexBytes `json:"pkEm"`
+	PKSm         hexBytes `json:"pkSm"`
+	Enc          hexBytes `json:"enc"`
+	SharedSecret hexBytes `json:"shared_secret"`
+}
+
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, v := range vectors {
+		var scheme *Scheme
+		for _, s := range schemes {
+			if s.ID() == v.KEMID {
+				scheme = s
+			}
+		}
+		if scheme == nil {
+			t.Fatalf("#%d: unknown KEM %#04x", i, v.KEMID)
+		}
+
+		recipient := deriveKeyPair(t, scheme, v.IKMR, v.SKRm, v.PKRm)
+		deriveKeyPair(t, scheme, v.IKME, v.SKEm, v.PKEm)
+		var sender *DecapsulationKey
+		if v.Mode == 2 {
+			sender = deriveKeyPair(t, scheme, v.IKMS, v.SKSm, v.PKSm)
+		}
+
+		ek, err := scheme.NewEncapsulationKey(v.PKRm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, sharedSecret, err := ek.AuthEncapsulateDerand(v.IKME, sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, v.Enc) {
+			t.Errorf("#%d %s: expected enc %x, got %x", i, scheme, v.Enc, ciphertext)
+		}
+		if !bytes.Equal(sharedSecret, v.SharedSecret) {
+			t.Errorf("#%d %s: expected shared secret %x, got %x", i, scheme, v.SharedSecret, sharedSecret)
+		}
+
+		var senderKey *EncapsulationKey
+		if sender != nil {
+			senderKey = sender.EncapsulationKey()
+		}
+		decapsulated, err := recipient.AuthDecapsulate(v.Enc, senderKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decapsulated, v.SharedSecret) {
+			t.Errorf("#%d %s: decapsulated %x, expected %x", i, scheme, decapsulated, v.SharedSecret)
+		}
+	}
+}
+
+func deriveKeyPair(t *testing.T, scheme *Scheme, ikm, sk, pk []byte) *DecapsulationKey {
+	t.Helper()
+	dk, err := scheme.DeriveKeyPair(ikm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dk.Bytes(), sk) {
+		t.Errorf("%s: expected private key %x, got %x", scheme, sk, dk.Bytes())
+	}
+	if !bytes.Equal(dk.EncapsulationKey().Bytes(), pk) {
+		t.Errorf("%s: expected public key %x, got %x", scheme, pk, dk.EncapsulationKey().Bytes())
+	}
+	return dk
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, scheme := range schemes {
+		t.Run(scheme.String(), func(t *testing.T) {
+			recipient, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sender, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, sharedSecret, err := recipient.EncapsulationKey().Encapsulate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ciphertext) != scheme.EncapsulationKeySize() {
+				t.Errorf("expected a ciphertext of %d bytes, got %d", scheme.EncapsulationKeySize(), len(ciphertext))
+			}
+			if len(sharedSecret) != scheme.SharedSecretSize() {
+				t.Errorf("expected a shared secret of %d bytes, got %d", scheme.SharedSecretSize(), len(sharedSecret))
+			}
+			decapsulated, err := recipient.Decapsulate(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decapsulated, sharedSecret) {
+				t.Error("decapsulated a different shared secret")
+			}
+
+			ciphertext, sharedSecret, err = recipient.EncapsulationKey().AuthEncapsulate(sender)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decapsulated, err = recipient.AuthDecapsulate(ciphertext, sender.EncapsulationKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decapsulated, sharedSecret) {
+				t.Error("decapsulated a different authenticated shared secret")
+			}
+			// decapsulating with the wrong sender, or without, gives another shared secret
+			decapsulated, err = recipient.AuthDecapsulate(ciphertext, recipient.EncapsulationKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(decapsulated, sharedSecret) {
+				t.Error("decapsulated the authenticated shared secret with the wrong sender")
+			}
+			decapsulated, err = recipient.Decapsulate(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(decapsulated, sharedSecret) {
+				t.Error("decapsulated the authenticated shared secret without a sender")
+			}
+
+			parsed, err := scheme.NewDecapsulationKey(recipient.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recipient.Bytes()) != scheme.DecapsulationKeySize() {
+				t.Errorf("expected a decapsulation key of %d bytes, got %d", scheme.DecapsulationKeySize(), len(recipient.Bytes()))
+			}
+			if !bytes.Equal(parsed.EncapsulationKey().Bytes(), recipient.EncapsulationKey().Bytes()) {
+				t.Error("the parsed decapsulation key has a different encapsulation key")
+			}
+
+			if _, err := recipient.Decapsulate(ciphertext[1:]); err == nil {
+				t.Error("expected an error for a short ciphertext")
+			}
+		})
+	}
+}
+
+func TestMismatchedSender(t *testing.T) {
+	recipient, err := X25519HKDFSHA256.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := P256HKDFSHA256.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := recipient.EncapsulationKey().AuthEncapsulate(sender); err == nil {
+		t.Error("expected an error for a sender key of another scheme")
+	}
+}
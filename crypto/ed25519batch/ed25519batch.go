@@ -0,0 +1,106 @@
+// Package ed25519batch implements the batch verification of Ed25519 signatures [1].
+//
+// Verifying a batch of n signatures with a random linear combination of the n verification
+// equations replaces the n double scalar multiplications of individual verification by a
+// single multi-scalar multiplication of size 2n+1, which is about twice as fast for large
+// batches. A batch is only known to be valid or not: when it is invalid, the signatures must be
+// verified individually to find the invalid ones.
+//
+// Batch verification uses the cofactored verification equation, [8][S]B = [8]R + [8][k]A, while
+// crypto/ed25519 uses the cofactorless one, [S]B = R + [k]A. Both accept all the signatures
+// produced by honest signers, but they disagree on some maliciously crafted signatures with
+// small-order components, see [2]. Verify is thus not a drop-in replacement for ed25519.Verify
+// when signers are adversarial and nodes must reach consensus on validity.
+//
+// [1] https://ed25519.cr.yp.to/ed25519-20110926.pdf
+// [2] https://hdevalence.ca/blog/2020-10-04-its-25519am
+package ed25519batch
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// Verify reports whether all the signatures[i] are valid signatures of messages[i] by
+// publicKeys[i]. It returns false if the slices don't have the same length or if any public key
+// or signature is malformed.
+func Verify(publicKeys []ed25519.PublicKey, messages, signatures [][]byte) bool {
+	n := len(publicKeys)
+	if len(messages) != n || len(signatures) != n {
+		return false
+	}
+	if n == 0 {
+		return true
+	}
+
+	// points and scalars of the equation
+	// [-sum(z_i * S_i)]B + sum([z_i]R_i) + sum([z_i * k_i]A_i) == 0
+	points := make([]*edwards25519.Point, 1+2*n)
+	scalars := make([]*edwards25519.Scalar, 1+2*n)
+	points[0] = edwards25519.NewGeneratorPoint()
+	bScalar := edwards25519.NewScalar()
+	scalars[0] = bScalar
+
+	// allocate the points and scalars of the batch at once
+	pointsBuffer := make([]edwards25519.Point, 2*n)
+	scalarsBuffer := make([]edwards25519.Scalar, 2*n)
+	var s, k edwards25519.Scalar
+	var digest [sha512.Size]byte
+	var zBytes [32]byte
+
+	for i := range n {
+		publicKey, signature := publicKeys[i], signatures[i]
+		if len(publicKey) != ed25519.PublicKeySize || len(signature) != ed25519.SignatureSize {
+			return false
+		}
+
+		R, A := &pointsBuffer[2*i], &pointsBuffer[2*i+1]
+		if _, err := R.SetBytes(signature[:32]); err != nil {
+			return false
+		}
+		if _, err := A.SetBytes(publicKey); err != nil {
+			return false
+		}
+		if _, err := s.SetCanonicalBytes(signature[32:]); err != nil {
+			return false
+		}
+
+		h := sha512.New()
+		h.Write(signature[:32])
+		h.Write(publicKey)
+		h.Write(messages[i])
+		if _, err := k.SetUniformBytes(h.Sum(digest[:0])); err != nil {
+			return false
+		}
+
+		z := &scalarsBuffer[2*i]
+		if err := randomScalar(z, zBytes[:]); err != nil {
+			return false
+		}
+		// bScalar -= z * S
+		bScalar.Subtract(bScalar, s.Multiply(z, &s))
+		points[1+2*i], scalars[1+2*i] = R, z
+		points[2+2*i], scalars[2+2*i] = A, scalarsBuffer[2*i+1].Multiply(z, &k)
+	}
+
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// randomScalar sets z to a random 128-bit scalar, which is enough for a forged signature to
+// pass the batch with probability 2^-128. buf is a 32-byte scratch buffer.
+func randomScalar(z *edwards25519.Scalar, buf []byte) error {
+	clear(buf)
+	if _, err := rand.Read(buf[:16]); err != nil {
+		return err
+	}
+	if _, err := z.SetCanonicalBytes(buf); err != nil {
+		return errors.New("ed25519batch: invalid random scalar")
+	}
+	return nil
+}
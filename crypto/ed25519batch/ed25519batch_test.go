@@ -0,0 +1,110 @@
+package ed25519batch
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+)
+
+func newBatch(t *testing.T, n int) (publicKeys []ed25519.PublicKey, messages, signatures [][]byte) {
+	t.Helper()
+	for i := range n {
+		publicKey, privateKey, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := fmt.Appendf(nil, "message %d", i)
+		publicKeys = append(publicKeys, publicKey)
+		messages = append(messages, message)
+		signatures = append(signatures, ed25519.Sign(privateKey, message))
+	}
+	return
+}
+
+func TestVerify(t *testing.T) {
+	if !Verify(nil, nil, nil) {
+		t.Error("empty batch rejected")
+	}
+
+	for _, n := range []int{1, 2, 3, 16, 65} {
+		publicKeys, messages, signatures := newBatch(t, n)
+		if !Verify(publicKeys, messages, signatures) {
+			t.Errorf("n=%d: valid batch rejected", n)
+		}
+
+		for _, i := range []int{0, n / 2, n - 1} {
+			messages[i][0] ^= 1
+			if Verify(publicKeys, messages, signatures) {
+				t.Errorf("n=%d: batch with the wrong message %d accepted", n, i)
+			}
+			messages[i][0] ^= 1
+
+			signatures[i][0] ^= 1
+			if Verify(publicKeys, messages, signatures) {
+				t.Errorf("n=%d: batch with the corrupted R of signature %d accepted", n, i)
+			}
+			signatures[i][0] ^= 1
+
+			signatures[i][40] ^= 1
+			if Verify(publicKeys, messages, signatures) {
+				t.Errorf("n=%d: batch with the corrupted S of signature %d accepted", n, i)
+			}
+			signatures[i][40] ^= 1
+
+			publicKeys[i], publicKeys[(i+1)%n] = publicKeys[(i+1)%n], publicKeys[i]
+			if n > 1 && Verify(publicKeys, messages, signatures) {
+				t.Errorf("n=%d: batch with the swapped public keys %d accepted", n, i)
+			}
+			publicKeys[i], publicKeys[(i+1)%n] = publicKeys[(i+1)%n], publicKeys[i]
+		}
+
+		if !Verify(publicKeys, messages, signatures) {
+			t.Errorf("n=%d: batch modified by the test", n)
+		}
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	publicKeys, messages, signatures := newBatch(t, 4)
+
+	if Verify(publicKeys[:3], messages, signatures) {
+		t.Error("batch with fewer public keys accepted")
+	}
+	if Verify(publicKeys, messages, signatures[:3]) {
+		t.Error("batch with fewer signatures accepted")
+	}
+
+	truncated := signatures[1]
+	signatures[1] = truncated[:ed25519.SignatureSize-1]
+	if Verify(publicKeys, messages, signatures) {
+		t.Error("batch with a truncated signature accepted")
+	}
+	signatures[1] = truncated
+
+	// S + L, where L is the order of the group, is valid in the equation but not canonical
+	nonCanonical := append([]byte(nil), signatures[2]...)
+	l := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	carry := 0
+	for i := range l {
+		sum := int(nonCanonical[32+i]) + int(l[i]) + carry
+		nonCanonical[32+i] = byte(sum)
+		carry = sum >> 8
+	}
+	if carry == 0 && nonCanonical[63]&0xe0 == 0 {
+		original := signatures[2]
+		signatures[2] = nonCanonical
+		if Verify(publicKeys, messages, signatures) {
+			t.Error("batch with a non-canonical S accepted")
+		}
+		signatures[2] = original
+	}
+
+	// y = 2 is not the y coordinate of a point of the curve
+	invalidKey := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	invalidKey[0] = 2
+	publicKeys[3] = invalidKey
+	if Verify(publicKeys, messages, signatures) {
+		t.Error("batch with an invalid public key accepted")
+	}
+}
@@ -0,0 +1,220 @@
+package ascon
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"strconv"
+
+	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/subtle"
+)
+
+// BlockSizeAEAD128 is the size in bytes of an Ascon-AEAD128
+// block.
+const BlockSizeAEAD128 = 16
+
+const ivAEAD128 uint64 = 0x00001000808c0001 // Ascon-AEAD128
+
+// dsep is the domain separation bit between the associated
+// data and the plaintext.
+const dsep uint64 = 0x8000000000000000
+
+type aead128 struct {
+	k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*aead128)(nil)
+
+// NewAEAD128 creates a 128-bit Ascon-AEAD128 AEAD as specified
+// in NIST SP 800-232.
+//
+// Ascon-AEAD128 is derived from ASCON-128a but is not
+// compatible with it: keys, nonces and data are loaded in
+// little-endian order and the domain separation constant
+// changed.
+//
+// Each unique key can encrypt a maximum 2^54 bytes. Nonces must
+// never be reused with the same key. Violating either of these
+// constraints compromises the security of the algorithm.
+//
+// There are no other constraints on the composition of the
+// nonce. For example, the nonce can be a counter.
+func NewAEAD128(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("ascon: bad key length")
+	}
+	return &aead128{
+		k0: binary.LittleEndian.Uint64(key[0:8]),
+		k1: binary.LittleEndian.Uint64(key[8:16]),
+	}, nil
+}
+
+func (a *aead128) NonceSize() int {
+	return NonceSize
+}
+
+func (a *aead128) Overhead() int {
+	return TagSize
+}
+
+func (a *aead128) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != NonceSize {
+		panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+	}
+
+	var s state
+	s.initAEAD128(a.k0, a.k1, nonce)
+	s.additionalDataAEAD128(additionalData)
+
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+	s.encryptAEAD128(out[:len(plaintext)], plaintext)
+	s.finalizeAEAD128(a.k0, a.k1)
+	s.tagAEAD128(out[len(out)-TagSize:])
+
+	return ret
+}
+
+func (a *aead128) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+	}
+	if len(ciphertext) < TagSize {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-TagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+	var s state
+	s.initAEAD128(a.k0, a.k1, nonce)
+	s.additionalDataAEAD128(additionalData)
+
+	ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+	if subtle.InexactOverlap(out, ciphertext) {
+		panic("ascon: invalid buffer overlap")
+	}
+	s.decryptAEAD128(out, ciphertext)
+	s.finalizeAEAD128(a.k0, a.k1)
+
+	expectedTag := make([]byte, TagSize)
+	s.tagAEAD128(expectedTag)
+
+	if subtle.ConstantTimeCompare(expectedTag, tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		runtime.KeepAlive(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+func (s *state) initAEAD128(k0, k1 uint64, nonce []byte) {
+	s.init(ivAEAD128, k0, k1,
+		binary.LittleEndian.Uint64(nonce[0:8]),
+		binary.LittleEndian.Uint64(nonce[8:16]))
+}
+
+func (s *state) additionalDataAEAD128(ad []byte) {
+	if len(ad) > 0 {
+		for len(ad) >= BlockSizeAEAD128 {
+			s.x0 ^= binary.LittleEndian.Uint64(ad[0:8])
+			s.x1 ^= binary.LittleEndian.Uint64(ad[8:16])
+			p8(s)
+			ad = ad[BlockSizeAEAD128:]
+		}
+		if len(ad) >= 8 {
+			s.x0 ^= binary.LittleEndian.Uint64(ad[0:8])
+			s.x1 ^= le64n(ad[8:])
+			s.x1 ^= padLE(len(ad) - 8)
+		} else {
+			s.x0 ^= le64n(ad)
+			s.x0 ^= padLE(len(ad))
+		}
+		p8(s)
+	}
+	s.x4 ^= dsep
+}
+
+func (s *state) encryptAEAD128(dst, src []byte) {
+	for len(src) >= BlockSizeAEAD128 && len(dst) >= BlockSizeAEAD128 {
+		s.x0 ^= binary.LittleEndian.Uint64(src[0:8])
+		s.x1 ^= binary.LittleEndian.Uint64(src[8:16])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0)
+		binary.LittleEndian.PutUint64(dst[8:16], s.x1)
+		p8(s)
+		src = src[BlockSizeAEAD128:]
+		dst = dst[BlockSizeAEAD128:]
+	}
+	if len(src) >= 8 {
+		s.x0 ^= binary.LittleEndian.Uint64(src[0:8])
+		s.x1 ^= le64n(src[8:])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0)
+		putLE64n(dst[8:], s.x1)
+		s.x1 ^= padLE(len(src) - 8)
+	} else {
+		s.x0 ^= le64n(src)
+		putLE64n(dst, s.x0)
+		s.x0 ^= padLE(len(src))
+	}
+}
+
+func (s *state) decryptAEAD128(dst, src []byte) {
+	for len(src) >= BlockSizeAEAD128 && len(dst) >= BlockSizeAEAD128 {
+		c0 := binary.LittleEndian.Uint64(src[0:8])
+		c1 := binary.LittleEndian.Uint64(src[8:16])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0^c0)
+		binary.LittleEndian.PutUint64(dst[8:16], s.x1^c1)
+		s.x0 = c0
+		s.x1 = c1
+		p8(s)
+		src = src[BlockSizeAEAD128:]
+		dst = dst[BlockSizeAEAD128:]
+	}
+	if len(src) >= 8 {
+		c0 := binary.LittleEndian.Uint64(src[0:8])
+		c1 := le64n(src[8:])
+		binary.LittleEndian.PutUint64(dst[0:8], s.x0^c0)
+		putLE64n(dst[8:], s.x1^c1)
+		s.x0 = c0
+		s.x1 = maskLE(s.x1, len(src)-8)
+		s.x1 |= c1
+		s.x1 ^= padLE(len(src) - 8)
+	} else {
+		c0 := le64n(src)
+		putLE64n(dst, s.x0^c0)
+		s.x0 = maskLE(s.x0, len(src))
+		s.x0 |= c0
+		s.x0 ^= padLE(len(src))
+	}
+}
+
+func (s *state) finalizeAEAD128(k0, k1 uint64) {
+	s.x2 ^= k0
+	s.x3 ^= k1
+	p12(s)
+	s.x3 ^= k0
+	s.x4 ^= k1
+}
+
+func (s *state) tagAEAD128(dst []byte) {
+	binary.LittleEndian.PutUint64(dst[0:8], s.x3)
+	binary.LittleEndian.PutUint64(dst[8:16], s.x4)
+}
+
+func putLE64n(b []byte, x uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(x >> (8 * i))
+	}
+}
+
+func maskLE(x uint64, n int) uint64 {
+	for i := 0; i < n; i++ {
+		x &^= 255 << (8 * i)
+	}
+	return x
+}
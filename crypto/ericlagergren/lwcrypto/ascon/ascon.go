@@ -1,8 +1,12 @@
-// Package ascon implements the ASCON AEAD cipher.
+// Package ascon implements the ASCON AEAD cipher, both the
+// pre-standard ASCON-128 and ASCON-128a and the NIST SP 800-232
+// Ascon-AEAD128, and the Ascon-Hash256, Ascon-XOF128 and
+// Ascon-CXOF128 hash functions of NIST SP 800-232.
 //
 // References:
 //
 //	[ascon]: https://ascon.iaik.tugraz.at
+//	[SP 800-232]: https://csrc.nist.gov/pubs/sp/800/232/final
 package ascon
 
 import (
@@ -79,6 +79,104 @@ func TestVectors128a(t *testing.T) {
 	testVectors(t, New128a, filepath.Join("testdata", "vectors_128a.txt"))
 }
 
+// TestVectorsAEAD128 uses entries of the NIST SP 800-232 KAT file
+// (LWC_AEAD_KAT_128_128.txt), whose plaintexts and associated data
+// count up from 0x20 and 0x30 respectively.
+func TestVectorsAEAD128(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	nonce, _ := hex.DecodeString("101112131415161718191A1B1C1D1E1F")
+	for i, v := range []struct {
+		pt, ad, ct string
+	}{
+		{
+			pt: "",
+			ad: "",
+			ct: "4F9C278211BEC9316BF68F46EE8B2EC6",
+		},
+		{
+			pt: "",
+			ad: "30",
+			ct: "CCCB674FE18A09A285D6AB11B35675C0",
+		},
+		{
+			pt: "20",
+			ad: "30",
+			ct: "962B8016836C75A7D86866588CA245D886",
+		},
+		{
+			pt: "202122232425262728292A2B2C2D2E",
+			ad: "303132333435363738393A3B3C3D3E3F",
+			ct: "6373EBB28BE97C9BAC090CF399C13E646BE0C80B0404770341EB48D6178948",
+		},
+		{
+			pt: "202122232425262728292A2B2C2D2E2F",
+			ad: "303132333435363738393A3B3C3D3E",
+			ct: "20FD19DABC1A5CC449A621D34DAC601372248BB7D46CC4A7F5F9C1AB782255B6",
+		},
+		{
+			pt: "202122232425262728292A2B2C2D2E2F30",
+			ad: "303132333435363738393A3B3C3D3E3F40",
+			ct: "BF77C71B3DE9F1C5B372EF273A08E89BE9D507D7B3C2AEE97911E791F7970D6635",
+		},
+		{
+			pt: "202122232425262728292A2B2C2D2E2F303132333435363738393A3B3C3D3E3F",
+			ad: "303132333435363738393A3B3C3D3E3F404142434445464748494A4B4C4D4E4F",
+			ct: "CB34D04660A66DBFBE9C856601F5B8AA51A499B55AC8F7FBEFBC331A613EE9CDFD191750A47F211C0A15ED28173D7CAA",
+		},
+	} {
+		pt, _ := hex.DecodeString(v.pt)
+		ad, _ := hex.DecodeString(v.ad)
+		ct, _ := hex.DecodeString(v.ct)
+		c, err := NewAEAD128(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := c.Seal(nil, nonce, pt, ad)
+		if !bytes.Equal(got, ct) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, ct, got)
+		}
+		got, err = c.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("#%d: %v", i+1, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Fatalf("#%d: expected %#x, got %#x", i+1, pt, got)
+		}
+	}
+}
+
+// TestRoundTripAEAD128 checks every combination of partial
+// blocks and that tampering is detected.
+func TestRoundTripAEAD128(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	c, err := NewAEAD128(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 3*BlockSizeAEAD128)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	for n := range buf {
+		for m := range buf {
+			pt, ad := buf[:n], buf[:m]
+			ct := c.Seal(nil, nonce, pt, ad)
+			got, err := c.Open(nil, nonce, ct, ad)
+			if err != nil {
+				t.Fatalf("(%d, %d): %v", n, m, err)
+			}
+			if !bytes.Equal(got, pt) {
+				t.Fatalf("(%d, %d): expected %#x, got %#x", n, m, pt, got)
+			}
+			ct[0] ^= 1
+			if _, err := c.Open(nil, nonce, ct, ad); err == nil {
+				t.Fatalf("(%d, %d): tampered ciphertext was accepted", n, m)
+			}
+		}
+	}
+}
+
 func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string) {
 	vecs, err := readVecs(path)
 	if err != nil {
@@ -119,6 +217,14 @@ func BenchmarkOpen8K_128a(b *testing.B) {
 	benchmarkOpen(b, New128a, make([]byte, 8*1024))
 }
 
+func BenchmarkSeal1K_AEAD128(b *testing.B) {
+	benchmarkSeal(b, NewAEAD128, make([]byte, 1024))
+}
+
+func BenchmarkOpen1K_AEAD128(b *testing.B) {
+	benchmarkOpen(b, NewAEAD128, make([]byte, 1024))
+}
+
 func BenchmarkSeal1K_128(b *testing.B) {
 	benchmarkSeal(b, New128, make([]byte, 1024))
 }
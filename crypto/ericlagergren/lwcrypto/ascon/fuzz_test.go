@@ -6,11 +6,13 @@ import (
 	"bytes"
 	"crypto/cipher"
 	"crypto/rand"
+	mathrand "math/rand"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon"
+	refaead128 "github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon/internal/asconc/aead128"
 	ref "github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon/internal/asconc/ref"
 	refa "github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon/internal/asconc/refa"
 )
@@ -26,6 +28,11 @@ func TestFuzz(t *testing.T) {
 
 		testFuzz(t, refa.New, ascon.New128a)
 	})
+	t.Run("AEAD128", func(t *testing.T) {
+		t.Parallel()
+
+		testFuzz(t, refaead128.New, ascon.NewAEAD128)
+	})
 }
 
 func testFuzz(t *testing.T, ref, test func([]byte) (cipher.AEAD, error)) {
@@ -59,7 +66,7 @@ func testFuzz(t *testing.T, ref, test func([]byte) (cipher.AEAD, error)) {
 		if _, err := rand.Read(nonce); err != nil {
 			t.Fatal(err)
 		}
-		n := rand.Intn(len(plaintext))
+		n := mathrand.Intn(len(plaintext))
 		if _, err := rand.Read(plaintext[:n]); err != nil {
 			t.Fatal(err)
 		}
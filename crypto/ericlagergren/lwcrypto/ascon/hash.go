@@ -0,0 +1,241 @@
+package ascon
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/subtle"
+)
+
+const (
+	// HashSize is the size in bytes of an Ascon-Hash256 digest.
+	HashSize = 32
+	// HashBlockSize is the rate in bytes of Ascon-Hash256,
+	// Ascon-XOF128 and Ascon-CXOF128.
+	HashBlockSize = 8
+	// MaxCustomizationSize is the maximum size in bytes of an
+	// Ascon-CXOF128 customization string.
+	MaxCustomizationSize = 256
+)
+
+const (
+	ivHash256 uint64 = 0x0000080100cc0002 // Ascon-Hash256
+	ivXOF128  uint64 = 0x0000080000cc0003 // Ascon-XOF128
+	ivCXOF128 uint64 = 0x0000080000cc0004 // Ascon-CXOF128
+)
+
+// The initial states only depend on the IV, so they are
+// computed once.
+var (
+	initHash256 = initHash(ivHash256)
+	initXOF128  = initHash(ivXOF128)
+	initCXOF128 = initHash(ivCXOF128)
+)
+
+func initHash(iv uint64) state {
+	s := state{x0: iv}
+	p12(&s)
+	return s
+}
+
+// digest is the sponge shared by Ascon-Hash256, Ascon-XOF128
+// and Ascon-CXOF128 as specified in NIST SP 800-232.
+//
+// Unlike the pre-standard ASCON-128 and ASCON-128a, words are
+// loaded and stored in little-endian order.
+type digest struct {
+	// init is the state after the IV and, for Ascon-CXOF128,
+	// the customization string have been absorbed.
+	init state
+	s    state
+	buf  [HashBlockSize]byte
+	// n is the number of buffered bytes while absorbing and
+	// the number of bytes of buf already read while squeezing.
+	n         int
+	squeezing bool
+}
+
+func (d *digest) Reset() {
+	d.s = d.init
+	d.buf = [HashBlockSize]byte{}
+	d.n = 0
+	d.squeezing = false
+}
+
+func (d *digest) BlockSize() int {
+	return HashBlockSize
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	if d.squeezing {
+		panic("ascon: write after read")
+	}
+	n := len(p)
+	if d.n > 0 {
+		k := copy(d.buf[d.n:], p)
+		d.n += k
+		p = p[k:]
+		if d.n < HashBlockSize {
+			return n, nil
+		}
+		d.s.x0 ^= binary.LittleEndian.Uint64(d.buf[:])
+		p12(&d.s)
+		d.n = 0
+	}
+	d.s.absorbBlocks(p)
+	p = p[len(p)&^(HashBlockSize-1):]
+	d.n = copy(d.buf[:], p)
+	return n, nil
+}
+
+func (d *digest) Read(p []byte) (int, error) {
+	if !d.squeezing {
+		d.s.x0 ^= le64n(d.buf[:d.n])
+		d.s.x0 ^= padLE(d.n)
+		p12(&d.s)
+		binary.LittleEndian.PutUint64(d.buf[:], d.s.x0)
+		d.n = 0
+		d.squeezing = true
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if d.n == HashBlockSize {
+			p12(&d.s)
+			binary.LittleEndian.PutUint64(d.buf[:], d.s.x0)
+			d.n = 0
+		}
+		k := copy(p, d.buf[d.n:])
+		d.n += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// absorbBlocks absorbs every full block of p, ignoring the
+// trailing partial block.
+func (s *state) absorbBlocks(p []byte) {
+	for len(p) >= HashBlockSize {
+		s.x0 ^= binary.LittleEndian.Uint64(p[0:8])
+		p12(s)
+		p = p[HashBlockSize:]
+	}
+}
+
+// absorbPadded absorbs p followed by its padding.
+func (s *state) absorbPadded(p []byte) {
+	s.absorbBlocks(p)
+	p = p[len(p)&^(HashBlockSize-1):]
+	s.x0 ^= le64n(p)
+	s.x0 ^= padLE(len(p))
+	p12(s)
+}
+
+type hash256 struct {
+	digest
+}
+
+var _ hash.Hash = (*hash256)(nil)
+
+// NewHash256 creates an Ascon-Hash256 hash.
+//
+// Ascon-Hash256 provides 128 bits of security against
+// collision, preimage and second preimage attacks.
+func NewHash256() hash.Hash {
+	h := &hash256{digest{init: initHash256}}
+	h.Reset()
+	return h
+}
+
+func (h *hash256) Size() int {
+	return HashSize
+}
+
+func (h *hash256) Sum(b []byte) []byte {
+	d := h.digest
+	ret, out := subtle.SliceForAppend(b, HashSize)
+	d.Read(out)
+	return ret
+}
+
+// SumHash256 returns the Ascon-Hash256 digest of data.
+func SumHash256(data []byte) [HashSize]byte {
+	s := initHash256
+	s.absorbPadded(data)
+	var out [HashSize]byte
+	for i := 0; i < HashSize; i += 8 {
+		if i > 0 {
+			p12(&s)
+		}
+		binary.LittleEndian.PutUint64(out[i:], s.x0)
+	}
+	return out
+}
+
+// XOF is an instance of the Ascon-XOF128 or Ascon-CXOF128
+// extendable output functions.
+//
+// Write absorbs more data. Read squeezes an arbitrary amount of
+// output. Writing after reading panics.
+type XOF struct {
+	digest
+}
+
+// NewXOF128 creates an Ascon-XOF128 extendable output function.
+//
+// Ascon-XOF128 provides min(128, L/2) bits of collision
+// resistance and min(128, L) bits of preimage resistance for an
+// L-bit output.
+func NewXOF128() *XOF {
+	x := &XOF{digest{init: initXOF128}}
+	x.Reset()
+	return x
+}
+
+// NewCXOF128 creates an Ascon-CXOF128 extendable output
+// function, which is Ascon-XOF128 with a customization string
+// for domain separation.
+//
+// The customization string must not be longer than
+// MaxCustomizationSize bytes.
+func NewCXOF128(customization []byte) (*XOF, error) {
+	if len(customization) > MaxCustomizationSize {
+		return nil, errors.New("ascon: customization string too long")
+	}
+	s := initCXOF128
+	s.x0 ^= uint64(len(customization)) * 8
+	p12(&s)
+	s.absorbPadded(customization)
+
+	x := &XOF{digest{init: s}}
+	x.Reset()
+	return x, nil
+}
+
+// Clone returns a copy of the XOF in its current state.
+func (x *XOF) Clone() *XOF {
+	clone := *x
+	return &clone
+}
+
+// SumXOF128 returns the first length bytes of the Ascon-XOF128
+// output of data.
+func SumXOF128(data []byte, length int) []byte {
+	x := NewXOF128()
+	x.Write(data)
+	out := make([]byte, length)
+	x.Read(out)
+	return out
+}
+
+func padLE(n int) uint64 {
+	return 0x01 << (8 * n)
+}
+
+func le64n(b []byte) uint64 {
+	var x uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		x |= uint64(b[i]) << (8 * i)
+	}
+	return x
+}
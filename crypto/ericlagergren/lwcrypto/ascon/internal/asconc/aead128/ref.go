@@ -0,0 +1,109 @@
+// Package aead128 implements a wrapper around the reference
+// implementation of the NIST SP 800-232 Ascon-AEAD128.
+//
+// Adapted from the ASCON-128a reference implementation in
+// ../refa the same way upstream did for
+// https://github.com/ascon/ascon-c/tree/main/crypto_aead/asconaead128/ref:
+// words are little-endian, and the IV and the domain separation
+// constant changed.
+package aead128
+
+/*
+#include "ascon.h"
+#include "api.h"
+#include "crypto_aead.h"
+*/
+import "C"
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/subtle"
+)
+
+type aead struct {
+	key []byte
+}
+
+func New(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case C.CRYPTO_KEYBYTES:
+		return &aead{key: key}, nil
+	default:
+		return nil, fmt.Errorf("invalid key size: %d", len(key))
+	}
+}
+
+func (a *aead) NonceSize() int {
+	return C.CRYPTO_NPUBBYTES
+}
+
+func (a *aead) Overhead() int {
+	return C.CRYPTO_ABYTES
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, len(plaintext)+C.CRYPTO_ABYTES)
+	if subtle.InexactOverlap(out, plaintext) {
+		panic("ascon: invalid buffer overlap")
+	}
+	var m *C.uchar
+	if len(plaintext) > 0 {
+		m = (*C.uchar)(&plaintext[0])
+	}
+	var ad *C.uchar
+	if len(additionalData) > 0 {
+		ad = (*C.uchar)(&additionalData[0])
+	}
+	clen := C.ulonglong(len(out))
+	r := C.crypto_aead_encrypt_aead128(
+		(*C.uchar)(&out[0]),
+		&clen,
+		m,
+		C.ulonglong(len(plaintext)),
+		ad,
+		C.ulonglong(len(additionalData)),
+		nil,
+		(*C.uchar)(&nonce[0]),
+		(*C.uchar)(&a.key[0]),
+	)
+	if r != 0 {
+		panic("crypto_aead_encrypt")
+	}
+	return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, len(ciphertext)-C.CRYPTO_ABYTES)
+	if subtle.InexactOverlap(out, ciphertext) {
+		panic("ascon: invalid buffer overlap")
+	}
+	if len(ciphertext) < C.CRYPTO_ABYTES {
+		return nil, errors.New("ciphertext too short")
+	}
+	var ad *C.uchar
+	if len(additionalData) > 0 {
+		ad = (*C.uchar)(&additionalData[0])
+	}
+	mlen := C.ulonglong(len(out))
+	r := C.crypto_aead_decrypt_aead128(
+		(*C.uchar)(&out[0]),
+		&mlen,
+		nil,
+		(*C.uchar)(&ciphertext[0]),
+		C.ulonglong(len(ciphertext)),
+		ad,
+		C.ulonglong(len(additionalData)),
+		(*C.uchar)(&nonce[0]),
+		(*C.uchar)(&a.key[0]),
+	)
+	if r != 0 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errors.New("auth failed")
+	}
+	return ret, nil
+}
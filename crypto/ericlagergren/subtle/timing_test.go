@@ -0,0 +1,59 @@
+package subtle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/utils"
+)
+
+var timingSink int
+
+func TestConstantTimeCompareTiming(t *testing.T) {
+	utils.SkipTimingTest(t)
+
+	for _, size := range []int{16, 32, 64, 4096} {
+		x := make([]byte, size)
+		for i := range x {
+			x[i] = byte(i)
+		}
+		equal := bytes.Clone(x)
+		differentFirst := bytes.Clone(x)
+		differentFirst[0] ^= 1
+
+		leak := utils.TimingLeak(20_000, 16,
+			func() { timingSink += ConstantTimeCompare(x, equal) },
+			func() { timingSink += ConstantTimeCompare(x, differentFirst) },
+		)
+		if leak > utils.TimingLeakThreshold {
+			t.Errorf("ConstantTimeCompare(%d bytes): timing depends on the input (t = %.1f)", size, leak)
+		}
+	}
+}
+
+func TestConstantTimeBigEndianTiming(t *testing.T) {
+	utils.SkipTimingTest(t)
+
+	x := make([]byte, 64)
+	zero := make([]byte, 64)
+	greater := bytes.Clone(x)
+	greater[0] = 0xff
+
+	leak := utils.TimingLeak(20_000, 16,
+		func() { timingSink += ConstantTimeBigEndianLessOrEq(x, zero) },
+		func() { timingSink += ConstantTimeBigEndianLessOrEq(x, greater) },
+	)
+	if leak > utils.TimingLeakThreshold {
+		t.Errorf("ConstantTimeBigEndianLessOrEq: timing depends on the input (t = %.1f)", leak)
+	}
+
+	nonZero := bytes.Clone(x)
+	nonZero[0] = 1
+	leak = utils.TimingLeak(20_000, 16,
+		func() { timingSink += ConstantTimeBigEndianZero(x) },
+		func() { timingSink += ConstantTimeBigEndianZero(nonZero) },
+	)
+	if leak > utils.TimingLeakThreshold {
+		t.Errorf("ConstantTimeBigEndianZero: timing depends on the input (t = %.1f)", leak)
+	}
+}
@@ -0,0 +1,87 @@
+package hpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/hpkelabel"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// A KDF is the key derivation function of a suite.
+type KDF struct {
+	id   uint16
+	name string
+	hash func() hash.Hash
+	// size is Nh in RFC 9180
+	size int
+}
+
+var (
+	// HKDFSHA256 is HKDF-SHA256.
+	HKDFSHA256 = &KDF{0x0001, "HKDF-SHA256", sha256.New, sha256.Size}
+	// HKDFSHA512 is HKDF-SHA512.
+	HKDFSHA512 = &KDF{0x0003, "HKDF-SHA512", sha512.New, sha512.Size}
+)
+
+// ID returns the KDF identifier in the HPKE registry.
+func (kdf *KDF) ID() uint16 {
+	return kdf.id
+}
+
+func (kdf *KDF) String() string {
+	return kdf.name
+}
+
+// labeledExtract is LabeledExtract with the hash of the KDF.
+func (kdf *KDF) labeledExtract(suiteID, salt []byte, label string, ikm []byte) []byte {
+	return hpkelabel.Extract(kdf.hash, suiteID, salt, label, ikm)
+}
+
+// labeledExpand is LabeledExpand with the hash of the KDF.
+func (kdf *KDF) labeledExpand(suiteID, prk []byte, label string, info []byte, length int) ([]byte, error) {
+	return hpkelabel.Expand(kdf.hash, suiteID, prk, label, info, length)
+}
+
+// An AEAD is the authenticated encryption algorithm of a suite. The other AEADs of the
+// encryption_aead benchmarks have no HPKE identifier.
+type AEAD struct {
+	id   uint16
+	name string
+	// keySize is Nk and nonceSize Nn in RFC 9180
+	keySize   int
+	nonceSize int
+	// new is nil for the export-only AEAD
+	new func(key []byte) (cipher.AEAD, error)
+}
+
+var (
+	// AES128GCM is AES-128-GCM.
+	AES128GCM = &AEAD{0x0001, "AES-128-GCM", 16, 12, newAESGCM}
+	// AES256GCM is AES-256-GCM.
+	AES256GCM = &AEAD{0x0002, "AES-256-GCM", 32, 12, newAESGCM}
+	// ChaCha20Poly1305 is ChaCha20-Poly1305.
+	ChaCha20Poly1305 = &AEAD{0x0003, "ChaCha20-Poly1305", chacha20poly1305.KeySize, chacha20poly1305.NonceSize, chacha20poly1305.New}
+	// ExportOnly is the export-only AEAD, for the contexts which are only used to export secrets.
+	ExportOnly = &AEAD{0xffff, "Export-only", 0, 0, nil}
+)
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ID returns the AEAD identifier in the HPKE registry.
+func (aead *AEAD) ID() uint16 {
+	return aead.id
+}
+
+func (aead *AEAD) String() string {
+	return aead.name
+}
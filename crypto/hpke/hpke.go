@@ -0,0 +1,276 @@
+// Package hpke implements the base and auth modes of Hybrid Public Key Encryption [1] with the
+// DHKEM(X25519), ML-KEM-768 and X-Wing KEMs [2], the HKDF-SHA256 and HKDF-SHA512 KDFs and the
+// AES-GCM and ChaCha20-Poly1305 AEADs.
+//
+// A Suite encrypts to a public key either with single-shot Seal and Open, or by setting up a
+// Sender and a Recipient context which encrypt a sequence of messages, in order, with the same
+// encapsulated key. The auth mode additionally authenticates the sender with its own private
+// key, and is only supported by DHKEM.
+//
+// The standard library has crypto/hpke since Go 1.26, without the auth mode; the tests check the
+// interoperability of both.
+//
+// [1] https://www.rfc-editor.org/rfc/rfc9180.html
+//
+// [2] https://datatracker.ietf.org/doc/draft-ietf-hpke-pq/
+package hpke
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	modeBase byte = 0x00
+	modeAuth byte = 0x02
+)
+
+// A Suite is an HPKE ciphersuite: the combination of a KEM, a KDF and an AEAD.
+type Suite struct {
+	kem  KEM
+	kdf  *KDF
+	aead *AEAD
+	// id is suite_id in RFC 9180
+	id []byte
+}
+
+// NewSuite returns the suite of kem, kdf and aead.
+func NewSuite(kem KEM, kdf *KDF, aead *AEAD) *Suite {
+	id := []byte("HPKE")
+	id = binary.BigEndian.AppendUint16(id, kem.ID())
+	id = binary.BigEndian.AppendUint16(id, kdf.ID())
+	id = binary.BigEndian.AppendUint16(id, aead.ID())
+	return &Suite{kem: kem, kdf: kdf, aead: aead, id: id}
+}
+
+// KEM returns the KEM of the suite.
+func (suite *Suite) KEM() KEM {
+	return suite.kem
+}
+
+// String returns the name of the suite, e.g. X-Wing-HKDF-SHA256-AES-128-GCM.
+func (suite *Suite) String() string {
+	return fmt.Sprintf("%s-%s-%s", suite.kem, suite.kdf, suite.aead)
+}
+
+type context struct {
+	suite          *Suite
+	aead           cipher.AEAD
+	baseNonce      []byte
+	exporterSecret []byte
+	// seq is the sequence number of the next message
+	seq uint64
+}
+
+// A Sender is the context of the sender, which encrypts messages to the recipient. It is not safe
+// for concurrent use.
+type Sender struct {
+	context
+}
+
+// A Recipient is the context of the recipient, which decrypts the messages of the sender in the
+// order in which they were encrypted. It is not safe for concurrent use.
+type Recipient struct {
+	context
+}
+
+// NewSender sets up the context of a sender in the base mode, encrypting to the public key
+// recipient. The encapsulated key enc must be sent to the recipient.
+func (suite *Suite) NewSender(recipient PublicKey, info []byte) (enc []byte, sender *Sender, err error) {
+	return suite.newSender(recipient, nil, info, nil)
+}
+
+// NewAuthSender sets up the context of a sender in the auth mode, which authenticates the sender as
+// the holder of the private key sender.
+func (suite *Suite) NewAuthSender(recipient PublicKey, sender PrivateKey, info []byte) (enc []byte, s *Sender, err error) {
+	if sender == nil {
+		return nil, nil, errors.New("hpke: missing sender key")
+	}
+	return suite.newSender(recipient, sender, info, nil)
+}
+
+// newSender sets up the context of a sender, in the auth mode if sender is not nil. random is the
+// randomness of the encapsulation, nil except in the known-answer tests.
+func (suite *Suite) newSender(recipient PublicKey, sender PrivateKey, info, random []byte) ([]byte, *Sender, error) {
+	if recipient.KEM() != suite.kem || (sender != nil && sender.KEM() != suite.kem) {
+		return nil, nil, errMismatchedKEM
+	}
+	enc, sharedSecret, err := recipient.encapsulate(random, sender)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err := suite.keySchedule(sender != nil, sharedSecret, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, &Sender{ctx}, nil
+}
+
+// NewRecipient sets up the context of a recipient in the base mode, decrypting with the private
+// key recipient the messages of the sender of the encapsulated key enc.
+func (suite *Suite) NewRecipient(recipient PrivateKey, enc, info []byte) (*Recipient, error) {
+	return suite.newRecipient(recipient, nil, enc, info)
+}
+
+// NewAuthRecipient sets up the context of a recipient in the auth mode, which only succeeds to
+// decrypt the messages of the holder of the private key of sender.
+func (suite *Suite) NewAuthRecipient(recipient PrivateKey, sender PublicKey, enc, info []byte) (*Recipient, error) {
+	if sender == nil {
+		return nil, errors.New("hpke: missing sender key")
+	}
+	return suite.newRecipient(recipient, sender, enc, info)
+}
+
+func (suite *Suite) newRecipient(recipient PrivateKey, sender PublicKey, enc, info []byte) (*Recipient, error) {
+	if recipient.KEM() != suite.kem || (sender != nil && sender.KEM() != suite.kem) {
+		return nil, errMismatchedKEM
+	}
+	sharedSecret, err := recipient.decapsulate(enc, sender)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := suite.keySchedule(sender != nil, sharedSecret, info)
+	if err != nil {
+		return nil, err
+	}
+	return &Recipient{ctx}, nil
+}
+
+// keySchedule is KeySchedule of section 5.1 of RFC 9180, without the PSK modes.
+func (suite *Suite) keySchedule(auth bool, sharedSecret, info []byte) (context, error) {
+	mode := modeBase
+	if auth {
+		mode = modeAuth
+	}
+
+	pskIDHash := suite.kdf.labeledExtract(suite.id, nil, "psk_id_hash", nil)
+	infoHash := suite.kdf.labeledExtract(suite.id, nil, "info_hash", info)
+	keyScheduleContext := make([]byte, 0, 1+len(pskIDHash)+len(infoHash))
+	keyScheduleContext = append(keyScheduleContext, mode)
+	keyScheduleContext = append(keyScheduleContext, pskIDHash...)
+	keyScheduleContext = append(keyScheduleContext, infoHash...)
+
+	secret := suite.kdf.labeledExtract(suite.id, sharedSecret, "secret", nil)
+
+	ctx := context{suite: suite}
+	var err error
+	ctx.exporterSecret, err = suite.kdf.labeledExpand(suite.id, secret, "exp", keyScheduleContext, suite.kdf.size)
+	if err != nil {
+		return context{}, err
+	}
+	if suite.aead.new == nil {
+		return ctx, nil
+	}
+
+	key, err := suite.kdf.labeledExpand(suite.id, secret, "key", keyScheduleContext, suite.aead.keySize)
+	if err != nil {
+		return context{}, err
+	}
+	ctx.baseNonce, err = suite.kdf.labeledExpand(suite.id, secret, "base_nonce", keyScheduleContext, suite.aead.nonceSize)
+	if err != nil {
+		return context{}, err
+	}
+	ctx.aead, err = suite.aead.new(key)
+	if err != nil {
+		return context{}, err
+	}
+	return ctx, nil
+}
+
+// nextNonce returns the nonce of the next message, the base nonce XORed with the sequence number,
+// and increments the sequence number.
+func (ctx *context) nextNonce() ([]byte, error) {
+	if ctx.aead == nil {
+		return nil, errors.New("hpke: the export-only AEAD can't encrypt")
+	}
+	// the nonces are 12 bytes long, so the sequence number would only overflow after 2^96 - 1
+	// messages, but it is a uint64
+	if ctx.seq == math.MaxUint64 {
+		return nil, errors.New("hpke: message limit reached")
+	}
+
+	nonce := make([]byte, len(ctx.baseNonce))
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], ctx.seq)
+	for i := range nonce {
+		nonce[i] ^= ctx.baseNonce[i]
+	}
+	ctx.seq++
+	return nonce, nil
+}
+
+// Seal encrypts and authenticates plaintext and authenticates aad.
+func (sender *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	nonce, err := sender.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return sender.aead.Seal(nil, nonce, plaintext, aad), nil
+}
+
+// Open authenticates and decrypts ciphertext and authenticates aad. A failed decryption doesn't
+// consume a sequence number, so the next message can still be decrypted.
+func (recipient *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
+	nonce, err := recipient.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := recipient.aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		recipient.seq--
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// Export derives a secret of length bytes bound to exporterContext, which is the same for the
+// sender and the recipient. length must be at most 255 times the output size of the KDF.
+func (ctx *context) Export(exporterContext []byte, length int) ([]byte, error) {
+	return ctx.suite.kdf.labeledExpand(ctx.suite.id, ctx.exporterSecret, "sec", exporterContext, length)
+}
+
+// Seal encrypts plaintext to recipient in the base mode with a single-use context.
+func (suite *Suite) Seal(recipient PublicKey, info, aad, plaintext []byte) (enc, ciphertext []byte, err error) {
+	enc, sender, err := suite.NewSender(recipient, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err = sender.Seal(aad, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, ciphertext, nil
+}
+
+// Open decrypts a ciphertext produced by Seal.
+func (suite *Suite) Open(recipient PrivateKey, enc, info, aad, ciphertext []byte) ([]byte, error) {
+	ctx, err := suite.NewRecipient(recipient, enc, info)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Open(aad, ciphertext)
+}
+
+// AuthSeal encrypts plaintext to recipient in the auth mode with a single-use context.
+func (suite *Suite) AuthSeal(recipient PublicKey, sender PrivateKey, info, aad, plaintext []byte) (enc, ciphertext []byte, err error) {
+	enc, ctx, err := suite.NewAuthSender(recipient, sender, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err = ctx.Seal(aad, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, ciphertext, nil
+}
+
+// AuthOpen decrypts a ciphertext produced by AuthSeal.
+func (suite *Suite) AuthOpen(recipient PrivateKey, sender PublicKey, enc, info, aad, ciphertext []byte) ([]byte, error) {
+	ctx, err := suite.NewAuthRecipient(recipient, sender, enc, info)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Open(aad, ciphertext)
+}
@@ -0,0 +1,403 @@
+package hpke
+
+import (
+	"bytes"
+	stdhpke "crypto/hpke"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+var (
+	kems  = []KEM{DHKEMX25519, MLKEM768, XWing}
+	kdfs  = []*KDF{HKDFSHA256, HKDFSHA512}
+	aeads = []*AEAD{AES128GCM, AES256GCM, ChaCha20Poly1305}
+)
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	*h = decoded
+	return err
+}
+
+type vector struct {
+	Mode           byte     `json:"mode"`
+	KEMID          uint16   `json:"kem_id"`
+	KDFID          uint16   `json:"kdf_id"`
+	AEADID         uint16   `json:"aead_id"`
+	Info           hexBytes `json:"info"`
+	IKMR           hexBytes `json:"ikmR"`
+	IKME           hexBytes `json:"ikmE"`
+	IKMS           hexBytes `json:"ikmS"`
+	SKRm           hexBytes `json:"skRm"`
+	PKRm           hexBytes `json:"pkRm"`
+	Enc            hexBytes `json:"enc"`
+	SharedSecret   hexBytes `json:"shared_secret"`
+	Key            hexBytes `json:"key"`
+	BaseNonce      hexBytes `json:"base_nonce"`
+	ExporterSecret hexBytes `json:"exporter_secret"`
+	Encryptions    []struct {
+		AAD        hexBytes `json:"aad"`
+		Ciphertext hexBytes `json:"ct"`
+		Nonce      hexBytes `json:"nonce"`
+		Plaintext  hexBytes `json:"pt"`
+	} `json:"encryptions"`
+	Exports []struct {
+		Context hexBytes `json:"exporter_context"`
+		Length  int      `json:"L"`
+		Value   hexBytes `json:"exported_value"`
+	} `json:"exports"`
+}
+
+// TestVectors checks the test vectors of RFC 9180 and draft-ietf-hpke-pq of the suites this
+// package supports.
+//
+// testdata/rfc9180.json holds the DHKEM(X25519) vectors of the base and auth modes of
+// test-vectors.json of https://github.com/cfrg/draft-irtf-cfrg-hpke at commit 5f503c5, the
+// vectors of RFC 9180, with the first 10 encryptions of each vector out of 257.
+//
+// testdata/hpke-pq.json holds the ML-KEM-768 and X-Wing vectors with HKDF-SHA256 of
+// src/crypto/hpke/testdata/hpke-pq.json of Go 1.26.
+func TestVectors(t *testing.T) {
+	for _, file := range []string{"testdata/rfc9180.json", "testdata/hpke-pq.json"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vectors []vector
+		if err := json.Unmarshal(data, &vectors); err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range vectors {
+			name := fmt.Sprintf("%s/mode-%d-kem-%04x-kdf-%04x-aead-%04x", file, v.Mode, v.KEMID, v.KDFID, v.AEADID)
+			t.Run(name, func(t *testing.T) {
+				testVector(t, v)
+			})
+		}
+	}
+}
+
+func testVector(t *testing.T, v vector) {
+	suite := NewSuite(findKEM(t, v.KEMID), findKDF(t, v.KDFID), findAEAD(t, v.AEADID))
+
+	recipient, err := suite.kem.DeriveKeyPair(v.IKMR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recipient.Bytes(), v.SKRm) {
+		t.Errorf("expected private key %x, got %x", v.SKRm, recipient.Bytes())
+	}
+	if !bytes.Equal(recipient.PublicKey().Bytes(), v.PKRm) {
+		t.Errorf("expected public key %x, got %x", v.PKRm, recipient.PublicKey().Bytes())
+	}
+
+	var sender PrivateKey
+	var senderPublicKey PublicKey
+	if v.Mode == modeAuth {
+		sender, err = suite.kem.DeriveKeyPair(v.IKMS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		senderPublicKey = sender.PublicKey()
+	}
+
+	enc, senderCtx, err := suite.newSender(recipient.PublicKey(), sender, v.Info, v.IKME)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, v.Enc) {
+		t.Fatalf("expected enc %x, got %x", v.Enc, enc)
+	}
+	if !bytes.Equal(senderCtx.exporterSecret, v.ExporterSecret) {
+		t.Errorf("expected exporter secret %x, got %x", v.ExporterSecret, senderCtx.exporterSecret)
+	}
+	if senderCtx.aead != nil && !bytes.Equal(senderCtx.baseNonce, v.BaseNonce) {
+		t.Errorf("expected base nonce %x, got %x", v.BaseNonce, senderCtx.baseNonce)
+	}
+
+	recipientCtx, err := suite.newRecipient(recipient, senderPublicKey, enc, v.Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, encryption := range v.Encryptions {
+		ciphertext, err := senderCtx.Seal(encryption.AAD, encryption.Plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, encryption.Ciphertext) {
+			t.Fatalf("encryption #%d: expected %x, got %x", i, encryption.Ciphertext, ciphertext)
+		}
+		plaintext, err := recipientCtx.Open(encryption.AAD, encryption.Ciphertext)
+		if err != nil {
+			t.Fatalf("encryption #%d: %v", i, err)
+		}
+		if !bytes.Equal(plaintext, encryption.Plaintext) {
+			t.Fatalf("encryption #%d: decrypted %x, expected %x", i, plaintext, encryption.Plaintext)
+		}
+	}
+
+	for i, export := range v.Exports {
+		for _, ctx := range []*context{&senderCtx.context, &recipientCtx.context} {
+			value, err := ctx.Export(export.Context, export.Length)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(value, export.Value) {
+				t.Errorf("export #%d: expected %x, got %x", i, export.Value, value)
+			}
+		}
+	}
+}
+
+func findKEM(t *testing.T, id uint16) KEM {
+	for _, kem := range kems {
+		if kem.ID() == id {
+			return kem
+		}
+	}
+	t.Fatalf("unknown KEM %#04x", id)
+	return nil
+}
+
+func findKDF(t *testing.T, id uint16) *KDF {
+	for _, kdf := range kdfs {
+		if kdf.ID() == id {
+			return kdf
+		}
+	}
+	t.Fatalf("unknown KDF %#04x", id)
+	return nil
+}
+
+func findAEAD(t *testing.T, id uint16) *AEAD {
+	for _, aead := range append(aeads, ExportOnly) {
+		if aead.ID() == id {
+			return aead
+		}
+	}
+	t.Fatalf("unknown AEAD %#04x", id)
+	return nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, kem := range kems {
+		for _, kdf := range kdfs {
+			for _, aead := range aeads {
+				suite := NewSuite(kem, kdf, aead)
+				t.Run(suite.String(), func(t *testing.T) {
+					recipient, err := kem.GenerateKey()
+					if err != nil {
+						t.Fatal(err)
+					}
+					info := []byte("info")
+
+					enc, ciphertext, err := suite.Seal(recipient.PublicKey(), info, []byte("aad"), []byte("plaintext"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					plaintext, err := suite.Open(recipient, enc, info, []byte("aad"), ciphertext)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(plaintext) != "plaintext" {
+						t.Errorf("decrypted %q", plaintext)
+					}
+					if _, err := suite.Open(recipient, enc, []byte("other info"), []byte("aad"), ciphertext); err == nil {
+						t.Error("expected an error for a different info")
+					}
+					if _, err := suite.Open(recipient, enc, info, []byte("other aad"), ciphertext); err == nil {
+						t.Error("expected an error for a different aad")
+					}
+
+					enc, sender, err := suite.NewSender(recipient.PublicKey(), info)
+					if err != nil {
+						t.Fatal(err)
+					}
+					recipientCtx, err := suite.NewRecipient(recipient, enc, info)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for i := range 5 {
+						message := []byte(fmt.Sprintf("message %d", i))
+						ciphertext, err := sender.Seal(nil, message)
+						if err != nil {
+							t.Fatal(err)
+						}
+						// a failed decryption doesn't desynchronize the contexts
+						if _, err := recipientCtx.Open(nil, ciphertext[1:]); err == nil {
+							t.Fatal("expected an error for a truncated ciphertext")
+						}
+						plaintext, err := recipientCtx.Open(nil, ciphertext)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if !bytes.Equal(plaintext, message) {
+							t.Fatalf("decrypted %q, expected %q", plaintext, message)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	suite := NewSuite(DHKEMX25519, HKDFSHA256, ChaCha20Poly1305)
+	recipient, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, ciphertext, err := suite.AuthSeal(recipient.PublicKey(), sender, nil, nil, []byte("plaintext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := suite.AuthOpen(recipient, sender.PublicKey(), enc, nil, nil, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "plaintext" {
+		t.Errorf("decrypted %q", plaintext)
+	}
+	if _, err := suite.AuthOpen(recipient, other.PublicKey(), enc, nil, nil, ciphertext); err == nil {
+		t.Error("expected an error for the wrong sender")
+	}
+	if _, err := suite.Open(recipient, enc, nil, nil, ciphertext); err == nil {
+		t.Error("expected an error for the base mode")
+	}
+
+	for _, kem := range []KEM{MLKEM768, XWing} {
+		recipient, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := NewSuite(kem, HKDFSHA256, AES128GCM).AuthSeal(recipient.PublicKey(), sender, nil, nil, nil); err == nil {
+			t.Errorf("%s: expected an error for the auth mode", kem)
+		}
+	}
+}
+
+func TestExportOnly(t *testing.T) {
+	suite := NewSuite(XWing, HKDFSHA256, ExportOnly)
+	recipient, err := XWing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, sender, err := suite.NewSender(recipient.PublicKey(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sender.Seal(nil, nil); err == nil {
+		t.Error("expected an error when encrypting with the export-only AEAD")
+	}
+	recipientCtx, err := suite.NewRecipient(recipient, enc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderSecret, err := sender.Export([]byte("context"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientSecret, err := recipientCtx.Export([]byte("context"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(senderSecret, recipientSecret) {
+		t.Error("the sender and the recipient exported different secrets")
+	}
+	if _, err := sender.Export(nil, 255*32+1); err == nil {
+		t.Error("expected an error for an export longer than 255 hashes")
+	}
+}
+
+func TestMismatchedKEM(t *testing.T) {
+	recipient, err := MLKEM768.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSuite(XWing, HKDFSHA256, AES128GCM).Seal(recipient.PublicKey(), nil, nil, nil); err == nil {
+		t.Error("expected an error for a key of another KEM")
+	}
+}
+
+// TestStandardLibrary checks that the base mode interoperates with crypto/hpke.
+func TestStandardLibrary(t *testing.T) {
+	stdKEMs := map[KEM]stdhpke.KEM{
+		DHKEMX25519: mustStd(stdhpke.NewKEM(DHKEMX25519.ID())),
+		MLKEM768:    stdhpke.MLKEM768(),
+		XWing:       stdhpke.MLKEM768X25519(),
+	}
+	for _, kem := range kems {
+		for _, kdf := range kdfs {
+			for _, aead := range aeads {
+				suite := NewSuite(kem, kdf, aead)
+				t.Run(suite.String(), func(t *testing.T) {
+					stdKEM := stdKEMs[kem]
+					stdKDF := mustStd(stdhpke.NewKDF(kdf.ID()))
+					stdAEAD := mustStd(stdhpke.NewAEAD(aead.ID()))
+
+					recipient, err := kem.GenerateKey()
+					if err != nil {
+						t.Fatal(err)
+					}
+					stdRecipient, err := stdKEM.NewPrivateKey(recipient.Bytes())
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(stdRecipient.PublicKey().Bytes(), recipient.PublicKey().Bytes()) {
+						t.Fatal("crypto/hpke derived a different public key")
+					}
+
+					enc, ciphertext, err := suite.Seal(recipient.PublicKey(), []byte("info"), nil, []byte("to the standard library"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					plaintext, err := stdhpke.Open(stdRecipient, stdKDF, stdAEAD, []byte("info"), append(enc, ciphertext...))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(plaintext) != "to the standard library" {
+						t.Errorf("crypto/hpke decrypted %q", plaintext)
+					}
+
+					message, err := stdhpke.Seal(stdRecipient.PublicKey(), stdKDF, stdAEAD, []byte("info"), []byte("from the standard library"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					encSize := len(enc)
+					plaintext, err = suite.Open(recipient, message[:encSize], []byte("info"), nil, message[encSize:])
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(plaintext) != "from the standard library" {
+						t.Errorf("decrypted %q", plaintext)
+					}
+				})
+			}
+		}
+	}
+}
+
+func mustStd[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -0,0 +1,304 @@
+package hpke
+
+import (
+	"crypto/mlkem"
+	"crypto/mlkem/mlkemtest"
+	"crypto/sha3"
+	"encoding/binary"
+	"errors"
+
+	"github.com/skerkour/go-benchmarks/crypto/dhkem"
+	"github.com/skerkour/go-benchmarks/crypto/internal/hpkelabel"
+	"github.com/skerkour/go-benchmarks/crypto/xwing"
+)
+
+// A KEM is the key encapsulation mechanism of a suite.
+type KEM interface {
+	// ID returns the KEM identifier in the HPKE registry.
+	ID() uint16
+	String() string
+	// GenerateKey generates a new private key, drawing random bytes from crypto/rand.
+	GenerateKey() (PrivateKey, error)
+	// DeriveKeyPair deterministically derives a private key from the input keying material ikm.
+	DeriveKeyPair(ikm []byte) (PrivateKey, error)
+	// NewPrivateKey parses an encoded private key.
+	NewPrivateKey(privateKey []byte) (PrivateKey, error)
+	// NewPublicKey parses an encoded public key.
+	NewPublicKey(publicKey []byte) (PublicKey, error)
+}
+
+// A PublicKey is the public key of a recipient, or of a sender in the auth mode.
+type PublicKey interface {
+	KEM() KEM
+	Bytes() []byte
+
+	// encapsulate returns the encapsulated key and the shared secret. random is nil, or the
+	// randomness of the KEM for known-answer tests: the ikmE of DHKEM, the randomness of
+	// ML-KEM-768 or the eseed of X-Wing. sender is nil in the base mode.
+	encapsulate(random []byte, sender PrivateKey) (enc, sharedSecret []byte, err error)
+}
+
+// A PrivateKey is the private key of a recipient, or of a sender in the auth mode.
+type PrivateKey interface {
+	KEM() KEM
+	Bytes() []byte
+	PublicKey() PublicKey
+
+	// decapsulate returns the shared secret of enc. sender is nil in the base mode.
+	decapsulate(enc []byte, sender PublicKey) (sharedSecret []byte, err error)
+}
+
+var (
+	errAuthNotSupported = errors.New("hpke: the KEM does not support the auth mode")
+	errMismatchedKEM    = errors.New("hpke: mismatched KEM")
+)
+
+var (
+	// DHKEMX25519 is DHKEM(X25519, HKDF-SHA256), the only KEM of this package which supports the
+	// auth mode.
+	DHKEMX25519 KEM = &dhKEM{dhkem.X25519HKDFSHA256}
+
+	// MLKEM768 is ML-KEM-768, as specified for HPKE by draft-ietf-hpke-pq.
+	MLKEM768 KEM = &mlKEM768{}
+
+	// XWing is X-Wing, as specified for HPKE by draft-ietf-hpke-pq.
+	XWing KEM = &xwingKEM{}
+)
+
+type dhKEM struct {
+	scheme *dhkem.Scheme
+}
+
+type dhKEMPrivateKey struct {
+	kem *dhKEM
+	key *dhkem.DecapsulationKey
+}
+
+type dhKEMPublicKey struct {
+	kem *dhKEM
+	key *dhkem.EncapsulationKey
+}
+
+func (kem *dhKEM) ID() uint16     { return kem.scheme.ID() }
+func (kem *dhKEM) String() string { return kem.scheme.String() }
+
+func (kem *dhKEM) GenerateKey() (PrivateKey, error) {
+	key, err := kem.scheme.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &dhKEMPrivateKey{kem, key}, nil
+}
+
+func (kem *dhKEM) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	key, err := kem.scheme.DeriveKeyPair(ikm)
+	if err != nil {
+		return nil, err
+	}
+	return &dhKEMPrivateKey{kem, key}, nil
+}
+
+func (kem *dhKEM) NewPrivateKey(privateKey []byte) (PrivateKey, error) {
+	key, err := kem.scheme.NewDecapsulationKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &dhKEMPrivateKey{kem, key}, nil
+}
+
+func (kem *dhKEM) NewPublicKey(publicKey []byte) (PublicKey, error) {
+	key, err := kem.scheme.NewEncapsulationKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &dhKEMPublicKey{kem, key}, nil
+}
+
+func (k *dhKEMPrivateKey) KEM() KEM      { return k.kem }
+func (k *dhKEMPrivateKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *dhKEMPrivateKey) PublicKey() PublicKey {
+	return &dhKEMPublicKey{k.kem, k.key.EncapsulationKey()}
+}
+
+func (k *dhKEMPrivateKey) decapsulate(enc []byte, sender PublicKey) ([]byte, error) {
+	var senderKey *dhkem.EncapsulationKey
+	if sender != nil {
+		s, ok := sender.(*dhKEMPublicKey)
+		if !ok || s.kem != k.kem {
+			return nil, errMismatchedKEM
+		}
+		senderKey = s.key
+	}
+	return k.key.AuthDecapsulate(enc, senderKey)
+}
+
+func (k *dhKEMPublicKey) KEM() KEM      { return k.kem }
+func (k *dhKEMPublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *dhKEMPublicKey) encapsulate(random []byte, sender PrivateKey) ([]byte, []byte, error) {
+	var senderKey *dhkem.DecapsulationKey
+	if sender != nil {
+		s, ok := sender.(*dhKEMPrivateKey)
+		if !ok || s.kem != k.kem {
+			return nil, nil, errMismatchedKEM
+		}
+		senderKey = s.key
+	}
+	if random != nil {
+		return k.key.AuthEncapsulateDerand(random, senderKey)
+	}
+	return k.key.AuthEncapsulate(senderKey)
+}
+
+// deriveSeed is the DeriveKeyPair of the KEMs of draft-ietf-hpke-pq: the seed of the private key
+// is derived from ikm with the LabeledDerive function of SHAKE256.
+func deriveSeed(kemID uint16, ikm []byte, length int) []byte {
+	suiteID := binary.BigEndian.AppendUint16([]byte("KEM"), kemID)
+	label := "DeriveKeyPair"
+
+	h := sha3.NewSHAKE256()
+	h.Write(ikm)
+	h.Write([]byte(hpkelabel.VersionLabel))
+	h.Write(suiteID)
+	h.Write(binary.BigEndian.AppendUint16(nil, uint16(len(label))))
+	h.Write([]byte(label))
+	h.Write(binary.BigEndian.AppendUint16(nil, uint16(length)))
+	seed := make([]byte, length)
+	h.Read(seed)
+	return seed
+}
+
+type mlKEM768 struct{}
+
+type mlKEM768PrivateKey struct {
+	key *mlkem.DecapsulationKey768
+}
+
+type mlKEM768PublicKey struct {
+	key *mlkem.EncapsulationKey768
+}
+
+func (kem *mlKEM768) ID() uint16     { return 0x0041 }
+func (kem *mlKEM768) String() string { return "ML-KEM-768" }
+
+func (kem *mlKEM768) GenerateKey() (PrivateKey, error) {
+	key, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, err
+	}
+	return &mlKEM768PrivateKey{key}, nil
+}
+
+func (kem *mlKEM768) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	return kem.NewPrivateKey(deriveSeed(kem.ID(), ikm, mlkem.SeedSize))
+}
+
+func (kem *mlKEM768) NewPrivateKey(privateKey []byte) (PrivateKey, error) {
+	key, err := mlkem.NewDecapsulationKey768(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &mlKEM768PrivateKey{key}, nil
+}
+
+func (kem *mlKEM768) NewPublicKey(publicKey []byte) (PublicKey, error) {
+	key, err := mlkem.NewEncapsulationKey768(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &mlKEM768PublicKey{key}, nil
+}
+
+func (k *mlKEM768PrivateKey) KEM() KEM      { return MLKEM768 }
+func (k *mlKEM768PrivateKey) Bytes() []byte { return k.key.Bytes() }
+func (k *mlKEM768PrivateKey) PublicKey() PublicKey {
+	return &mlKEM768PublicKey{k.key.EncapsulationKey()}
+}
+
+func (k *mlKEM768PrivateKey) decapsulate(enc []byte, sender PublicKey) ([]byte, error) {
+	if sender != nil {
+		return nil, errAuthNotSupported
+	}
+	return k.key.Decapsulate(enc)
+}
+
+func (k *mlKEM768PublicKey) KEM() KEM      { return MLKEM768 }
+func (k *mlKEM768PublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *mlKEM768PublicKey) encapsulate(random []byte, sender PrivateKey) ([]byte, []byte, error) {
+	if sender != nil {
+		return nil, nil, errAuthNotSupported
+	}
+	if random != nil {
+		sharedSecret, enc, err := mlkemtest.Encapsulate768(k.key, random)
+		return enc, sharedSecret, err
+	}
+	sharedSecret, enc := k.key.Encapsulate()
+	return enc, sharedSecret, nil
+}
+
+type xwingKEM struct{}
+
+type xwingPrivateKey struct {
+	key *xwing.DecapsulationKey
+}
+
+type xwingPublicKey struct {
+	key *xwing.EncapsulationKey
+}
+
+func (kem *xwingKEM) ID() uint16     { return 0x647a }
+func (kem *xwingKEM) String() string { return "X-Wing" }
+
+func (kem *xwingKEM) GenerateKey() (PrivateKey, error) {
+	key, err := xwing.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &xwingPrivateKey{key}, nil
+}
+
+func (kem *xwingKEM) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	return kem.NewPrivateKey(deriveSeed(kem.ID(), ikm, xwing.SeedSize))
+}
+
+func (kem *xwingKEM) NewPrivateKey(privateKey []byte) (PrivateKey, error) {
+	key, err := xwing.NewKeyFromSeed(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &xwingPrivateKey{key}, nil
+}
+
+func (kem *xwingKEM) NewPublicKey(publicKey []byte) (PublicKey, error) {
+	key, err := xwing.NewEncapsulationKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &xwingPublicKey{key}, nil
+}
+
+func (k *xwingPrivateKey) KEM() KEM             { return XWing }
+func (k *xwingPrivateKey) Bytes() []byte        { return k.key.Bytes() }
+func (k *xwingPrivateKey) PublicKey() PublicKey { return &xwingPublicKey{k.key.EncapsulationKey()} }
+
+func (k *xwingPrivateKey) decapsulate(enc []byte, sender PublicKey) ([]byte, error) {
+	if sender != nil {
+		return nil, errAuthNotSupported
+	}
+	return k.key.Decapsulate(enc)
+}
+
+func (k *xwingPublicKey) KEM() KEM      { return XWing }
+func (k *xwingPublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *xwingPublicKey) encapsulate(random []byte, sender PrivateKey) ([]byte, []byte, error) {
+	if sender != nil {
+		return nil, nil, errAuthNotSupported
+	}
+	if random != nil {
+		return k.key.EncapsulateDerand(random)
+	}
+	return k.key.Encapsulate()
+}
@@ -0,0 +1,90 @@
+package hybridkem
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/sha3"
+)
+
+// Shares are the inputs of a Combiner: the shared secrets, ciphertexts and encapsulation keys of
+// the post-quantum (PQ) and traditional (T) components.
+type Shares struct {
+	SharedSecretPQ     []byte
+	SharedSecretT      []byte
+	CiphertextPQ       []byte
+	CiphertextT        []byte
+	EncapsulationKeyPQ []byte
+	EncapsulationKeyT  []byte
+}
+
+// A Combiner derives the SharedKeySize-byte shared key of a hybrid KEM from the shares of its
+// components and a label.
+type Combiner interface {
+	Combine(label []byte, shares *Shares) []byte
+	String() string
+}
+
+var (
+	// XWing is the combiner of X-Wing: SHA3-256(ss_PQ || ss_T || ct_T || ek_T || label). It
+	// relies on ML-KEM being ciphertext second-preimage resistant to skip ct_PQ and ek_PQ, so
+	// it must only be used with ML-KEM.
+	XWing Combiner = xwingCombiner{}
+
+	// KitchenSink hashes everything:
+	// SHA3-256(ss_PQ || ss_T || ct_PQ || ct_T || ek_PQ || ek_T || label). It is the most
+	// conservative combiner but hashes the large ML-KEM encapsulation key and ciphertext.
+	KitchenSink Combiner = kitchenSinkCombiner{}
+
+	// ConcatKDF only binds the shared secrets: HKDF-SHA256 with ss_PQ || ss_T as input keying
+	// material and the label as info, as done by TLS 1.3 hybrid key exchanges.
+	ConcatKDF Combiner = concatKDFCombiner{}
+)
+
+type xwingCombiner struct{}
+
+func (xwingCombiner) Combine(label []byte, shares *Shares) []byte {
+	h := sha3.New256()
+	h.Write(shares.SharedSecretPQ)
+	h.Write(shares.SharedSecretT)
+	h.Write(shares.CiphertextT)
+	h.Write(shares.EncapsulationKeyT)
+	h.Write(label)
+	return h.Sum(nil)
+}
+
+func (xwingCombiner) String() string {
+	return "XWing"
+}
+
+type kitchenSinkCombiner struct{}
+
+func (kitchenSinkCombiner) Combine(label []byte, shares *Shares) []byte {
+	h := sha3.New256()
+	h.Write(shares.SharedSecretPQ)
+	h.Write(shares.SharedSecretT)
+	h.Write(shares.CiphertextPQ)
+	h.Write(shares.CiphertextT)
+	h.Write(shares.EncapsulationKeyPQ)
+	h.Write(shares.EncapsulationKeyT)
+	h.Write(label)
+	return h.Sum(nil)
+}
+
+func (kitchenSinkCombiner) String() string {
+	return "KitchenSink"
+}
+
+type concatKDFCombiner struct{}
+
+func (concatKDFCombiner) Combine(label []byte, shares *Shares) []byte {
+	secret := make([]byte, 0, len(shares.SharedSecretPQ)+len(shares.SharedSecretT))
+	secret = append(secret, shares.SharedSecretPQ...)
+	secret = append(secret, shares.SharedSecretT...)
+	// HKDF only fails for output lengths above 255 hash sizes
+	sharedKey, _ := hkdf.Key(sha256.New, secret, nil, string(label), SharedKeySize)
+	return sharedKey
+}
+
+func (concatKDFCombiner) String() string {
+	return "ConcatKDF"
+}
@@ -0,0 +1,320 @@
+// Package hybridkem implements hybrid KEMs which combine an ML-KEM parameter set with a
+// traditional KEM built on an ECDH curve, so that the shared key is secure as long as one of
+// the two is [1].
+//
+// A Scheme is defined by its ML-KEM parameter set, its crypto/ecdh curve, a Combiner which
+// derives the shared key from the two shared secrets and a label for domain separation. With
+// ML-KEM-768, X25519, the XWing combiner and XWingLabel, it is X-Wing.
+//
+// Keys are derived from a 32-byte seed like X-Wing keys: the seed is expanded with SHAKE256
+// into the 64-byte ML-KEM seed followed by the ECDH private key. The ECDH KEM is ephemeral-static
+// Diffie-Hellman: its ciphertext is the ephemeral public key and its shared secret the ECDH
+// output.
+//
+// [1] https://datatracker.ietf.org/doc/draft-irtf-cfrg-hybrid-kems/
+package hybridkem
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha3"
+	"errors"
+	"fmt"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/x25519"
+)
+
+const (
+	// SeedSize is the size of a decapsulation key seed.
+	SeedSize = 32
+	// SharedKeySize is the size of the shared keys of all the combiners.
+	SharedKeySize = 32
+
+	// mlKemRandomnessSize is the size of the randomness of ML-KEM encapsulation.
+	mlKemRandomnessSize = 32
+)
+
+// XWingLabel is the label of X-Wing.
+const XWingLabel = (`` +
+	`\./` +
+	`/^\`)
+
+// A Scheme is a hybrid KEM. It is safe for concurrent use.
+type Scheme struct {
+	mlKem    *MLKEM
+	curve    ecdh.Curve
+	combiner Combiner
+	label    []byte
+	name     string
+
+	// scalarSize is the size of the ECDH private keys and scalarMask the mask of their first
+	// byte to keep them below the order of the curve with high probability
+	scalarSize int
+	scalarMask byte
+}
+
+// New returns the hybrid KEM combining mlKem and curve with combiner and label. It returns an
+// error for the curves other than X25519, P-256, P-384 and P-521.
+func New(mlKem *MLKEM, curve ecdh.Curve, combiner Combiner, label []byte) (*Scheme, error) {
+	scheme := &Scheme{
+		mlKem:    mlKem,
+		curve:    curve,
+		combiner: combiner,
+		label:    bytes.Clone(label),
+		name:     fmt.Sprintf("%s-%s-%s", mlKem, curve, combiner),
+	}
+
+	switch curve {
+	case ecdh.X25519(), ecdh.P256():
+		scheme.scalarSize, scheme.scalarMask = 32, 0xff
+	case ecdh.P384():
+		scheme.scalarSize, scheme.scalarMask = 48, 0xff
+	case ecdh.P521():
+		scheme.scalarSize, scheme.scalarMask = 66, 0x01
+	default:
+		return nil, errors.New("hybridkem: unsupported curve")
+	}
+	return scheme, nil
+}
+
+// String returns the name of the scheme, e.g. ML-KEM-768-X25519-XWing.
+func (scheme *Scheme) String() string {
+	return scheme.name
+}
+
+// EncapsulationKeySize returns the size of the encapsulation keys: the ML-KEM encapsulation key
+// followed by the ECDH public key.
+func (scheme *Scheme) EncapsulationKeySize() int {
+	return scheme.mlKem.encapsulationKeySize + scheme.publicKeySize()
+}
+
+// CiphertextSize returns the size of the ciphertexts: the ML-KEM ciphertext followed by the
+// ephemeral ECDH public key.
+func (scheme *Scheme) CiphertextSize() int {
+	return scheme.mlKem.ciphertextSize + scheme.publicKeySize()
+}
+
+// EncapsulationSeedSize returns the size of the randomness used by EncapsulateDerand: the
+// ML-KEM encapsulation randomness followed by the ephemeral ECDH private key.
+func (scheme *Scheme) EncapsulationSeedSize() int {
+	return mlKemRandomnessSize + scheme.scalarSize
+}
+
+func (scheme *Scheme) publicKeySize() int {
+	if scheme.curve == ecdh.X25519() {
+		return 32
+	}
+	// uncompressed point
+	return 1 + 2*scheme.scalarSize
+}
+
+// A DecapsulationKey is the secret key used to decapsulate a shared key from a ciphertext.
+type DecapsulationKey struct {
+	seed [SeedSize]byte
+	dkPQ mlKemDecapsulationKey
+	dkT  *ecdh.PrivateKey
+	ek   *EncapsulationKey
+}
+
+// An EncapsulationKey is the public key used to produce ciphertexts to be decapsulated by the
+// corresponding DecapsulationKey.
+type EncapsulationKey struct {
+	scheme *Scheme
+	ekPQ   mlKemEncapsulationKey
+	ekT    *ecdh.PublicKey
+	bytes  []byte
+}
+
+// GenerateKey generates a new decapsulation key, drawing random bytes from crypto/rand.
+func (scheme *Scheme) GenerateKey() (*DecapsulationKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return scheme.NewKeyFromSeed(seed)
+}
+
+// NewKeyFromSeed deterministically derives a decapsulation key from a 32-byte seed. The seed
+// must be uniformly random.
+func (scheme *Scheme) NewKeyFromSeed(seed []byte) (*DecapsulationKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("hybridkem: invalid seed length")
+	}
+
+	expander := sha3.NewSHAKE256()
+	expander.Write(seed)
+	mlKemSeed := make([]byte, mlkem.SeedSize)
+	expander.Read(mlKemSeed)
+
+	dkPQ, err := scheme.mlKem.newDecapsulationKey(mlKemSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	// rejection sampling of the ECDH private key, which for X25519 and with the mask for the
+	// NIST curves succeeds at the first try with overwhelming probability
+	scalar := make([]byte, scheme.scalarSize)
+	var dkT *ecdh.PrivateKey
+	for {
+		expander.Read(scalar)
+		scalar[0] &= scheme.scalarMask
+		if dkT, err = scheme.curve.NewPrivateKey(scalar); err == nil {
+			break
+		}
+	}
+
+	ekPQ := dkPQ.encapsulationKey()
+	ekT := dkT.PublicKey()
+	ek := &EncapsulationKey{
+		scheme: scheme,
+		ekPQ:   ekPQ,
+		ekT:    ekT,
+		bytes:  append(ekPQ.Bytes(), ekT.Bytes()...),
+	}
+
+	dk := &DecapsulationKey{
+		dkPQ: dkPQ,
+		dkT:  dkT,
+		ek:   ek,
+	}
+	copy(dk.seed[:], seed)
+	return dk, nil
+}
+
+// Bytes returns the decapsulation key as a 32-byte seed.
+func (dk *DecapsulationKey) Bytes() []byte {
+	return bytes.Clone(dk.seed[:])
+}
+
+// EncapsulationKey returns the public encapsulation key necessary to produce ciphertexts.
+func (dk *DecapsulationKey) EncapsulationKey() *EncapsulationKey {
+	return dk.ek
+}
+
+// NewEncapsulationKey parses an encapsulation key of the scheme. It returns an error if the
+// ML-KEM key fails the modulus check of FIPS 203, if the ECDH public key is not a valid point or
+// if it is an X25519 point of small order, for which the shared secret would be all zeros.
+func (scheme *Scheme) NewEncapsulationKey(encapsulationKey []byte) (*EncapsulationKey, error) {
+	if len(encapsulationKey) != scheme.EncapsulationKeySize() {
+		return nil, errors.New("hybridkem: invalid encapsulation key length")
+	}
+
+	ekPQ, err := scheme.mlKem.newEncapsulationKey(encapsulationKey[:scheme.mlKem.encapsulationKeySize])
+	if err != nil {
+		return nil, err
+	}
+	ekTBytes := encapsulationKey[scheme.mlKem.encapsulationKeySize:]
+	if scheme.curve == ecdh.X25519() && x25519.IsSmallOrder(ekTBytes) {
+		return nil, errors.New("hybridkem: invalid X25519 public key")
+	}
+	ekT, err := scheme.curve.NewPublicKey(ekTBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncapsulationKey{
+		scheme: scheme,
+		ekPQ:   ekPQ,
+		ekT:    ekT,
+		bytes:  bytes.Clone(encapsulationKey),
+	}, nil
+}
+
+// Bytes returns the encapsulation key in its encoded form.
+func (ek *EncapsulationKey) Bytes() []byte {
+	return bytes.Clone(ek.bytes)
+}
+
+// Encapsulate generates a shared key and an associated ciphertext, drawing random bytes from
+// crypto/rand.
+func (ek *EncapsulationKey) Encapsulate() (ciphertext, sharedKey []byte, err error) {
+	ephemeralKey, err := ek.scheme.curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ssPQ, ctPQ := ek.ekPQ.encapsulate()
+	return ek.encapsulate(ssPQ, ctPQ, ephemeralKey)
+}
+
+// EncapsulateDerand is the derandomized version of Encapsulate, using eseed, of
+// EncapsulationSeedSize bytes, as randomness.
+//
+// Like mlkemtest.Encapsulate768 and mlkemtest.Encapsulate1024 which it uses, it must only be
+// used for known-answer tests.
+func (ek *EncapsulationKey) EncapsulateDerand(eseed []byte) (ciphertext, sharedKey []byte, err error) {
+	if len(eseed) != ek.scheme.EncapsulationSeedSize() {
+		return nil, nil, errors.New("hybridkem: invalid encapsulation seed length")
+	}
+
+	ephemeralKey, err := ek.scheme.curve.NewPrivateKey(eseed[mlKemRandomnessSize:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ssPQ, ctPQ, err := ek.ekPQ.encapsulateDerand(eseed[:mlKemRandomnessSize])
+	if err != nil {
+		return nil, nil, err
+	}
+	return ek.encapsulate(ssPQ, ctPQ, ephemeralKey)
+}
+
+func (ek *EncapsulationKey) encapsulate(ssPQ, ctPQ []byte, ephemeralKey *ecdh.PrivateKey) (ciphertext, sharedKey []byte, err error) {
+	ssT, err := ephemeralKey.ECDH(ek.ekT)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctT := ephemeralKey.PublicKey().Bytes()
+
+	sharedKey = ek.scheme.combiner.Combine(ek.scheme.label, &Shares{
+		SharedSecretPQ:     ssPQ,
+		SharedSecretT:      ssT,
+		CiphertextPQ:       ctPQ,
+		CiphertextT:        ctT,
+		EncapsulationKeyPQ: ek.bytes[:ek.scheme.mlKem.encapsulationKeySize],
+		EncapsulationKeyT:  ek.bytes[ek.scheme.mlKem.encapsulationKeySize:],
+	})
+
+	ciphertext = make([]byte, 0, ek.scheme.CiphertextSize())
+	ciphertext = append(ciphertext, ctPQ...)
+	ciphertext = append(ciphertext, ctT...)
+	return ciphertext, sharedKey, nil
+}
+
+// Decapsulate generates a shared key from a ciphertext. It returns an error if the ciphertext
+// has the wrong length, if its ECDH part is not a valid point or if the ECDH shared secret is
+// all zeros.
+func (dk *DecapsulationKey) Decapsulate(ciphertext []byte) (sharedKey []byte, err error) {
+	scheme := dk.ek.scheme
+	if len(ciphertext) != scheme.CiphertextSize() {
+		return nil, errors.New("hybridkem: invalid ciphertext length")
+	}
+
+	ctPQ := ciphertext[:scheme.mlKem.ciphertextSize]
+	ctT := ciphertext[scheme.mlKem.ciphertextSize:]
+
+	ssPQ, err := dk.dkPQ.decapsulate(ctPQ)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralKey, err := scheme.curve.NewPublicKey(ctT)
+	if err != nil {
+		return nil, err
+	}
+	ssT, err := dk.dkT.ECDH(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheme.combiner.Combine(scheme.label, &Shares{
+		SharedSecretPQ:     ssPQ,
+		SharedSecretT:      ssT,
+		CiphertextPQ:       ctPQ,
+		CiphertextT:        ctT,
+		EncapsulationKeyPQ: dk.ek.bytes[:scheme.mlKem.encapsulationKeySize],
+		EncapsulationKeyT:  dk.ek.bytes[scheme.mlKem.encapsulationKeySize:],
+	}), nil
+}
@@ -0,0 +1,228 @@
+package hybridkem
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/x25519"
+	"github.com/skerkour/go-benchmarks/crypto/xwing"
+)
+
+var curves = []ecdh.Curve{ecdh.X25519(), ecdh.P256(), ecdh.P384(), ecdh.P521()}
+
+var combiners = []Combiner{XWing, KitchenSink, ConcatKDF}
+
+func newScheme(t *testing.T, mlKem *MLKEM, curve ecdh.Curve, combiner Combiner) *Scheme {
+	t.Helper()
+	scheme, err := New(mlKem, curve, combiner, []byte("hybridkem test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+// TestXWing checks that the scheme of ML-KEM-768, X25519 and the XWing combiner with the X-Wing
+// label is X-Wing, whose implementation is tested against the vectors of the specification.
+func TestXWing(t *testing.T) {
+	scheme, err := New(MLKEM768, ecdh.X25519(), XWing, []byte(XWingLabel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme.EncapsulationKeySize() != xwing.EncapsulationKeySize {
+		t.Errorf("expected an encapsulation key of %d bytes, got %d", xwing.EncapsulationKeySize, scheme.EncapsulationKeySize())
+	}
+	if scheme.CiphertextSize() != xwing.CiphertextSize {
+		t.Errorf("expected a ciphertext of %d bytes, got %d", xwing.CiphertextSize, scheme.CiphertextSize())
+	}
+
+	for range 10 {
+		seed := make([]byte, SeedSize)
+		rand.Read(seed)
+		eseed := make([]byte, xwing.EncapsulationSeedSize)
+		rand.Read(eseed)
+
+		dk, err := scheme.NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := xwing.NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dk.EncapsulationKey().Bytes(), want.EncapsulationKey().Bytes()) {
+			t.Fatal("encapsulation keys differ from X-Wing")
+		}
+
+		ciphertext, sharedKey, err := dk.EncapsulationKey().EncapsulateDerand(eseed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantCiphertext, wantSharedKey, err := want.EncapsulationKey().EncapsulateDerand(eseed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, wantCiphertext) {
+			t.Fatal("ciphertexts differ from X-Wing")
+		}
+		if !bytes.Equal(sharedKey, wantSharedKey) {
+			t.Fatal("shared keys differ from X-Wing")
+		}
+
+		decapsulated, err := want.Decapsulate(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decapsulated, sharedKey) {
+			t.Fatal("X-Wing decapsulated a different shared key")
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, mlKem := range []*MLKEM{MLKEM768, MLKEM1024} {
+		for _, curve := range curves {
+			for _, combiner := range combiners {
+				scheme := newScheme(t, mlKem, curve, combiner)
+				t.Run(scheme.String(), func(t *testing.T) {
+					dk, err := scheme.GenerateKey()
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					ek, err := scheme.NewEncapsulationKey(dk.EncapsulationKey().Bytes())
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(ek.Bytes()) != scheme.EncapsulationKeySize() {
+						t.Errorf("expected an encapsulation key of %d bytes, got %d", scheme.EncapsulationKeySize(), len(ek.Bytes()))
+					}
+
+					ciphertext, sharedKey, err := ek.Encapsulate()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(ciphertext) != scheme.CiphertextSize() {
+						t.Errorf("expected a ciphertext of %d bytes, got %d", scheme.CiphertextSize(), len(ciphertext))
+					}
+					if len(sharedKey) != SharedKeySize {
+						t.Errorf("expected a shared key of %d bytes, got %d", SharedKeySize, len(sharedKey))
+					}
+
+					decapsulated, err := dk.Decapsulate(ciphertext)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(decapsulated, sharedKey) {
+						t.Fatal("decapsulated a different shared key")
+					}
+
+					// the decapsulation key is its seed
+					reloaded, err := scheme.NewKeyFromSeed(dk.Bytes())
+					if err != nil {
+						t.Fatal(err)
+					}
+					decapsulated, err = reloaded.Decapsulate(ciphertext)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(decapsulated, sharedKey) {
+						t.Fatal("the key reloaded from its seed decapsulated a different shared key")
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestDomainSeparation checks that the same components and randomness give different shared
+// keys with different combiners or labels.
+func TestDomainSeparation(t *testing.T) {
+	seed := make([]byte, SeedSize)
+	rand.Read(seed)
+	eseed := make([]byte, mlKemRandomnessSize+32)
+	rand.Read(eseed)
+
+	sharedKeys := make(map[string]string)
+	for _, combiner := range combiners {
+		for _, label := range []string{"label 1", "label 2"} {
+			scheme, err := New(MLKEM768, ecdh.P256(), combiner, []byte(label))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dk, err := scheme.NewKeyFromSeed(seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, sharedKey, err := dk.EncapsulationKey().EncapsulateDerand(eseed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name := scheme.String() + " " + label
+			if other, ok := sharedKeys[string(sharedKey)]; ok {
+				t.Errorf("%s and %s give the same shared key", name, other)
+			}
+			sharedKeys[string(sharedKey)] = name
+		}
+	}
+}
+
+func TestInvalidInputs(t *testing.T) {
+	for _, curve := range curves {
+		scheme := newScheme(t, MLKEM768, curve, KitchenSink)
+		t.Run(scheme.String(), func(t *testing.T) {
+			if _, err := scheme.NewKeyFromSeed(make([]byte, SeedSize-1)); err == nil {
+				t.Error("expected an error for a short seed")
+			}
+
+			dk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ek := dk.EncapsulationKey().Bytes()
+
+			if _, err := scheme.NewEncapsulationKey(ek[1:]); err == nil {
+				t.Error("expected an error for a short encapsulation key")
+			}
+			// an ML-KEM coefficient of 0xfff, above the modulus
+			invalid := bytes.Clone(ek)
+			invalid[0], invalid[1] = 0xff, 0x0f
+			if _, err := scheme.NewEncapsulationKey(invalid); err == nil {
+				t.Error("expected an error for a ML-KEM encapsulation key failing the modulus check")
+			}
+			// the all-zeros X25519 point is of small order and is not a valid NIST point
+			invalid = bytes.Clone(ek)
+			clear(invalid[MLKEM768.encapsulationKeySize:])
+			if _, err := scheme.NewEncapsulationKey(invalid); err == nil {
+				t.Error("expected an error for an invalid ECDH public key")
+			}
+			if curve == ecdh.X25519() {
+				for _, point := range x25519.SmallOrderPoints {
+					invalid = bytes.Clone(ek)
+					copy(invalid[MLKEM768.encapsulationKeySize:], point[:])
+					if _, err := scheme.NewEncapsulationKey(invalid); err == nil {
+						t.Errorf("expected an error for the small order X25519 key %x", point)
+					}
+				}
+			}
+
+			if _, _, err := dk.EncapsulationKey().EncapsulateDerand(make([]byte, scheme.EncapsulationSeedSize()+1)); err == nil {
+				t.Error("expected an error for a long encapsulation seed")
+			}
+
+			ciphertext, _, err := dk.EncapsulationKey().Encapsulate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := dk.Decapsulate(ciphertext[1:]); err == nil {
+				t.Error("expected an error for a short ciphertext")
+			}
+			clear(ciphertext[MLKEM768.ciphertextSize:])
+			if _, err := dk.Decapsulate(ciphertext); err == nil {
+				t.Error("expected an error for an all-zeros ECDH ciphertext")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package hybridkem
+
+import (
+	"crypto/mlkem"
+	"crypto/mlkem/mlkemtest"
+)
+
+// MLKEM is an ML-KEM parameter set of crypto/mlkem.
+type MLKEM struct {
+	name                 string
+	encapsulationKeySize int
+	ciphertextSize       int
+	newDecapsulationKey  func(seed []byte) (mlKemDecapsulationKey, error)
+	newEncapsulationKey  func(encapsulationKey []byte) (mlKemEncapsulationKey, error)
+}
+
+// String returns the name of the parameter set, e.g. ML-KEM-768.
+func (parameters *MLKEM) String() string {
+	return parameters.name
+}
+
+var (
+	// MLKEM768 is ML-KEM-768, which targets NIST security category 3.
+	MLKEM768 = &MLKEM{
+		name:                 "ML-KEM-768",
+		encapsulationKeySize: mlkem.EncapsulationKeySize768,
+		ciphertextSize:       mlkem.CiphertextSize768,
+		newDecapsulationKey: func(seed []byte) (mlKemDecapsulationKey, error) {
+			dk, err := mlkem.NewDecapsulationKey768(seed)
+			if err != nil {
+				return nil, err
+			}
+			return mlKem768DecapsulationKey{dk}, nil
+		},
+		newEncapsulationKey: func(encapsulationKey []byte) (mlKemEncapsulationKey, error) {
+			ek, err := mlkem.NewEncapsulationKey768(encapsulationKey)
+			if err != nil {
+				return nil, err
+			}
+			return mlKem768EncapsulationKey{ek}, nil
+		},
+	}
+
+	// MLKEM1024 is ML-KEM-1024, which targets NIST security category 5.
+	MLKEM1024 = &MLKEM{
+		name:                 "ML-KEM-1024",
+		encapsulationKeySize: mlkem.EncapsulationKeySize1024,
+		ciphertextSize:       mlkem.CiphertextSize1024,
+		newDecapsulationKey: func(seed []byte) (mlKemDecapsulationKey, error) {
+			dk, err := mlkem.NewDecapsulationKey1024(seed)
+			if err != nil {
+				return nil, err
+			}
+			return mlKem1024DecapsulationKey{dk}, nil
+		},
+		newEncapsulationKey: func(encapsulationKey []byte) (mlKemEncapsulationKey, error) {
+			ek, err := mlkem.NewEncapsulationKey1024(encapsulationKey)
+			if err != nil {
+				return nil, err
+			}
+			return mlKem1024EncapsulationKey{ek}, nil
+		},
+	}
+)
+
+// mlKemDecapsulationKey and mlKemEncapsulationKey abstract over the types of the parameter sets
+// of crypto/mlkem.
+type mlKemDecapsulationKey interface {
+	decapsulate(ciphertext []byte) (sharedKey []byte, err error)
+	encapsulationKey() mlKemEncapsulationKey
+}
+
+type mlKemEncapsulationKey interface {
+	Bytes() []byte
+	encapsulate() (sharedKey, ciphertext []byte)
+	encapsulateDerand(random []byte) (sharedKey, ciphertext []byte, err error)
+}
+
+type mlKem768DecapsulationKey struct {
+	*mlkem.DecapsulationKey768
+}
+
+func (dk mlKem768DecapsulationKey) decapsulate(ciphertext []byte) ([]byte, error) {
+	return dk.Decapsulate(ciphertext)
+}
+
+func (dk mlKem768DecapsulationKey) encapsulationKey() mlKemEncapsulationKey {
+	return mlKem768EncapsulationKey{dk.EncapsulationKey()}
+}
+
+type mlKem768EncapsulationKey struct {
+	*mlkem.EncapsulationKey768
+}
+
+func (ek mlKem768EncapsulationKey) encapsulate() ([]byte, []byte) {
+	return ek.Encapsulate()
+}
+
+func (ek mlKem768EncapsulationKey) encapsulateDerand(random []byte) ([]byte, []byte, error) {
+	return mlkemtest.Encapsulate768(ek.EncapsulationKey768, random)
+}
+
+type mlKem1024DecapsulationKey struct {
+	*mlkem.DecapsulationKey1024
+}
+
+func (dk mlKem1024DecapsulationKey) decapsulate(ciphertext []byte) ([]byte, error) {
+	return dk.Decapsulate(ciphertext)
+}
+
+func (dk mlKem1024DecapsulationKey) encapsulationKey() mlKemEncapsulationKey {
+	return mlKem1024EncapsulationKey{dk.EncapsulationKey()}
+}
+
+type mlKem1024EncapsulationKey struct {
+	*mlkem.EncapsulationKey1024
+}
+
+func (ek mlKem1024EncapsulationKey) encapsulate() ([]byte, []byte) {
+	return ek.Encapsulate()
+}
+
+func (ek mlKem1024EncapsulationKey) encapsulateDerand(random []byte) ([]byte, []byte, error) {
+	return mlkemtest.Encapsulate1024(ek.EncapsulationKey1024, random)
+}
@@ -0,0 +1,38 @@
+// Package hpkelabel implements the labeled HKDF functions of section 4 of RFC 9180 [1], shared by
+// DHKEM and the HPKE key schedule.
+//
+// [1] https://www.rfc-editor.org/rfc/rfc9180.html#section-4
+package hpkelabel
+
+import (
+	"crypto/hkdf"
+	"encoding/binary"
+	"hash"
+)
+
+// VersionLabel is the version prefix of all the labels of RFC 9180.
+const VersionLabel = "HPKE-v1"
+
+// Extract is LabeledExtract of section 4 of RFC 9180.
+func Extract(h func() hash.Hash, suiteID, salt []byte, label string, ikm []byte) []byte {
+	labeledIKM := make([]byte, 0, len(VersionLabel)+len(suiteID)+len(label)+len(ikm))
+	labeledIKM = append(labeledIKM, VersionLabel...)
+	labeledIKM = append(labeledIKM, suiteID...)
+	labeledIKM = append(labeledIKM, label...)
+	labeledIKM = append(labeledIKM, ikm...)
+	// HKDF-Extract can't fail
+	prk, _ := hkdf.Extract(h, labeledIKM, salt)
+	return prk
+}
+
+// Expand is LabeledExpand of section 4 of RFC 9180. It returns an error if length is above 255
+// times the size of the hash.
+func Expand(h func() hash.Hash, suiteID, prk []byte, label string, info []byte, length int) ([]byte, error) {
+	labeledInfo := make([]byte, 0, 2+len(VersionLabel)+len(suiteID)+len(label)+len(info))
+	labeledInfo = binary.BigEndian.AppendUint16(labeledInfo, uint16(length))
+	labeledInfo = append(labeledInfo, VersionLabel...)
+	labeledInfo = append(labeledInfo, suiteID...)
+	labeledInfo = append(labeledInfo, label...)
+	labeledInfo = append(labeledInfo, info...)
+	return hkdf.Expand(h, prk, string(labeledInfo), length)
+}
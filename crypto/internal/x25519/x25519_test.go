@@ -0,0 +1,34 @@
+package x25519
+
+import (
+	"crypto/ecdh"
+	"testing"
+)
+
+// TestSmallOrderPoints checks that the X25519 shared secret with the points of SmallOrderPoints
+// is all zeros, which crypto/ecdh reports as an error.
+func TestSmallOrderPoints(t *testing.T) {
+	privateKey, err := ecdh.X25519().GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, point := range SmallOrderPoints {
+		publicKey, err := ecdh.X25519().NewPublicKey(point[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := privateKey.ECDH(publicKey); err == nil {
+			t.Errorf("%x is not of small order", point)
+		}
+		for _, msb := range []byte{0, 0x80} {
+			encoding := point
+			encoding[PublicKeySize-1] |= msb
+			if !IsSmallOrder(encoding[:]) {
+				t.Errorf("%x not reported as of small order", encoding)
+			}
+		}
+	}
+	if IsSmallOrder(privateKey.PublicKey().Bytes()) {
+		t.Errorf("%x reported as of small order", privateKey.PublicKey().Bytes())
+	}
+}
@@ -0,0 +1,242 @@
+package k12
+
+import (
+	"runtime"
+	"sync"
+)
+
+const (
+	// ChunkSize is the size in bytes of the chunks in which KangarooTwelve splits its input.
+	ChunkSize = 8192
+
+	// domain separation bytes of the final node when the input fits in a single chunk, of the
+	// final node of a tree and of the leaves.
+	singleNodeSeparator = 0x07
+	finalNodeSeparator  = 0x06
+	leafSeparator       = 0x0B
+)
+
+// finalNodeMarker is appended to the first chunk when the input spans several chunks (110^62 in
+// the RFC).
+var finalNodeMarker = []byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// KT is an instance of KT128 or KT256. Data is absorbed with Write and an output of any length
+// can be squeezed with Read.
+//
+// The first chunk of the input is absorbed directly by the final node. The following chunks are
+// leaves: each one is hashed into a chaining value that is absorbed by the final node. When a
+// single Write provides several full chunks, they are hashed by up to GOMAXPROCS goroutines.
+//
+// specified in 3 of [1].
+type KT struct {
+	customization []byte
+	newTurboSHAKE func(separator byte) *TurboSHAKE
+	cvSize        int
+
+	finalNode TurboSHAKE
+	// firstChunkTodo is the number of bytes missing in the first chunk
+	firstChunkTodo int
+	tree           bool
+
+	leaf    TurboSHAKE
+	leafLen int
+	// leaves is the number of chaining values absorbed by the final node
+	leaves uint64
+
+	squeezing bool
+}
+
+// NewKT128 creates a KT128 instance, formerly known as KangarooTwelve, with the given
+// customization string.
+func NewKT128(customization []byte) *KT {
+	return newKT(NewTurboSHAKE128, 32, customization)
+}
+
+// NewKT256 creates a KT256 instance with the given customization string.
+func NewKT256(customization []byte) *KT {
+	return newKT(NewTurboSHAKE256, 64, customization)
+}
+
+func newKT(newTurboSHAKE func(separator byte) *TurboSHAKE, cvSize int, customization []byte) *KT {
+	k := &KT{
+		customization: customization,
+		newTurboSHAKE: newTurboSHAKE,
+		cvSize:        cvSize,
+		// the separator of the final node is only known once all the input has been written
+		finalNode: *newTurboSHAKE(singleNodeSeparator),
+		leaf:      *newTurboSHAKE(leafSeparator),
+	}
+	k.Reset()
+	return k
+}
+
+// SumKT128 returns length bytes of KT128 of data with the given customization string.
+func SumKT128(data []byte, length int, customization []byte) []byte {
+	k := NewKT128(customization)
+	k.Write(data)
+	out := make([]byte, length)
+	k.Read(out)
+	return out
+}
+
+// SumKT256 returns length bytes of KT256 of data with the given customization string.
+func SumKT256(data []byte, length int, customization []byte) []byte {
+	k := NewKT256(customization)
+	k.Write(data)
+	out := make([]byte, length)
+	k.Read(out)
+	return out
+}
+
+// BlockSize returns the chunk size.
+func (k *KT) BlockSize() int {
+	return ChunkSize
+}
+
+// Reset resets the KT to its initial state, keeping its customization string.
+func (k *KT) Reset() {
+	k.finalNode.Reset()
+	k.finalNode.separator = singleNodeSeparator
+	k.firstChunkTodo = ChunkSize
+	k.tree = false
+	k.leaf.Reset()
+	k.leafLen = 0
+	k.leaves = 0
+	k.squeezing = false
+}
+
+// Clone returns a copy of the KT in its current state, e.g. to derive several outputs from a
+// common prefix without absorbing it again.
+func (k *KT) Clone() *KT {
+	clone := *k
+	return &clone
+}
+
+// Write absorbs more data. It panics if output has already been read.
+func (k *KT) Write(p []byte) (int, error) {
+	if k.squeezing {
+		panic("k12: Write after Read")
+	}
+	k.write(p)
+	return len(p), nil
+}
+
+func (k *KT) write(p []byte) {
+	if k.firstChunkTodo > 0 {
+		n := min(k.firstChunkTodo, len(p))
+		k.finalNode.Write(p[:n])
+		k.firstChunkTodo -= n
+		p = p[n:]
+	}
+	if len(p) == 0 {
+		return
+	}
+
+	if !k.tree {
+		k.finalNode.Write(finalNodeMarker)
+		k.tree = true
+	}
+
+	if k.leafLen > 0 {
+		n := min(ChunkSize-k.leafLen, len(p))
+		k.leaf.Write(p[:n])
+		k.leafLen += n
+		p = p[n:]
+		if k.leafLen == ChunkSize {
+			k.flushLeaf()
+		}
+	}
+
+	if chunks := len(p) / ChunkSize; chunks > 0 {
+		k.absorbLeaves(p[:chunks*ChunkSize])
+		p = p[chunks*ChunkSize:]
+	}
+
+	if len(p) > 0 {
+		k.leaf.Write(p)
+		k.leafLen = len(p)
+	}
+}
+
+// flushLeaf absorbs the chaining value of the current leaf in the final node.
+func (k *KT) flushLeaf() {
+	var cv [64]byte
+	k.leaf.Read(cv[:k.cvSize])
+	k.finalNode.Write(cv[:k.cvSize])
+	k.leaf.Reset()
+	k.leafLen = 0
+	k.leaves += 1
+}
+
+// absorbLeaves hashes full chunks concurrently and absorbs their chaining values in order.
+func (k *KT) absorbLeaves(data []byte) {
+	chunks := len(data) / ChunkSize
+	cvs := make([]byte, chunks*k.cvSize)
+	sumLeaves := func(first, step int) {
+		leaf := k.newTurboSHAKE(leafSeparator)
+		for i := first; i < chunks; i += step {
+			leaf.Reset()
+			leaf.Write(data[i*ChunkSize : (i+1)*ChunkSize])
+			leaf.Read(cvs[i*k.cvSize : (i+1)*k.cvSize])
+		}
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), chunks)
+	if workers <= 1 {
+		sumLeaves(0, 1)
+	} else {
+		var wg sync.WaitGroup
+		for worker := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sumLeaves(worker, workers)
+			}()
+		}
+		wg.Wait()
+	}
+
+	k.finalNode.Write(cvs)
+	k.leaves += uint64(chunks)
+}
+
+// Read squeezes len(p) bytes of output. The first call appends the customization string to the
+// input, after which Write can't be called anymore.
+func (k *KT) Read(p []byte) (int, error) {
+	if !k.squeezing {
+		k.finish()
+	}
+	return k.finalNode.Read(p)
+}
+
+func (k *KT) finish() {
+	k.write(k.customization)
+	k.write(lengthEncode(uint64(len(k.customization))))
+
+	if k.tree {
+		if k.leafLen > 0 {
+			k.flushLeaf()
+		}
+		k.finalNode.Write(lengthEncode(k.leaves))
+		k.finalNode.Write([]byte{0xFF, 0xFF})
+		k.finalNode.separator = finalNodeSeparator
+	}
+	k.squeezing = true
+}
+
+// lengthEncode encodes x as its big-endian representation without leading zeros, followed by
+// the length of that representation.
+//
+// specified in 3.3 of [1].
+func lengthEncode(x uint64) []byte {
+	var buf [9]byte
+	n := 0
+	for v := x; v > 0; v >>= 8 {
+		n++
+	}
+	for i := 0; i < n; i++ {
+		buf[i] = byte(x >> (8 * (n - 1 - i)))
+	}
+	buf[n] = byte(n)
+	return buf[:n+1]
+}
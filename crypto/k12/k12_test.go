@@ -0,0 +1,178 @@
+package k12
+
+import (
+	"bytes"
+	"crypto/sha3"
+	"encoding/hex"
+	"runtime"
+	"testing"
+)
+
+// Test vectors from section 5 of RFC 9861. Messages and customization strings are ptn(n) or
+// repetitions of 0xFF.
+
+// ptn returns the n bytes pattern 00 01 .. F9 FA 00 01 ..
+func ptn(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i % 0xFB)
+	}
+	return buf
+}
+
+func TestTurboSHAKEVectors(t *testing.T) {
+	for i, v := range []struct {
+		sum       func(data []byte, length int, separator byte) []byte
+		msg       []byte
+		separator byte
+		length    int
+		// only the last bytes of long outputs are given
+		output string
+	}{
+		{SumTurboSHAKE128, nil, 0x1F, 32, "1E415F1C5983AFF2169217277D17BB538CD945A397DDEC541F1CE41AF2C1B74C"},
+		{SumTurboSHAKE128, nil, 0x07, 64, "5A223AD30B3B8C66A243048CFCED430F54E7529287D15150B973133ADFAC6A2FFE2708E73061E09A4000168BA9C8CA1813198F7BBED4984B4185F2C2580EE623"},
+		{SumTurboSHAKE128, nil, 0x07, 10032, "7593A28020A3C4AE0D605FD61F5EB56ECCD27CC3D12FF09F78369772A460C55D"},
+		{SumTurboSHAKE128, []byte{0xFF}, 0x06, 32, "8EC9C66465ED0D4A6C35D13506718D687A25CB05C74CCA1E42501ABD83874A67"},
+		{SumTurboSHAKE256, nil, 0x1F, 64, "367A329DAFEA871C7802EC67F905AE13C57695DC2C6663C61035F59A18F8E7DB11EDC0E12E91EA60EB6B32DF06DD7F002FBAFABB6E13EC1CC20D995547600DB0"},
+	} {
+		want, _ := hex.DecodeString(v.output)
+		got := v.sum(v.msg, v.length, v.separator)
+		got = got[len(got)-len(want):]
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %X, got %X", i+1, want, got)
+		}
+	}
+}
+
+// TestTurboSHAKEFullRounds checks the sponge against crypto/sha3: with the 24 rounds of
+// Keccak-f[1600] and the 0x1F separator, TurboSHAKE is SHAKE.
+func TestTurboSHAKEFullRounds(t *testing.T) {
+	msg := ptn(1000)
+	for _, n := range []int{0, 1, 135, 136, 137, 167, 168, 169, 1000} {
+		shake128 := newTurboSHAKE(RateTurboSHAKE128, 24, 0x1F)
+		shake128.Write(msg[:n])
+		got := make([]byte, 500)
+		shake128.Read(got)
+		if want := sha3.SumSHAKE128(msg[:n], 500); !bytes.Equal(got, want) {
+			t.Fatalf("SHAKE128(%d): expected %X, got %X", n, want, got)
+		}
+
+		shake256 := newTurboSHAKE(RateTurboSHAKE256, 24, 0x1F)
+		shake256.Write(msg[:n])
+		shake256.Read(got)
+		if want := sha3.SumSHAKE256(msg[:n], 500); !bytes.Equal(got, want) {
+			t.Fatalf("SHAKE256(%d): expected %X, got %X", n, want, got)
+		}
+	}
+}
+
+var kt128Vectors = []struct {
+	msg           []byte
+	customization []byte
+	length        int
+	output        string
+}{
+	{nil, nil, 32, "1AC2D450FC3B4205D19DA7BFCA1B37513C0803577AC7167F06FE2CE1F0EF39E5"},
+	{nil, nil, 10032, "E8DC563642F7228C84684C898405D3A834799158C079B12880277A1D28E2FF6D"},
+	{ptn(17), nil, 32, "6BF75FA2239198DB4772E36478F8E19B0F371205F6A9A93A273F51DF37122888"},
+	{ptn(17 * 17), nil, 32, "0C315EBCDEDBF61426DE7DCF8FB725D1E74675D7F5327A5067F367B108ECB67C"},
+	{ptn(17 * 17 * 17), nil, 32, "CB552E2EC77D9910701D578B457DDF772C12E322E4EE7FE417F92C758F0D59D0"},
+	{ptn(17 * 17 * 17 * 17), nil, 32, "8701045E22205345FF4DDA05555CBB5C3AF1A771C2B89BAEF37DB43D9998B9FE"},
+	{ptn(17 * 17 * 17 * 17 * 17), nil, 32, "844D610933B1B9963CBDEB5AE3B6B05CC7CBD67CEEDF883EB678A0A8E0371682"},
+	{ptn(17 * 17 * 17 * 17 * 17 * 17), nil, 32, "3C390782A8A4E89FA6367F72FEAAF13255C8D95878481D3CD8CE85F58E880AF8"},
+	{nil, ptn(1), 32, "FAB658DB63E94A246188BF7AF69A133045F46EE984C56E3C3328CAAF1AA1A583"},
+	{[]byte{0xFF}, ptn(41), 32, "D848C5068CED736F4462159B9867FD4C20B808ACC3D5BC48E0B06BA0A3762EC4"},
+	{bytes.Repeat([]byte{0xFF}, 3), ptn(41 * 41), 32, "C389E5009AE57120854C2E8C64670AC01358CF4C1BAF89447A724234DC7CED74"},
+	{bytes.Repeat([]byte{0xFF}, 7), ptn(41 * 41 * 41), 32, "75D2F86A2E644566726B4FBCFC5657B9DBCF070C7B0DCA06450AB291D7443BCF"},
+	// inputs and customization strings around the chunk boundary, from section 5 of RFC 9861
+	{ptn(8191), nil, 32, "1B577636F723643E990CC7D6A659837436FD6A103626600EB8301CD1DBE553D6"},
+	{ptn(8192), nil, 32, "48F256F6772F9EDFB6A8B661EC92DC93B95EBD05A08A17B39AE3490870C926C3"},
+	{ptn(8192), ptn(8189), 32, "3ED12F70FB05DDB58689510AB3E4D23C6C6033849AA01E1D8C220A297FEDCD0B"},
+	{ptn(8192), ptn(8190), 32, "6A7C1B6A5CD0D8C9CA943A4A216CC64604559A2EA45F78570A15253D67BA00AE"},
+}
+
+func TestKT128Vectors(t *testing.T) {
+	for i, v := range kt128Vectors {
+		want, _ := hex.DecodeString(v.output)
+		for _, writeSize := range []int{1024, 7919, ChunkSize, 3 * ChunkSize, len(v.msg) + 1} {
+			k := NewKT128(v.customization)
+			for p := v.msg; len(p) > 0; {
+				n := min(writeSize, len(p))
+				k.Write(p[:n])
+				p = p[n:]
+			}
+			got := make([]byte, v.length)
+			k.Read(got)
+			got = got[len(got)-len(want):]
+			if !bytes.Equal(got, want) {
+				t.Fatalf("#%d (write size %d): expected %X, got %X", i+1, writeSize, want, got)
+			}
+		}
+	}
+}
+
+func TestKT256Vectors(t *testing.T) {
+	for i, v := range []struct {
+		msg    []byte
+		output string
+	}{
+		{nil, "B23D2E9CEA9F4904E02BEC06817FC10CE38CE8E93EF4C89E6537076AF8646404E3E8B68107B8833A5D30490AA33482353FD4ADC7148ECB782855003AAEBDE4A9"},
+		{ptn(17), "1BA3C02B1FC514474F06C8979978A9056C8483F4A1B63D0DCCEFE3A28A2F323E1CDCCA40EBF006AC76EF0397152346837B1277D3E7FAA9C9653B19075098527B"},
+	} {
+		want, _ := hex.DecodeString(v.output)
+		if got := SumKT256(v.msg, len(want), nil); !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %X, got %X", i+1, want, got)
+		}
+	}
+}
+
+// TestKTWorkers checks that the output doesn't depend on the number of goroutines hashing the
+// leaves, nor on how the input is split between calls to Write.
+func TestKTWorkers(t *testing.T) {
+	msg := ptn(40*ChunkSize + 123)
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	for _, sum := range []func(data []byte, length int, customization []byte) []byte{SumKT128, SumKT256} {
+		runtime.GOMAXPROCS(1)
+		want := sum(msg, 64, []byte("workers"))
+		for _, procs := range []int{2, 3, 8} {
+			runtime.GOMAXPROCS(procs)
+			if got := sum(msg, 64, []byte("workers")); !bytes.Equal(got, want) {
+				t.Fatalf("GOMAXPROCS=%d: expected %X, got %X", procs, want, got)
+			}
+		}
+	}
+
+	k := NewKT256([]byte("workers"))
+	for p := msg; len(p) > 0; {
+		n := min(5000, len(p))
+		k.Write(p[:n])
+		p = p[n:]
+	}
+	got := make([]byte, 64)
+	k.Read(got)
+	if want := SumKT256(msg, 64, []byte("workers")); !bytes.Equal(got, want) {
+		t.Fatalf("streaming: expected %X, got %X", want, got)
+	}
+}
+
+func TestKTClone(t *testing.T) {
+	msg := ptn(3*ChunkSize + 17)
+
+	k := NewKT128([]byte("clone"))
+	k.Write(msg[:2*ChunkSize+5])
+	clone := k.Clone()
+	clone.Write(msg[2*ChunkSize+5:])
+	k.Write([]byte("other suffix"))
+
+	got := make([]byte, 64)
+	clone.Read(got)
+	if want := SumKT128(msg, 64, []byte("clone")); !bytes.Equal(got, want) {
+		t.Fatalf("clone: expected %X, got %X", want, got)
+	}
+	k.Read(got)
+	other := append(msg[:2*ChunkSize+5:2*ChunkSize+5], "other suffix"...)
+	if want := SumKT128(other, 64, []byte("clone")); !bytes.Equal(got, want) {
+		t.Fatalf("original: expected %X, got %X", want, got)
+	}
+}
@@ -0,0 +1,176 @@
+// Package k12 implements TurboSHAKE128, TurboSHAKE256 and the KangarooTwelve tree hashes KT128
+// and KT256 as specified in RFC 9861 [1].
+//
+// TurboSHAKE is SHAKE with the Keccak permutation reduced from 24 to 12 rounds. KangarooTwelve
+// splits its input into chunks of 8192 bytes whose chaining values are computed independently,
+// which lets large inputs be hashed by several goroutines.
+//
+// [1] https://www.rfc-editor.org/rfc/rfc9861.html
+package k12
+
+import "encoding/binary"
+
+const (
+	// rounds of Keccak-p[1600] used by TurboSHAKE
+	rounds = 12
+
+	// RateTurboSHAKE128 is the block size in bytes of TurboSHAKE128.
+	RateTurboSHAKE128 = 168
+	// RateTurboSHAKE256 is the block size in bytes of TurboSHAKE256.
+	RateTurboSHAKE256 = 136
+
+	// DefaultSeparator is the domain separation byte to use when TurboSHAKE is used as a plain
+	// hash function or XOF.
+	DefaultSeparator = 0x1F
+
+	maxRate = RateTurboSHAKE128
+)
+
+// TurboSHAKE is an instance of TurboSHAKE128 or TurboSHAKE256. Data is absorbed with Write and
+// an output of any length can be squeezed with Read.
+//
+// specified in 2.2 of [1].
+type TurboSHAKE struct {
+	a         [25]uint64
+	buf       [maxRate]byte
+	rate      int
+	rounds    int
+	separator byte
+	// n is the number of bytes buffered when absorbing, and the number of bytes of buf already
+	// read when squeezing
+	n         int
+	squeezing bool
+}
+
+// NewTurboSHAKE128 creates a TurboSHAKE128 instance with the given domain separation byte,
+// which must be in the range 0x01..0x7F.
+func NewTurboSHAKE128(separator byte) *TurboSHAKE {
+	return newTurboSHAKE(RateTurboSHAKE128, rounds, separator)
+}
+
+// NewTurboSHAKE256 creates a TurboSHAKE256 instance with the given domain separation byte,
+// which must be in the range 0x01..0x7F.
+func NewTurboSHAKE256(separator byte) *TurboSHAKE {
+	return newTurboSHAKE(RateTurboSHAKE256, rounds, separator)
+}
+
+func newTurboSHAKE(rate, rounds int, separator byte) *TurboSHAKE {
+	if separator < 0x01 || separator > 0x7F {
+		panic("k12: invalid domain separation byte")
+	}
+	return &TurboSHAKE{
+		rate:      rate,
+		rounds:    rounds,
+		separator: separator,
+	}
+}
+
+// SumTurboSHAKE128 returns length bytes of TurboSHAKE128 of data with the given domain
+// separation byte.
+func SumTurboSHAKE128(data []byte, length int, separator byte) []byte {
+	t := NewTurboSHAKE128(separator)
+	t.Write(data)
+	out := make([]byte, length)
+	t.Read(out)
+	return out
+}
+
+// SumTurboSHAKE256 returns length bytes of TurboSHAKE256 of data with the given domain
+// separation byte.
+func SumTurboSHAKE256(data []byte, length int, separator byte) []byte {
+	t := NewTurboSHAKE256(separator)
+	t.Write(data)
+	out := make([]byte, length)
+	t.Read(out)
+	return out
+}
+
+// BlockSize returns the rate of the sponge.
+func (t *TurboSHAKE) BlockSize() int {
+	return t.rate
+}
+
+// Reset resets the TurboSHAKE to its initial state, keeping its domain separation byte.
+func (t *TurboSHAKE) Reset() {
+	t.a = [25]uint64{}
+	t.n = 0
+	t.squeezing = false
+}
+
+// Clone returns a copy of the TurboSHAKE in its current state.
+func (t *TurboSHAKE) Clone() *TurboSHAKE {
+	clone := *t
+	return &clone
+}
+
+// Write absorbs more data. It panics if output has already been read.
+func (t *TurboSHAKE) Write(p []byte) (int, error) {
+	if t.squeezing {
+		panic("k12: Write after Read")
+	}
+	written := len(p)
+
+	if t.n > 0 {
+		n := copy(t.buf[t.n:t.rate], p)
+		t.n += n
+		p = p[n:]
+		if t.n < t.rate {
+			return written, nil
+		}
+		t.absorb(t.buf[:t.rate])
+		t.n = 0
+	}
+	for len(p) >= t.rate {
+		t.absorb(p[:t.rate])
+		p = p[t.rate:]
+	}
+	t.n = copy(t.buf[:], p)
+
+	return written, nil
+}
+
+// Read squeezes len(p) bytes of output. The first call pads the absorbed data, after which
+// Write can't be called anymore.
+func (t *TurboSHAKE) Read(p []byte) (int, error) {
+	if !t.squeezing {
+		t.pad()
+	}
+	read := len(p)
+
+	for len(p) > 0 {
+		if t.n == t.rate {
+			keccakP1600(&t.a, t.rounds)
+			t.squeezeBlock()
+		}
+		n := copy(p, t.buf[t.n:t.rate])
+		t.n += n
+		p = p[n:]
+	}
+
+	return read, nil
+}
+
+func (t *TurboSHAKE) absorb(block []byte) {
+	for i := 0; i < t.rate/8; i++ {
+		t.a[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+	}
+	keccakP1600(&t.a, t.rounds)
+}
+
+// pad appends the domain separation byte and the final bit of the padding, absorbs the last
+// block and switches to squeezing.
+func (t *TurboSHAKE) pad() {
+	clear(t.buf[t.n:t.rate])
+	t.buf[t.n] = t.separator
+	t.buf[t.rate-1] ^= 0x80
+	t.absorb(t.buf[:t.rate])
+	t.squeezing = true
+	t.squeezeBlock()
+}
+
+func (t *TurboSHAKE) squeezeBlock() {
+	for i := 0; i < t.rate/8; i++ {
+		binary.LittleEndian.PutUint64(t.buf[i*8:], t.a[i])
+	}
+	t.n = 0
+}
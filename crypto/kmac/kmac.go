@@ -3,6 +3,7 @@ package kmac
 import (
 	"encoding/binary"
 	"hash"
+	"io"
 
 	"crypto/sha3"
 )
@@ -38,6 +39,8 @@ type Kmac struct {
 	// initBlock []byte
 }
 
+var _ hash.Hash = (*Kmac)(nil)
+
 // NewKMAC128 creates a new instance of KMAC128 which providing 128 bits of security
 // using the given key, which must have 16 bytes or more, generating the given tagSize
 // bytes output and using the given domainSeparationCustomizationString.
@@ -45,7 +48,7 @@ type Kmac struct {
 // Note that unlike other hash implementations in the standard library,
 // the returned Hash does not implement encoding.BinaryMarshaler
 // or encoding.BinaryUnmarshaler.
-func NewKMAC128(key []byte, outputLen int, domainSeparationCustomizationString []byte) hash.Hash {
+func NewKMAC128(key []byte, outputLen int, domainSeparationCustomizationString []byte) *Kmac {
 	if len(key) < 16 {
 		panic("Key must not be smaller than security strength")
 	}
@@ -75,15 +78,26 @@ func newKMAC(key []byte, outputLen int, c *sha3.SHAKE) *Kmac {
 	}
 
 	k := &Kmac{SHAKE: c, outputLen: outputLen}
-
-	// leftEncode returns max 9 bytes
-	initBlock := make([]byte, 0, 9+len(key))
-	initBlock = append(initBlock, leftEncode(uint64(len(key)*8))...)
-	initBlock = append(initBlock, key...)
-	k.Write(bytepad(initBlock, k.BlockSize()))
+	writeKey(c, key)
 	return k
 }
 
+// writeKey absorbs bytepad(encode_string(key), rate), which is the same for KMAC and KMACXOF
+func writeKey(c *sha3.SHAKE, key []byte) {
+	c.Write(Bytepad(EncodeString(key), c.BlockSize()))
+}
+
+// EncodeString encodes the bit string input as left_encode(len(input)) || input so it can be
+// unambiguously parsed from the beginning of the string.
+//
+// specified in 2.3.2 of [1].
+func EncodeString(input []byte) []byte {
+	// LeftEncode returns max 9 bytes
+	buf := make([]byte, 0, 9+len(input))
+	buf = append(buf, LeftEncode(uint64(len(input)*8))...)
+	return append(buf, input...)
+}
+
 // Reset resets the hash to initial state.
 // func (k *Kmac) Reset() {
 // 	k.SHAKE.Reset()
@@ -104,48 +118,130 @@ func (k *Kmac) Size() int {
 func (k *Kmac) Sum(b []byte) []byte {
 
 	// right_encode(outputLen)
-	k.Write(rightEncode(uint64(k.outputLen * 8)))
+	k.Write(RightEncode(uint64(k.outputLen * 8)))
 	hash := make([]byte, k.outputLen)
 
 	k.Read(hash)
 	return append(b, hash...)
 }
 
-// // Clone returns copy of a KMAC context within its current state.
-// func (k *kmac) Clone() sha3.ShakeHash {
-// 	b := make([]byte, len(k.initBlock))
-// 	copy(b, k.initBlock)
+// KmacXOF is a KMACXOF128 or KMACXOF256 context. Unlike KMAC, the output length is not
+// bound to the input, so an arbitrary amount of output can be read from it.
+type KmacXOF struct {
+	shake     *sha3.SHAKE
+	squeezing bool
+}
 
-// 	return &kmac{
-// 		ShakeHash: k.ShakeHash.Clone(),
-// 		outputLen: k.outputLen,
-// 		initBlock: b,
-// 	}
-// }
+var _ io.ReadWriter = (*KmacXOF)(nil)
+
+// NewKMACXOF128 creates a new instance of KMACXOF128 which providing 128 bits of security
+// using the given key, which must have 16 bytes or more, and the given
+// domainSeparationCustomizationString.
+func NewKMACXOF128(key []byte, domainSeparationCustomizationString []byte) *KmacXOF {
+	if len(key) < 16 {
+		panic("Key must not be smaller than security strength")
+	}
+
+	c := sha3.NewCSHAKE128([]byte(functionName), domainSeparationCustomizationString)
+	writeKey(c, key)
+	return &KmacXOF{shake: c}
+}
+
+// NewKMACXOF256 creates a new instance of KMACXOF256 which providing 256 bits of security
+// using the given key, which must have 32 bytes or more, and the given
+// domainSeparationCustomizationString.
+func NewKMACXOF256(key []byte, domainSeparationCustomizationString []byte) *KmacXOF {
+	if len(key) < 32 {
+		panic("Key must not be smaller than security strength")
+	}
+
+	c := sha3.NewCSHAKE256([]byte(functionName), domainSeparationCustomizationString)
+	writeKey(c, key)
+	return &KmacXOF{shake: c}
+}
+
+// Write absorbs more data. It panics if output has already been read.
+func (k *KmacXOF) Write(p []byte) (int, error) {
+	if k.squeezing {
+		panic("kmac: Write after Read")
+	}
+	return k.shake.Write(p)
+}
+
+// Read squeezes output. The first call appends right_encode(0), which marks an arbitrary
+// length output.
+//
+// specified in 4.3.1 of [1].
+func (k *KmacXOF) Read(p []byte) (int, error) {
+	if !k.squeezing {
+		k.shake.Write(RightEncode(0))
+		k.squeezing = true
+	}
+	return k.shake.Read(p)
+}
+
+// BlockSize returns the rate of the underlying cSHAKE.
+func (k *KmacXOF) BlockSize() int {
+	return k.shake.BlockSize()
+}
+
+// Clone returns a copy of the KMAC context in its current state, e.g. to compute the KMAC of
+// several inputs with the same key without absorbing the key again.
+func (k *Kmac) Clone() *Kmac {
+	return &Kmac{SHAKE: cloneSHAKE(k.SHAKE), outputLen: k.outputLen}
+}
+
+// Clone returns a copy of the KMACXOF context in its current state.
+func (k *KmacXOF) Clone() *KmacXOF {
+	return &KmacXOF{shake: cloneSHAKE(k.shake), squeezing: k.squeezing}
+}
+
+// cloneSHAKE copies the state of a cSHAKE through its binary marshaling, as crypto/sha3 doesn't
+// provide a Clone method. The state can only be unmarshaled into a cSHAKE of the same rate, so
+// the rate selects the constructor: 168 bytes for cSHAKE128 and 136 bytes for cSHAKE256.
+func cloneSHAKE(s *sha3.SHAKE) *sha3.SHAKE {
+	state, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	var clone *sha3.SHAKE
+	switch s.BlockSize() {
+	case 168:
+		clone = sha3.NewCSHAKE128([]byte(functionName), nil)
+	case 136:
+		clone = sha3.NewCSHAKE256([]byte(functionName), nil)
+	default:
+		panic("kmac: unexpected cSHAKE rate")
+	}
+	if err := clone.UnmarshalBinary(state); err != nil {
+		panic(err)
+	}
+	return clone
+}
 
-// bytepad prepends an encoding of the integer w to an input string X, then pads
+// Bytepad prepends an encoding of the integer w to an input string X, then pads
 // the result with zeros until it is a byte string whose length in bytes is a multiple of w
 //
 // specified in 2.3.3 of [1].
 //
 // copied from golang.org/x/crypto/sha3/shake.go
-func bytepad(input []byte, w int) []byte {
-	// leftEncode always returns max 9 bytes
+func Bytepad(input []byte, w int) []byte {
+	// LeftEncode always returns max 9 bytes
 	buf := make([]byte, 0, 9+len(input)+w)
-	buf = append(buf, leftEncode(uint64(w))...)
+	buf = append(buf, LeftEncode(uint64(w))...)
 	buf = append(buf, input...)
 	padlen := (len(buf)+w-1)/w*w - len(buf)
 	return append(buf, make([]byte, padlen)...)
 }
 
-// leftEncode encodes the integer x as a byte string in a way that can be unambiguously parsed
+// LeftEncode encodes the integer x as a byte string in a way that can be unambiguously parsed
 // from the beginning of the string by inserting the length of the byte string before the byte string
 // representation of x.
 //
 // specified in 2.3.1 of [1].
 //
 // copied from golang.org/x/crypto/sha3/shake.go
-func leftEncode(value uint64) []byte {
+func LeftEncode(value uint64) []byte {
 	var b [9]byte
 	binary.BigEndian.PutUint64(b[1:], value)
 	// Trim all but last leading zero bytes
@@ -158,12 +254,12 @@ func leftEncode(value uint64) []byte {
 	return b[i-1:]
 }
 
-// rightEncode encodes the integer x as a byte string in a way that can be
+// RightEncode encodes the integer x as a byte string in a way that can be
 // unambiguously parsed from the end of the string by inserting the length
 // of the byte string after the byte string representation of x
 //
 // specified in 2.3.1 of [1].
-func rightEncode(value uint64) []byte {
+func RightEncode(value uint64) []byte {
 	var b [9]byte
 	binary.BigEndian.PutUint64(b[:8], value)
 	// Trim all but last leading zero bytes
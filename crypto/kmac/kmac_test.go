@@ -0,0 +1,139 @@
+package kmac
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Samples from https://csrc.nist.gov/projects/cryptographic-standards-and-guidelines/example-values
+// (KMAC_samples.pdf and KMACXOF_samples.pdf). All of them use the key 0x40..0x5F and either
+// the data 0x00..0x03 or 0x00..0xC7.
+var kmacSamples = []struct {
+	name          string
+	xof           bool
+	security      int
+	dataLen       int
+	customization string
+	output        string
+}{
+	{"KMAC128 #1", false, 128, 4, "", "E5780B0D3EA6F7D3A429C5706AA43A00FADBD7D49628839E3187243F456EE14E"},
+	{"KMAC128 #2", false, 128, 4, "My Tagged Application", "3B1FBA963CD8B0B59E8C1A6D71888B7143651AF8BA0A7070C0979E2811324AA5"},
+	{"KMAC128 #3", false, 128, 200, "My Tagged Application", "1F5B4E6CCA02209E0DCB5CA635B89A15E271ECC760071DFD805FAA38F9729230"},
+	{"KMAC256 #4", false, 256, 4, "My Tagged Application", "20C570C31346F703C9AC36C61C03CB64C3970D0CFC787E9B79599D273A68D2F7F69D4CC3DE9D104A351689F27CF6F5951F0103F33F4F24871024D9C27773A8DD"},
+	{"KMAC256 #5", false, 256, 200, "", "75358CF39E41494E949707927CEE0AF20A3FF553904C86B08F21CC414BCFD691589D27CF5E15369CBBFF8B9A4C2EB17800855D0235FF635DA82533EC6B759B69"},
+	{"KMAC256 #6", false, 256, 200, "My Tagged Application", "B58618F71F92E1D56C1B8C55DDD7CD188B97B4CA4D99831EB2699A837DA2E4D970FBACFDE50033AEA585F1A2708510C32D07880801BD182898FE476876FC8965"},
+	{"KMACXOF128 #1", true, 128, 4, "", "CD83740BBD92CCC8CF032B1481A0F4460E7CA9DD12B08A0C4031178BACD6EC35"},
+	{"KMACXOF128 #2", true, 128, 4, "My Tagged Application", "31A44527B4ED9F5C6101D11DE6D26F0620AA5C341DEF41299657FE9DF1A3B16C"},
+	{"KMACXOF128 #3", true, 128, 200, "My Tagged Application", "47026C7CD793084AA0283C253EF658490C0DB61438B8326FE9BDDF281B83AE0F"},
+	{"KMACXOF256 #4", true, 256, 4, "My Tagged Application", "1755133F1534752AAD0748F2C706FB5C784512CAB835CD15676B16C0C6647FA96FAA7AF634A0BF8FF6DF39374FA00FAD9A39E322A7C92065A64EB1FB0801EB2B"},
+	{"KMACXOF256 #5", true, 256, 200, "", "FF7B171F1E8A2B24683EED37830EE797538BA8DC563F6DA1E667391A75EDC02CA633079F81CE12A25F45615EC89972031D18337331D24CEB8F8CA8E6A19FD98B"},
+	{"KMACXOF256 #6", true, 256, 200, "My Tagged Application", "D5BE731C954ED7732846BB59DBE3A8E30F83E77A4BFF4459F2F1C2B4ECEBB8CE67BA01C62E8AB8578D2D499BD1BB276768781190020A306A97DE281DCC30305D"},
+}
+
+func TestKMACSamples(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(0x40 + i)
+	}
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	for _, sample := range kmacSamples {
+		t.Run(strings.ReplaceAll(sample.name, " ", "_"), func(t *testing.T) {
+			want, _ := hex.DecodeString(sample.output)
+			customization := []byte(sample.customization)
+			got := make([]byte, len(want))
+
+			switch {
+			case sample.xof && sample.security == 128:
+				xof := NewKMACXOF128(key, customization)
+				xof.Write(data[:sample.dataLen])
+				xof.Read(got)
+			case sample.xof:
+				xof := NewKMACXOF256(key, customization)
+				xof.Write(data[:sample.dataLen])
+				xof.Read(got)
+			case sample.security == 128:
+				mac := NewKMAC128(key, len(want), customization)
+				mac.Write(data[:sample.dataLen])
+				got = mac.Sum(nil)
+			default:
+				mac := NewKMAC256(key, len(want), customization)
+				mac.Write(data[:sample.dataLen])
+				got = mac.Sum(nil)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("expected %X, got %X", want, got)
+			}
+		})
+	}
+}
+
+// TestKMACXOFStreaming checks that reading the output in several calls gives the same stream,
+// and that KMACXOF is not a prefix of KMAC (the output length is bound in KMAC).
+func TestKMACXOFStreaming(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	oneShot := make([]byte, 1024)
+	xof := NewKMACXOF256(key, nil)
+	xof.Write([]byte("input"))
+	xof.Read(oneShot)
+
+	streamed := make([]byte, 0, 1024)
+	xof = NewKMACXOF256(key, nil)
+	xof.Write([]byte("in"))
+	xof.Write([]byte("put"))
+	for len(streamed) < 1024 {
+		chunk := make([]byte, min(37, 1024-len(streamed)))
+		xof.Read(chunk)
+		streamed = append(streamed, chunk...)
+	}
+	if !bytes.Equal(oneShot, streamed) {
+		t.Fatal("streamed output differs from the one-shot output")
+	}
+
+	mac := NewKMAC256(key, 64, nil)
+	mac.Write([]byte("input"))
+	if bytes.Equal(mac.Sum(nil), oneShot[:64]) {
+		t.Fatal("KMACXOF256 output should differ from KMAC256")
+	}
+}
+
+// TestClone checks that a clone continues from the state of the original and that the two are
+// independent.
+func TestClone(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	mac := NewKMAC256(key, 32, []byte("clone"))
+	mac.Write([]byte("prefix"))
+	clone := mac.Clone()
+	clone.Write([]byte(" and suffix"))
+	mac.Write([]byte(" and other suffix"))
+
+	want := NewKMAC256(key, 32, []byte("clone"))
+	want.Write([]byte("prefix and suffix"))
+	if got := clone.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Fatal("KMAC clone differs from a KMAC of the same input")
+	}
+	other := NewKMAC256(key, 32, []byte("clone"))
+	other.Write([]byte("prefix and other suffix"))
+	if !bytes.Equal(mac.Sum(nil), other.Sum(nil)) {
+		t.Fatal("KMAC was modified by its clone")
+	}
+
+	xof := NewKMACXOF128(key, []byte("clone"))
+	xof.Write([]byte("input"))
+	xofClone := xof.Clone()
+	got := make([]byte, 100)
+	xofClone.Read(got)
+	expected := make([]byte, 100)
+	xof.Read(expected)
+	if !bytes.Equal(got, expected) {
+		t.Fatal("KMACXOF clone differs from the original")
+	}
+}
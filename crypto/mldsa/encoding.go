@@ -0,0 +1,103 @@
+package mldsa
+
+// appendBits appends the low bits of each of values, least significant bit first, which is
+// SimpleBitPack of FIPS 204, Algorithm 16, when the values fit in bits bits.
+func appendBits(out []byte, values *[n]uint32, bits int) []byte {
+	var acc uint64
+	accBits := 0
+	for _, value := range values {
+		acc |= uint64(value) << accBits
+		accBits += bits
+		for accBits >= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+			accBits -= 8
+		}
+	}
+	return out
+}
+
+// readBits is the inverse of appendBits, SimpleBitUnpack of FIPS 204, Algorithm 18. in must be
+// n × bits / 8 bytes long.
+func readBits(in []byte, bits int) *[n]uint32 {
+	var values [n]uint32
+	var acc uint64
+	accBits := 0
+	mask := uint64(1)<<bits - 1
+	for i := range values {
+		for accBits < bits {
+			acc |= uint64(in[0]) << accBits
+			in = in[1:]
+			accBits += 8
+		}
+		values[i] = uint32(acc & mask)
+		acc >>= bits
+		accBits -= bits
+	}
+	return &values
+}
+
+// appendSigned is BitPack of FIPS 204, Algorithm 17, for the coefficients of f in [-a, b] which
+// are encoded as b - f_i on bits bits.
+func appendSigned(out []byte, f *ringElement, b uint32, bits int) []byte {
+	var values [n]uint32
+	for i, c := range f {
+		values[i] = uint32(fieldSub(fieldElement(b), c))
+	}
+	return appendBits(out, &values, bits)
+}
+
+// readSigned is BitUnpack of FIPS 204, Algorithm 19, the inverse of appendSigned. It returns false
+// if a coefficient is above b, which happens for invalid encodings when 2^bits - 1 > a + b.
+func readSigned(in []byte, a, b uint32, bits int) (ringElement, bool) {
+	var f ringElement
+	for i, value := range readBits(in, bits) {
+		if value > a+b {
+			return f, false
+		}
+		f[i] = fieldSub(fieldElement(b), fieldElement(value))
+	}
+	return f, true
+}
+
+// appendHint is HintBitPack of FIPS 204, Algorithm 20: the indexes of the hints of each
+// polynomial, followed by the number of hints up to each polynomial.
+func appendHint(out []byte, hint [][n]bool, omega int) []byte {
+	encoded := make([]byte, omega+len(hint))
+	index := 0
+	for i := range hint {
+		for j, h := range hint[i] {
+			if h {
+				encoded[index] = byte(j)
+				index++
+			}
+		}
+		encoded[omega+i] = byte(index)
+	}
+	return append(out, encoded...)
+}
+
+// readHint is HintBitUnpack of FIPS 204, Algorithm 21. It returns false for malformed hints,
+// including the non-canonical encodings.
+func readHint(in []byte, hint [][n]bool, omega int) bool {
+	index := 0
+	for i := range hint {
+		limit := int(in[omega+i])
+		if limit < index || limit > omega {
+			return false
+		}
+		first := index
+		for ; index < limit; index++ {
+			if index > first && in[index-1] >= in[index] {
+				return false
+			}
+			hint[i][in[index]] = true
+		}
+	}
+	for _, b := range in[index:omega] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package mldsa
+
+const (
+	q = 8380417
+	n = 256
+	d = 13
+
+	// qNegInv is -q⁻¹ mod 2³², for the Montgomery reduction.
+	qNegInv = 4236238847
+)
+
+// A fieldElement is an integer modulo q, always reduced to [0, q).
+type fieldElement uint32
+
+// ringElement is a polynomial of Z_q[X]/(X^256+1), as its coefficients, and nttElement the same
+// polynomial in the NTT domain.
+type (
+	ringElement [n]fieldElement
+	nttElement  [n]fieldElement
+)
+
+var (
+	// zetas are ζ^BitRev₈(i) in the Montgomery domain, ζ = 1753 being a 512th root of unity.
+	zetas [n]fieldElement
+	// inverseNTTScale is 256⁻¹ with two Montgomery factors, to cancel the one lost by the
+	// multiplication of two NTT elements.
+	inverseNTTScale fieldElement
+)
+
+func init() {
+	r := uint64(1<<32) % q
+	for i := range n {
+		rev := 0
+		for bit := range 8 {
+			rev |= (i >> bit & 1) << (7 - bit)
+		}
+		zetas[i] = fieldElement(powMod(1753, uint64(rev)) * r % q)
+	}
+	inverseNTTScale = fieldElement(powMod(n, q-2) * r % q * r % q)
+}
+
+func powMod(base, exponent uint64) uint64 {
+	result := uint64(1)
+	for ; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			result = result * base % q
+		}
+		base = base * base % q
+	}
+	return result
+}
+
+// fieldReduceOnce reduces a value in [0, 2q) to [0, q) in constant time.
+func fieldReduceOnce(a uint32) fieldElement {
+	x := a - q
+	x += q & uint32(int32(x)>>31)
+	return fieldElement(x)
+}
+
+func fieldAdd(a, b fieldElement) fieldElement {
+	return fieldReduceOnce(uint32(a + b))
+}
+
+func fieldSub(a, b fieldElement) fieldElement {
+	return fieldReduceOnce(uint32(a - b + q))
+}
+
+// fieldMontgomeryMul returns a × b × 2⁻³² mod q.
+func fieldMontgomeryMul(a, b fieldElement) fieldElement {
+	x := uint64(a) * uint64(b)
+	t := uint32(x) * qNegInv
+	return fieldReduceOnce(uint32((x + uint64(t)*q) >> 32))
+}
+
+// fieldInfinityNorm returns |a mod± q|, in constant time.
+func fieldInfinityNorm(a fieldElement) uint32 {
+	negative := uint32(int32((q-1)/2-uint32(a)) >> 31)
+	return uint32(a)&^negative | (q-uint32(a))&negative
+}
+
+// fieldFromSigned returns a mod q, for |a| < q.
+func fieldFromSigned(a int32) fieldElement {
+	return fieldReduceOnce(uint32(a + q))
+}
+
+// ntt is NTT of FIPS 204, Algorithm 41.
+func ntt(f ringElement) nttElement {
+	m := 0
+	for length := 128; length >= 1; length /= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m++
+			zeta := zetas[m]
+			for j := start; j < start+length; j++ {
+				t := fieldMontgomeryMul(zeta, f[j+length])
+				f[j+length] = fieldSub(f[j], t)
+				f[j] = fieldAdd(f[j], t)
+			}
+		}
+	}
+	return nttElement(f)
+}
+
+// inverseNTT is NTT⁻¹ of FIPS 204, Algorithm 42. It expects a sum of products of nttMul, which
+// lack a Montgomery factor compensated by inverseNTTScale.
+func inverseNTT(f nttElement) ringElement {
+	m := n
+	for length := 1; length < n; length *= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m--
+			zeta := q - zetas[m]
+			for j := start; j < start+length; j++ {
+				t := f[j]
+				f[j] = fieldAdd(t, f[j+length])
+				f[j+length] = fieldMontgomeryMul(zeta, fieldSub(t, f[j+length]))
+			}
+		}
+	}
+	for i := range f {
+		f[i] = fieldMontgomeryMul(inverseNTTScale, f[i])
+	}
+	return ringElement(f)
+}
+
+// nttMulAcc adds a × b, missing a Montgomery factor, to acc.
+func nttMulAcc(acc *nttElement, a, b *nttElement) {
+	for i := range acc {
+		acc[i] = fieldAdd(acc[i], fieldMontgomeryMul(a[i], b[i]))
+	}
+}
+
+// nttMul returns a × b, missing a Montgomery factor.
+func nttMul(a, b *nttElement) nttElement {
+	var product nttElement
+	for i := range product {
+		product[i] = fieldMontgomeryMul(a[i], b[i])
+	}
+	return product
+}
+
+func ringAdd(a, b *ringElement) ringElement {
+	var sum ringElement
+	for i := range sum {
+		sum[i] = fieldAdd(a[i], b[i])
+	}
+	return sum
+}
+
+func ringSub(a, b *ringElement) ringElement {
+	var difference ringElement
+	for i := range difference {
+		difference[i] = fieldSub(a[i], b[i])
+	}
+	return difference
+}
+
+func nttSub(a, b *nttElement) nttElement {
+	var difference nttElement
+	for i := range difference {
+		difference[i] = fieldSub(a[i], b[i])
+	}
+	return difference
+}
+
+// ringInfinityNorm returns the infinity norm of f, the maximum of |f_i mod± q|.
+func ringInfinityNorm(f *ringElement) uint32 {
+	var norm uint32
+	for _, c := range f {
+		norm = max(norm, fieldInfinityNorm(c))
+	}
+	return norm
+}
+
+// power2Round is Power2Round of FIPS 204, Algorithm 35: it splits r into r1 × 2ᵈ + r0 with r0 in
+// (-2ᵈ⁻¹, 2ᵈ⁻¹].
+func power2Round(r fieldElement) (r1 uint32, r0 fieldElement) {
+	r1 = (uint32(r) + 1<<(d-1) - 1) >> d
+	return r1, fieldFromSigned(int32(r) - int32(r1<<d))
+}
+
+// decompose is Decompose of FIPS 204, Algorithm 36, in constant time: it splits r into
+// r1 × 2γ₂ + r0 with r0 in (-γ₂, γ₂], except for the values close to q - 1 for which r1 is 0
+// and r0 is negative.
+func decompose(r fieldElement, gamma2 uint32) (r1 uint32, r0 int32) {
+	r1 = (uint32(r) + 127) >> 7
+	if gamma2 == (q-1)/32 {
+		r1 = (r1*1025 + 1<<21) >> 22
+		r1 &= 15
+	} else {
+		r1 = (r1*11275 + 1<<23) >> 24
+		r1 ^= uint32(int32(43-r1)>>31) & r1
+	}
+	r0 = int32(r) - int32(r1*2*gamma2)
+	r0 -= ((q-1)/2 - r0) >> 31 & q
+	return r1, r0
+}
+
+// useHint is UseHint of FIPS 204, Algorithm 40.
+func useHint(hint bool, r fieldElement, gamma2 uint32) uint32 {
+	r1, r0 := decompose(r, gamma2)
+	if !hint {
+		return r1
+	}
+	if gamma2 == (q-1)/32 {
+		if r0 > 0 {
+			return (r1 + 1) & 15
+		}
+		return (r1 - 1) & 15
+	}
+	if r0 > 0 {
+		if r1 == 43 {
+			return 0
+		}
+		return r1 + 1
+	}
+	if r1 == 0 {
+		return 43
+	}
+	return r1 - 1
+}
@@ -0,0 +1,636 @@
+// Package mldsa implements ML-DSA, the Module-Lattice-Based Digital Signature Algorithm of
+// FIPS 204 [1], with the ML-DSA-44, ML-DSA-65 and ML-DSA-87 parameter sets.
+//
+// Private keys are stored as their 32-byte seed, from which the expanded private key of FIPS 204
+// is derived. Signatures are hedged by default, mixing fresh randomness into the per-signature
+// secret, and can also be deterministic.
+//
+// The implementation follows the pseudocode of FIPS 204, in the Montgomery domain for the
+// multiplications in Z_q. crypto/mldsa of the standard library implements the same algorithm and
+// is used by the tests to check interoperability.
+//
+// [1] https://nvlpubs.nist.gov/nistpubs/FIPS/NIST.FIPS.204.pdf
+package mldsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha3"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// SeedSize is the size of a private key seed.
+	SeedSize = 32
+	// MaxContextSize is the maximum size of a context string.
+	MaxContextSize = 255
+
+	maxK, maxL = 8, 7
+)
+
+// Parameters are an ML-DSA parameter set.
+type Parameters struct {
+	name string
+	// the matrix A is k × l
+	k, l int
+	// eta bounds the coefficients of the secret vectors
+	eta uint32
+	// gamma1 = 2^gamma1Bits bounds the coefficients of the masking vector
+	gamma1Bits int
+	// gamma2 is the low-order rounding range
+	gamma2 uint32
+	// lambda is the collision strength of the commitment hash
+	lambda int
+	// tau is the number of ±1 coefficients of the challenge
+	tau int
+	// omega is the maximum number of hints
+	omega int
+}
+
+var (
+	// MLDSA44 is ML-DSA-44, which targets NIST security category 2.
+	MLDSA44 = &Parameters{name: "ML-DSA-44", k: 4, l: 4, eta: 2, gamma1Bits: 17, gamma2: (q - 1) / 88, lambda: 128, tau: 39, omega: 80}
+	// MLDSA65 is ML-DSA-65, which targets NIST security category 3.
+	MLDSA65 = &Parameters{name: "ML-DSA-65", k: 6, l: 5, eta: 4, gamma1Bits: 19, gamma2: (q - 1) / 32, lambda: 192, tau: 49, omega: 55}
+	// MLDSA87 is ML-DSA-87, which targets NIST security category 5.
+	MLDSA87 = &Parameters{name: "ML-DSA-87", k: 8, l: 7, eta: 2, gamma1Bits: 19, gamma2: (q - 1) / 32, lambda: 256, tau: 60, omega: 75}
+)
+
+// String returns the name of the parameter set, e.g. ML-DSA-44.
+func (params *Parameters) String() string {
+	return params.name
+}
+
+// PublicKeySize returns the size of the encoded public keys: ρ and t1 on 10 bits per coefficient.
+func (params *Parameters) PublicKeySize() int {
+	return 32 + params.k*n*10/8
+}
+
+// SignatureSize returns the size of the signatures: the commitment hash, z and the hints.
+func (params *Parameters) SignatureSize() int {
+	return params.lambda/4 + params.l*n*(params.gamma1Bits+1)/8 + params.omega + params.k
+}
+
+// ExpandedPrivateKeySize returns the size of the expanded private keys of FIPS 204.
+func (params *Parameters) ExpandedPrivateKeySize() int {
+	return 32 + 32 + 64 + (params.l+params.k)*n*params.etaBits()/8 + params.k*n*d/8
+}
+
+func (params *Parameters) beta() uint32 {
+	return uint32(params.tau) * params.eta
+}
+
+func (params *Parameters) etaBits() int {
+	if params.eta == 2 {
+		return 3
+	}
+	return 4
+}
+
+// w1Bits is the size of the coefficients of w1, whose maximum is (q - 1) / (2γ₂) - 1.
+func (params *Parameters) w1Bits() int {
+	if params.gamma2 == (q-1)/88 {
+		return 6
+	}
+	return 4
+}
+
+// A PublicKey is an ML-DSA public key, with the matrix A expanded.
+type PublicKey struct {
+	params  *Parameters
+	encoded []byte
+	// tr is the hash of the encoded public key
+	tr [64]byte
+	// a is the matrix Â, row by row
+	a [maxK * maxL]nttElement
+	// t1 is NTT(t1 × 2ᵈ)
+	t1 [maxK]nttElement
+}
+
+// A PrivateKey is an ML-DSA private key, with its secret vectors in the NTT domain.
+type PrivateKey struct {
+	seed [SeedSize]byte
+	pk   *PublicKey
+	key  [32]byte
+	s1   [maxL]nttElement
+	s2   [maxK]nttElement
+	t0   [maxK]nttElement
+}
+
+// GenerateKey generates a new private key, drawing random bytes from crypto/rand.
+func GenerateKey(params *Parameters) (*PrivateKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(params, seed)
+}
+
+// NewPrivateKey deterministically derives a private key from a 32-byte seed, which is
+// ML-DSA.KeyGen_internal of FIPS 204, Algorithm 6.
+func NewPrivateKey(params *Parameters, seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("mldsa: invalid seed length")
+	}
+	sk, _ := newPrivateKey(params, seed, false)
+	return sk, nil
+}
+
+// newPrivateKey derives the private key of seed and, if expanded is true, its expanded encoding.
+func newPrivateKey(params *Parameters, seed []byte, expanded bool) (*PrivateKey, []byte) {
+	h := sha3.NewSHAKE256()
+	h.Write(seed)
+	h.Write([]byte{byte(params.k), byte(params.l)})
+	var rho [32]byte
+	var rhoPrime [64]byte
+	sk := &PrivateKey{pk: &PublicKey{params: params}}
+	h.Read(rho[:])
+	h.Read(rhoPrime[:])
+	h.Read(sk.key[:])
+	copy(sk.seed[:], seed)
+
+	k, l := params.k, params.l
+	sk.pk.expandA(rho[:])
+
+	var s1 [maxL]ringElement
+	var s2, t0 [maxK]ringElement
+	for r := range l {
+		s1[r] = rejBoundedPoly(rhoPrime[:], uint16(r), params.eta)
+		sk.s1[r] = ntt(s1[r])
+	}
+	for r := range k {
+		s2[r] = rejBoundedPoly(rhoPrime[:], uint16(l+r), params.eta)
+		sk.s2[r] = ntt(s2[r])
+	}
+
+	encoded := make([]byte, 0, params.PublicKeySize())
+	encoded = append(encoded, rho[:]...)
+	for i := range k {
+		var acc nttElement
+		for j := range l {
+			nttMulAcc(&acc, &sk.pk.a[i*l+j], &sk.s1[j])
+		}
+		as1 := inverseNTT(acc)
+		t := ringAdd(&as1, &s2[i])
+
+		var t1 [n]uint32
+		var t1Shifted ringElement
+		for j, c := range t {
+			t1[j], t0[i][j] = power2Round(c)
+			t1Shifted[j] = fieldElement(t1[j] << d)
+		}
+		encoded = appendBits(encoded, &t1, 10)
+		sk.pk.t1[i] = ntt(t1Shifted)
+		sk.t0[i] = ntt(t0[i])
+	}
+	sk.pk.encoded = encoded
+	shake256(sk.pk.tr[:], encoded)
+
+	if !expanded {
+		return sk, nil
+	}
+	out := make([]byte, 0, params.ExpandedPrivateKeySize())
+	out = append(out, rho[:]...)
+	out = append(out, sk.key[:]...)
+	out = append(out, sk.pk.tr[:]...)
+	for r := range l {
+		out = appendSigned(out, &s1[r], params.eta, params.etaBits())
+	}
+	for r := range k {
+		out = appendSigned(out, &s2[r], params.eta, params.etaBits())
+	}
+	for r := range k {
+		out = appendSigned(out, &t0[r], 1<<(d-1), d)
+	}
+	return sk, out
+}
+
+// newPrivateKeyFromExpanded decodes an expanded private key, skDecode of FIPS 204, Algorithm 25.
+// The seed of the returned key is unknown and Bytes must not be called. It is only used by the
+// known-answer tests.
+func newPrivateKeyFromExpanded(params *Parameters, expanded []byte) (*PrivateKey, error) {
+	if len(expanded) != params.ExpandedPrivateKeySize() {
+		return nil, errors.New("mldsa: invalid expanded private key length")
+	}
+	k, l := params.k, params.l
+	sk := &PrivateKey{pk: &PublicKey{params: params}}
+	rho := expanded[:32]
+	copy(sk.key[:], expanded[32:64])
+	copy(sk.pk.tr[:], expanded[64:128])
+	rest := expanded[128:]
+	sk.pk.expandA(rho)
+
+	etaSize := n * params.etaBits() / 8
+	var s2 [maxK]ringElement
+	for r := range l + k {
+		s, ok := readSigned(rest[:etaSize], params.eta, params.eta, params.etaBits())
+		if !ok {
+			return nil, errors.New("mldsa: invalid expanded private key")
+		}
+		if r < l {
+			sk.s1[r] = ntt(s)
+		} else {
+			s2[r-l] = s
+			sk.s2[r-l] = ntt(s)
+		}
+		rest = rest[etaSize:]
+	}
+
+	encoded := append(make([]byte, 0, params.PublicKeySize()), rho...)
+	for i := range k {
+		t0, _ := readSigned(rest[:n*d/8], 1<<(d-1)-1, 1<<(d-1), d)
+		sk.t0[i] = ntt(t0)
+		rest = rest[n*d/8:]
+
+		var acc nttElement
+		for j := range l {
+			nttMulAcc(&acc, &sk.pk.a[i*l+j], &sk.s1[j])
+		}
+		as1 := inverseNTT(acc)
+		t := ringAdd(&as1, &s2[i])
+		var t1 [n]uint32
+		var t1Shifted ringElement
+		for j, c := range t {
+			t1[j], _ = power2Round(c)
+			t1Shifted[j] = fieldElement(t1[j] << d)
+		}
+		encoded = appendBits(encoded, &t1, 10)
+		sk.pk.t1[i] = ntt(t1Shifted)
+	}
+	sk.pk.encoded = encoded
+	return sk, nil
+}
+
+// NewPublicKey decodes an encoded public key, pkDecode of FIPS 204, Algorithm 23.
+func NewPublicKey(params *Parameters, publicKey []byte) (*PublicKey, error) {
+	if len(publicKey) != params.PublicKeySize() {
+		return nil, errors.New("mldsa: invalid public key length")
+	}
+	pk := &PublicKey{params: params, encoded: bytes.Clone(publicKey)}
+	pk.expandA(publicKey[:32])
+	shake256(pk.tr[:], publicKey)
+
+	rest := publicKey[32:]
+	for i := range params.k {
+		var t1Shifted ringElement
+		for j, c := range readBits(rest[:n*10/8], 10) {
+			t1Shifted[j] = fieldElement(c << d)
+		}
+		pk.t1[i] = ntt(t1Shifted)
+		rest = rest[n*10/8:]
+	}
+	return pk, nil
+}
+
+// Bytes returns the private key seed.
+func (sk *PrivateKey) Bytes() []byte {
+	return bytes.Clone(sk.seed[:])
+}
+
+// ExpandedBytes returns the expanded encoding of the private key of FIPS 204, for the
+// implementations which don't store private keys as seeds.
+func (sk *PrivateKey) ExpandedBytes() []byte {
+	_, expanded := newPrivateKey(sk.pk.params, sk.seed[:], true)
+	return expanded
+}
+
+// PublicKey returns the public key of the private key.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return sk.pk
+}
+
+// Bytes returns the encoded public key.
+func (pk *PublicKey) Bytes() []byte {
+	return bytes.Clone(pk.encoded)
+}
+
+// Parameters returns the parameter set of the public key.
+func (pk *PublicKey) Parameters() *Parameters {
+	return pk.params
+}
+
+// expandA is ExpandA of FIPS 204, Algorithm 32.
+func (pk *PublicKey) expandA(rho []byte) {
+	k, l := pk.params.k, pk.params.l
+	for r := range k {
+		for s := range l {
+			pk.a[r*l+s] = rejNTTPoly(rho, byte(s), byte(r))
+		}
+	}
+}
+
+// rejNTTPoly is RejNTTPoly of FIPS 204, Algorithm 30, which samples a uniform polynomial in the
+// NTT domain.
+func rejNTTPoly(rho []byte, s, r byte) nttElement {
+	h := sha3.NewSHAKE128()
+	h.Write(rho)
+	h.Write([]byte{s, r})
+
+	var a nttElement
+	var buf [168]byte
+	j := 0
+	for j < n {
+		h.Read(buf[:])
+		for i := 0; i < len(buf) && j < n; i += 3 {
+			c := uint32(buf[i]) | uint32(buf[i+1])<<8 | uint32(buf[i+2]&0x7f)<<16
+			if c < q {
+				a[j] = fieldElement(c)
+				j++
+			}
+		}
+	}
+	return a
+}
+
+// rejBoundedPoly is RejBoundedPoly of FIPS 204, Algorithm 31, which samples a polynomial with
+// coefficients in [-η, η].
+func rejBoundedPoly(rho []byte, index uint16, eta uint32) ringElement {
+	h := sha3.NewSHAKE256()
+	h.Write(rho)
+	h.Write(binary.LittleEndian.AppendUint16(nil, index))
+
+	var a ringElement
+	var buf [136]byte
+	j := 0
+	for j < n {
+		h.Read(buf[:])
+		for i := 0; i < len(buf) && j < n; i++ {
+			for _, b := range [2]uint32{uint32(buf[i]) & 0xf, uint32(buf[i]) >> 4} {
+				if j == n {
+					break
+				}
+				// CoeffFromHalfByte
+				if eta == 2 && b < 15 {
+					a[j] = fieldFromSigned(2 - int32(b%5))
+					j++
+				} else if eta == 4 && b < 9 {
+					a[j] = fieldFromSigned(4 - int32(b))
+					j++
+				}
+			}
+		}
+	}
+	return a
+}
+
+// sampleInBall is SampleInBall of FIPS 204, Algorithm 29, which samples the challenge polynomial
+// with tau ±1 coefficients.
+func sampleInBall(cTilde []byte, tau int) ringElement {
+	h := sha3.NewSHAKE256()
+	h.Write(cTilde)
+
+	var c ringElement
+	var buf [136]byte
+	h.Read(buf[:])
+	signs := binary.LittleEndian.Uint64(buf[:8])
+	pos := 8
+	for i := n - tau; i < n; i++ {
+		var j int
+		for {
+			if pos == len(buf) {
+				h.Read(buf[:])
+				pos = 0
+			}
+			j = int(buf[pos])
+			pos++
+			if j <= i {
+				break
+			}
+		}
+		c[i] = c[j]
+		c[j] = 1
+		if signs&1 == 1 {
+			c[j] = q - 1
+		}
+		signs >>= 1
+	}
+	return c
+}
+
+// expandMask is ExpandMask of FIPS 204, Algorithm 34, which samples the masking vector y.
+func expandMask(y []ringElement, rho []byte, kappa int, gamma1Bits int) {
+	bits := gamma1Bits + 1
+	buf := make([]byte, n*bits/8)
+	for r := range y {
+		h := sha3.NewSHAKE256()
+		h.Write(rho)
+		h.Write(binary.LittleEndian.AppendUint16(nil, uint16(kappa+r)))
+		h.Read(buf)
+		// all the values are valid, as 2^bits - 1 = 2γ₁ - 1
+		y[r], _ = readSigned(buf, 1<<gamma1Bits-1, 1<<gamma1Bits, bits)
+	}
+}
+
+// messageRepresentative returns μ = H(tr || M', 64), where M' is the concatenation of prefix and
+// message.
+func messageRepresentative(tr *[64]byte, prefix, message []byte) [64]byte {
+	h := sha3.NewSHAKE256()
+	h.Write(tr[:])
+	h.Write(prefix)
+	h.Write(message)
+	var mu [64]byte
+	h.Read(mu[:])
+	return mu
+}
+
+// domainPrefix returns the prefix of the message of the pure ML-DSA signatures, 0 || |ctx| || ctx.
+func domainPrefix(context []byte) ([]byte, error) {
+	if len(context) > MaxContextSize {
+		return nil, errors.New("mldsa: context too long")
+	}
+	return append([]byte{0, byte(len(context))}, context...), nil
+}
+
+// Sign returns a hedged signature of message with the context string context, which is at most
+// MaxContextSize bytes and must be the same to verify the signature. It is ML-DSA.Sign of
+// FIPS 204, Algorithm 2, drawing the randomness from crypto/rand.
+func (sk *PrivateKey) Sign(message, context []byte) ([]byte, error) {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return nil, err
+	}
+	var rnd [32]byte
+	if _, err := rand.Read(rnd[:]); err != nil {
+		return nil, err
+	}
+	return sk.signInternal(prefix, message, &rnd), nil
+}
+
+// SignDeterministic is the deterministic variant of Sign, which always returns the same
+// signature for the same message.
+func (sk *PrivateKey) SignDeterministic(message, context []byte) ([]byte, error) {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return nil, err
+	}
+	return sk.signInternal(prefix, message, &[32]byte{}), nil
+}
+
+// signInternal is ML-DSA.Sign_internal of FIPS 204, Algorithm 7, for M' = prefix || message.
+func (sk *PrivateKey) signInternal(prefix, message []byte, rnd *[32]byte) []byte {
+	params := sk.pk.params
+	k, l := params.k, params.l
+	gamma1 := uint32(1) << params.gamma1Bits
+	beta := params.beta()
+
+	mu := messageRepresentative(&sk.pk.tr, prefix, message)
+	var rhoPrime [64]byte
+	h := sha3.NewSHAKE256()
+	h.Write(sk.key[:])
+	h.Write(rnd[:])
+	h.Write(mu[:])
+	h.Read(rhoPrime[:])
+
+	var y, z [maxL]ringElement
+	var w [maxK]ringElement
+	var yHat [maxL]nttElement
+	hint := make([][n]bool, k)
+	cTilde := make([]byte, params.lambda/4)
+	w1Encoded := make([]byte, 0, k*n*params.w1Bits()/8)
+
+	for kappa := 0; ; kappa += l {
+		expandMask(y[:l], rhoPrime[:], kappa, params.gamma1Bits)
+		for r := range l {
+			yHat[r] = ntt(y[r])
+		}
+
+		w1Encoded = w1Encoded[:0]
+		for i := range k {
+			var acc nttElement
+			for j := range l {
+				nttMulAcc(&acc, &sk.pk.a[i*l+j], &yHat[j])
+			}
+			w[i] = inverseNTT(acc)
+			var w1 [n]uint32
+			for j, c := range w[i] {
+				w1[j], _ = decompose(c, params.gamma2)
+			}
+			w1Encoded = appendBits(w1Encoded, &w1, params.w1Bits())
+		}
+
+		h := sha3.NewSHAKE256()
+		h.Write(mu[:])
+		h.Write(w1Encoded)
+		h.Read(cTilde)
+		c := sampleInBall(cTilde, params.tau)
+		cHat := ntt(c)
+
+		if !sk.computeResponse(z[:l], y[:l], w[:k], hint, &cHat, gamma1, beta) {
+			continue
+		}
+
+		signature := make([]byte, 0, params.SignatureSize())
+		signature = append(signature, cTilde...)
+		for r := range l {
+			signature = appendSigned(signature, &z[r], gamma1, params.gamma1Bits+1)
+		}
+		return appendHint(signature, hint, params.omega)
+	}
+}
+
+// computeResponse computes the response z and the hints of the challenge cHat, and returns false
+// if they must be rejected.
+func (sk *PrivateKey) computeResponse(z, y, w []ringElement, hint [][n]bool, cHat *nttElement, gamma1, beta uint32) bool {
+	params := sk.pk.params
+	for r := range z {
+		cs1 := inverseNTT(nttMul(cHat, &sk.s1[r]))
+		z[r] = ringAdd(&y[r], &cs1)
+		if ringInfinityNorm(&z[r]) >= gamma1-beta {
+			return false
+		}
+	}
+
+	hints := 0
+	for i := range w {
+		cs2 := inverseNTT(nttMul(cHat, &sk.s2[i]))
+		r := ringSub(&w[i], &cs2)
+		ct0 := inverseNTT(nttMul(cHat, &sk.t0[i]))
+		if ringInfinityNorm(&ct0) >= params.gamma2 {
+			return false
+		}
+		for j, c := range r {
+			r1, r0 := decompose(c, params.gamma2)
+			if uint32(max(r0, -r0)) >= params.gamma2-beta {
+				return false
+			}
+			// MakeHint(-ct0, r + ct0)
+			v1, _ := decompose(fieldAdd(c, ct0[j]), params.gamma2)
+			hint[i][j] = r1 != v1
+			if hint[i][j] {
+				hints++
+			}
+		}
+	}
+	return hints <= params.omega
+}
+
+// Verify reports whether signature is a valid signature of message with the context string
+// context by pk. It is ML-DSA.Verify of FIPS 204, Algorithm 3.
+func Verify(pk *PublicKey, message, signature, context []byte) bool {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return false
+	}
+	return pk.verifyInternal(prefix, message, signature)
+}
+
+// verifyInternal is ML-DSA.Verify_internal of FIPS 204, Algorithm 8, for M' = prefix || message.
+func (pk *PublicKey) verifyInternal(prefix, message, signature []byte) bool {
+	params := pk.params
+	k, l := params.k, params.l
+	gamma1 := uint32(1) << params.gamma1Bits
+	if len(signature) != params.SignatureSize() {
+		return false
+	}
+
+	// sigDecode
+	cTilde := signature[:params.lambda/4]
+	rest := signature[params.lambda/4:]
+	zSize := n * (params.gamma1Bits + 1) / 8
+	var zHat [maxL]nttElement
+	for r := range l {
+		z, _ := readSigned(rest[:zSize], gamma1-1, gamma1, params.gamma1Bits+1)
+		if ringInfinityNorm(&z) >= gamma1-params.beta() {
+			return false
+		}
+		zHat[r] = ntt(z)
+		rest = rest[zSize:]
+	}
+	hint := make([][n]bool, k)
+	if !readHint(rest, hint, params.omega) {
+		return false
+	}
+
+	mu := messageRepresentative(&pk.tr, prefix, message)
+	c := sampleInBall(cTilde, params.tau)
+	cHat := ntt(c)
+
+	w1Encoded := make([]byte, 0, k*n*params.w1Bits()/8)
+	for i := range k {
+		var acc nttElement
+		for j := range l {
+			nttMulAcc(&acc, &pk.a[i*l+j], &zHat[j])
+		}
+		ct1 := nttMul(&cHat, &pk.t1[i])
+		wApprox := inverseNTT(nttSub(&acc, &ct1))
+		var w1 [n]uint32
+		for j, c := range wApprox {
+			w1[j] = useHint(hint[i][j], c, params.gamma2)
+		}
+		w1Encoded = appendBits(w1Encoded, &w1, params.w1Bits())
+	}
+
+	h := sha3.NewSHAKE256()
+	h.Write(mu[:])
+	h.Write(w1Encoded)
+	cTildePrime := make([]byte, len(cTilde))
+	h.Read(cTildePrime)
+	return bytes.Equal(cTilde, cTildePrime)
+}
+
+func shake256(out, in []byte) {
+	h := sha3.NewSHAKE256()
+	h.Write(in)
+	h.Read(out)
+}
@@ -0,0 +1,204 @@
+package mldsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var parameterSets = []*Parameters{MLDSA44, MLDSA65, MLDSA87}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	*h = decoded
+	return err
+}
+
+// testdata holds the ACVP sample vectors of ML-DSA, prompts and expected results, as found in
+// sign/mldsa/testdata of github.com/cloudflare/circl v1.6.5, which are those of
+// https://github.com/usnistgov/ACVP-Server. The sigGen and sigVer vectors use the internal
+// interface: the message is M' and the private keys are expanded.
+type acvpTest struct {
+	TcID       json.Number `json:"tcId"`
+	Seed       hexBytes    `json:"seed"`
+	PK         hexBytes    `json:"pk"`
+	SK         hexBytes    `json:"sk"`
+	Message    hexBytes    `json:"message"`
+	Rnd        hexBytes    `json:"rnd"`
+	Signature  hexBytes    `json:"signature"`
+	TestPassed bool        `json:"testPassed"`
+}
+
+type acvpGroup struct {
+	ParameterSet  string     `json:"parameterSet"`
+	Deterministic bool       `json:"deterministic"`
+	PK            hexBytes   `json:"pk"`
+	Tests         []acvpTest `json:"tests"`
+}
+
+// readACVP returns the groups of the prompts of mode, and the expected results by test case.
+func readACVP(t *testing.T, mode string) ([]acvpGroup, map[json.Number]acvpTest) {
+	t.Helper()
+	read := func(file string) []acvpGroup {
+		f, err := os.Open(filepath.Join("testdata", "ML-DSA-"+mode+"-FIPS204", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vectorSet struct {
+			TestGroups []acvpGroup `json:"testGroups"`
+		}
+		if err := json.NewDecoder(r).Decode(&vectorSet); err != nil {
+			t.Fatal(err)
+		}
+		return vectorSet.TestGroups
+	}
+
+	results := make(map[json.Number]acvpTest)
+	for _, group := range read("expectedResults.json.gz") {
+		for _, test := range group.Tests {
+			results[test.TcID] = test
+		}
+	}
+	return read("prompt.json.gz"), results
+}
+
+func parametersByName(t *testing.T, name string) *Parameters {
+	t.Helper()
+	for _, params := range parameterSets {
+		if params.String() == name {
+			return params
+		}
+	}
+	t.Fatalf("unknown parameter set %s", name)
+	return nil
+}
+
+func TestACVPKeyGen(t *testing.T) {
+	groups, results := readACVP(t, "keyGen")
+	for _, group := range groups {
+		params := parametersByName(t, group.ParameterSet)
+		for _, test := range group.Tests {
+			expected := results[test.TcID]
+			sk, expanded := newPrivateKey(params, test.Seed, true)
+			if !bytes.Equal(sk.PublicKey().Bytes(), expected.PK) {
+				t.Errorf("%s #%s: wrong public key", params, test.TcID)
+			}
+			if !bytes.Equal(expanded, expected.SK) {
+				t.Errorf("%s #%s: wrong expanded private key", params, test.TcID)
+			}
+		}
+	}
+}
+
+func TestACVPSigGen(t *testing.T) {
+	groups, results := readACVP(t, "sigGen")
+	for _, group := range groups {
+		params := parametersByName(t, group.ParameterSet)
+		for _, test := range group.Tests {
+			sk, err := newPrivateKeyFromExpanded(params, test.SK)
+			if err != nil {
+				t.Fatalf("%s #%s: %v", params, test.TcID, err)
+			}
+			var rnd [32]byte
+			if !group.Deterministic {
+				copy(rnd[:], test.Rnd)
+			}
+			signature := sk.signInternal(nil, test.Message, &rnd)
+			if !bytes.Equal(signature, results[test.TcID].Signature) {
+				t.Errorf("%s #%s: wrong signature", params, test.TcID)
+			}
+			if !sk.PublicKey().verifyInternal(nil, test.Message, signature) {
+				t.Errorf("%s #%s: signature rejected", params, test.TcID)
+			}
+		}
+	}
+}
+
+func TestACVPSigVer(t *testing.T) {
+	groups, results := readACVP(t, "sigVer")
+	for _, group := range groups {
+		params := parametersByName(t, group.ParameterSet)
+		pk, err := NewPublicKey(params, group.PK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range group.Tests {
+			valid := pk.verifyInternal(nil, test.Message, test.Signature)
+			if valid != results[test.TcID].TestPassed {
+				t.Errorf("%s #%s: expected %v, got %v", params, test.TcID, results[test.TcID].TestPassed, valid)
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	message := []byte("message")
+	context := []byte("context")
+	for _, params := range parameterSets {
+		t.Run(params.String(), func(t *testing.T) {
+			sk, err := GenerateKey(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, err := NewPublicKey(params, sk.PublicKey().Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pk.Bytes()) != params.PublicKeySize() {
+				t.Errorf("expected a %d-byte public key, got %d bytes", params.PublicKeySize(), len(pk.Bytes()))
+			}
+
+			signature, err := sk.Sign(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(signature) != params.SignatureSize() {
+				t.Errorf("expected a %d-byte signature, got %d bytes", params.SignatureSize(), len(signature))
+			}
+			if !Verify(pk, message, signature, context) {
+				t.Error("valid signature rejected")
+			}
+			if Verify(pk, message, signature, nil) {
+				t.Error("signature accepted with another context")
+			}
+			if Verify(pk, []byte("other message"), signature, context) {
+				t.Error("signature of another message accepted")
+			}
+			signature[len(signature)/2] ^= 1
+			if Verify(pk, message, signature, context) {
+				t.Error("corrupted signature accepted")
+			}
+
+			hedged, err := sk.Sign(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(hedged, signature) {
+				t.Error("hedged signatures are equal")
+			}
+			deterministic, err := sk.SignDeterministic(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, _ := sk.SignDeterministic(message, context)
+			if !bytes.Equal(deterministic, again) {
+				t.Error("deterministic signatures differ")
+			}
+
+			if _, err := sk.Sign(message, make([]byte, MaxContextSize+1)); err == nil {
+				t.Error("context too long accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// crypto/mldsa was added to the standard library in Go 1.27.
+
+//go:build go1.27
+
+package mldsa
+
+import (
+	"bytes"
+	"crypto/mldsa"
+	"testing"
+)
+
+// TestStandardLibrary checks that keys and deterministic signatures are those of crypto/mldsa,
+// and that the signatures of each implementation are accepted by the other.
+func TestStandardLibrary(t *testing.T) {
+	message := []byte("message")
+	context := []byte("context")
+	for i, stdParams := range []mldsa.Parameters{mldsa.MLDSA44(), mldsa.MLDSA65(), mldsa.MLDSA87()} {
+		params := parameterSets[i]
+		t.Run(params.String(), func(t *testing.T) {
+			stdSK, err := mldsa.GenerateKey(stdParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sk, err := NewPrivateKey(params, stdSK.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sk.PublicKey().Bytes(), stdSK.PublicKey().Bytes()) {
+				t.Fatal("public keys differ")
+			}
+
+			options := &mldsa.Options{Context: string(context)}
+			stdSignature, err := stdSK.SignDeterministic(message, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err := sk.SignDeterministic(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(signature, stdSignature) {
+				t.Error("deterministic signatures differ")
+			}
+
+			hedged, err := sk.Sign(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := mldsa.Verify(stdSK.PublicKey(), message, hedged, options); err != nil {
+				t.Errorf("signature rejected by crypto/mldsa: %v", err)
+			}
+			stdHedged, err := stdSK.Sign(nil, message, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(sk.PublicKey(), message, stdHedged, context) {
+				t.Error("signature of crypto/mldsa rejected")
+			}
+		})
+	}
+}
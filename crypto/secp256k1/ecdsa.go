@@ -0,0 +1,253 @@
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// ECDSASignatureSize is the size of the ECDSA signatures, r || s.
+	ECDSASignatureSize = 64
+	// RecoverableSignatureSize is the size of the recoverable ECDSA signatures, r || s || v where v
+	// is the recovery id.
+	RecoverableSignatureSize = 65
+)
+
+// hashToScalar is bits2int of RFC 6979 modulo n: the leftmost 256 bits of hash.
+func hashToScalar(hash []byte) *scalar {
+	var b [32]byte
+	if len(hash) > len(b) {
+		hash = hash[:len(b)]
+	}
+	copy(b[len(b)-len(hash):], hash)
+	e := &scalar{}
+	e.setBytes(&b)
+	return e
+}
+
+// nonceGenerator is the HMAC-SHA256 DRBG of RFC 6979, Section 3.2, which derives the nonces from
+// the private key and the message hash, and the additional data of Section 3.6.
+type nonceGenerator struct {
+	k, v []byte
+	mac  hash.Hash
+	// started is set once a candidate was returned or rejected
+	started bool
+}
+
+func newNonceGenerator(sk *PrivateKey, hash, additionalData []byte) *nonceGenerator {
+	x := sk.d.bytes()
+	h1 := hashToScalar(hash).bytes()
+	g := &nonceGenerator{k: make([]byte, sha256.Size), v: make([]byte, sha256.Size)}
+	for i := range g.v {
+		g.v[i] = 1
+	}
+	for _, separator := range []byte{0, 1} {
+		g.mac = hmac.New(sha256.New, g.k)
+		g.mac.Write(g.v)
+		g.mac.Write([]byte{separator})
+		g.mac.Write(x[:])
+		g.mac.Write(h1[:])
+		g.mac.Write(additionalData)
+		g.k = g.mac.Sum(g.k[:0])
+		g.mac = hmac.New(sha256.New, g.k)
+		g.update()
+	}
+	return g
+}
+
+// update sets V = HMAC_K(V).
+func (g *nonceGenerator) update() {
+	g.mac.Reset()
+	g.mac.Write(g.v)
+	g.v = g.mac.Sum(g.v[:0])
+}
+
+// next returns the next nonce in [1, n-1].
+func (g *nonceGenerator) next() *scalar {
+	for {
+		if g.started {
+			// K = HMAC_K(V || 0x00), V = HMAC_K(V) after a rejected candidate
+			g.mac.Reset()
+			g.mac.Write(g.v)
+			g.mac.Write([]byte{0})
+			g.k = g.mac.Sum(g.k[:0])
+			g.mac = hmac.New(sha256.New, g.k)
+			g.update()
+		}
+		g.started = true
+		g.update()
+		k := &scalar{}
+		if k.setBytes((*[32]byte)(g.v)) && k.isZero() == 0 {
+			return k
+		}
+	}
+}
+
+// SignECDSA returns the ECDSA signature r || s of hash, with a low s. Hashes longer than 32
+// bytes are truncated.
+func (sk *PrivateKey) SignECDSA(hash []byte) ([]byte, error) {
+	signature, err := sk.SignECDSARecoverable(hash)
+	if err != nil {
+		return nil, err
+	}
+	return signature[:ECDSASignatureSize], nil
+}
+
+// SignECDSARecoverable returns the ECDSA signature r || s of hash followed by the recovery id v,
+// from which RecoverPublicKey computes the public key: bit 0 of v is the parity of the y of the
+// nonce point, and bit 1 is set if its x is above n.
+func (sk *PrivateKey) SignECDSARecoverable(hash []byte) ([]byte, error) {
+	additionalData := make([]byte, 32)
+	if _, err := rand.Read(additionalData); err != nil {
+		return nil, err
+	}
+	return sk.signECDSA(hash, additionalData), nil
+}
+
+// signECDSA signs hash with the nonces of RFC 6979 with additionalData, which are
+// deterministic if it is empty.
+func (sk *PrivateKey) signECDSA(hash, additionalData []byte) []byte {
+	e := hashToScalar(hash)
+	nonces := newNonceGenerator(sk, hash, additionalData)
+	for {
+		k := nonces.next()
+		var rPoint point
+		x, y := rPoint.scalarBaseMult(k).affine()
+		xBytes := x.bytes()
+		var r scalar
+		overflow := !r.setBytes(&xBytes)
+		if r.isZero() == 1 {
+			continue
+		}
+		// s = (e + rd) / k
+		var s, kInv scalar
+		s.mul(&r, &sk.d).add(&s, e).mul(&s, kInv.invert(k))
+		if s.isZero() == 1 {
+			continue
+		}
+
+		v := byte(y.isOdd())
+		if overflow {
+			v |= 2
+		}
+		// -s is the signature of the nonce -k, whose point has the other y
+		high := s.isHigh()
+		var negS scalar
+		s.selectFrom(negS.neg(&s), &s, high)
+		v ^= byte(high)
+
+		rBytes, sBytes := r.bytes(), s.bytes()
+		signature := make([]byte, 0, RecoverableSignatureSize)
+		signature = append(signature, rBytes[:]...)
+		signature = append(signature, sBytes[:]...)
+		return append(signature, v)
+	}
+}
+
+// parseECDSASignature decodes r and s, which must be in [1, n-1] with a low s.
+func parseECDSASignature(signature []byte) (r, s *scalar, ok bool) {
+	r, s = &scalar{}, &scalar{}
+	if !r.setBytes((*[32]byte)(signature[:32])) || r.isZero() == 1 ||
+		!s.setBytes((*[32]byte)(signature[32:64])) || s.isZero() == 1 || s.isHigh() == 1 {
+		return nil, nil, false
+	}
+	return r, s, true
+}
+
+// VerifyECDSA reports whether signature is a valid ECDSA signature r || s of hash by pk. High-s
+// signatures are rejected.
+func VerifyECDSA(pk *PublicKey, hash, signature []byte) bool {
+	if len(signature) != ECDSASignatureSize {
+		return false
+	}
+	r, s, ok := parseECDSASignature(signature)
+	if !ok {
+		return false
+	}
+	return verifyECDSA(pk, hash, r, s)
+}
+
+// verifyECDSA verifies the signature (r, s), for r and s in [1, n-1], whether s is low or not.
+func verifyECDSA(pk *PublicKey, hash []byte, r, s *scalar) bool {
+	e := hashToScalar(hash)
+	var w, u1, u2 scalar
+	w.invert(s)
+	u1.mul(e, &w)
+	u2.mul(r, &w)
+	var q point
+	q.doubleScalarMultVartime(&u1, &u2, &pk.p)
+	if q.isIdentity() == 1 {
+		return false
+	}
+
+	// x(Q) mod n == r, without an inversion: X == xZ for x = r or, if it is below p, r + n
+	rBytes := r.bytes()
+	var x, xz fieldElement
+	x.setBytes(&rBytes)
+	if xz.mul(&x, &q.z).equal(&q.x) == 1 {
+		return true
+	}
+	xBytes, ok := addN(&rBytes)
+	if !ok || !x.setBytes(&xBytes) {
+		return false
+	}
+	return xz.mul(&x, &q.z).equal(&q.x) == 1
+}
+
+// addN returns r + n, and false if it overflows 256 bits.
+func addN(r *[32]byte) ([32]byte, bool) {
+	var x limbs
+	// r is below n, so this only decodes it
+	scalarModulus.setBytes(&x, r)
+	var carry uint64
+	for i := range 4 {
+		x[i], carry = bits.Add64(x[i], n[i], carry)
+	}
+	return x.bytes(), carry == 0
+}
+
+// RecoverPublicKey returns the public key of a recoverable ECDSA signature r || s || v of hash.
+func RecoverPublicKey(hash, signature []byte) (*PublicKey, error) {
+	if len(signature) != RecoverableSignatureSize || signature[64] > 3 {
+		return nil, errors.New("secp256k1: invalid recoverable signature")
+	}
+	r, s, ok := parseECDSASignature(signature)
+	if !ok {
+		return nil, errors.New("secp256k1: invalid recoverable signature")
+	}
+	v := signature[64]
+
+	// R is the nonce point, of x-coordinate r or r + n
+	xBytes := r.bytes()
+	if v&2 != 0 {
+		if xBytes, ok = addN(&xBytes); !ok {
+			return nil, errors.New("secp256k1: invalid recoverable signature")
+		}
+	}
+	var rPoint point
+	if err := rPoint.liftX(&xBytes); err != nil {
+		return nil, errors.New("secp256k1: invalid recoverable signature")
+	}
+	if v&1 == 1 {
+		rPoint.neg(&rPoint)
+	}
+
+	// Q = (sR - eG) / r
+	e := hashToScalar(hash)
+	var rInv, u1, u2 scalar
+	rInv.invert(r)
+	u1.mul(e, &rInv).neg(&u1)
+	u2.mul(s, &rInv)
+	var q point
+	q.doubleScalarMultVartime(&u1, &u2, &rPoint)
+	if q.isIdentity() == 1 {
+		return nil, errors.New("secp256k1: invalid recoverable signature")
+	}
+	pk := &PublicKey{}
+	pk.setPoint(&q)
+	return pk, nil
+}
@@ -0,0 +1,216 @@
+package secp256k1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	*h = decoded
+	return err
+}
+
+// testdata/rfc6979-sha256.csv holds deterministic ECDSA signatures of SHA-256 hashes, with
+// decimal private keys and DER signatures, from https://bitcointalk.org/index.php?topic=285142.40
+// as found in secec/testdata of gitlab.com/yawning/secp256k1-voi.
+func TestRFC6979(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "rfc6979-sha256.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		d, ok := new(big.Int).SetString(record[0], 10)
+		if !ok {
+			t.Fatalf("invalid private key %q", record[0])
+		}
+		sk, err := NewPrivateKey(d.FillBytes(make([]byte, PrivateKeySize)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := hex.DecodeString(record[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256([]byte(record[1]))
+		signature := sk.signECDSA(hash[:], nil)
+		if der := toDER(signature); !bytes.Equal(der, expected) {
+			t.Errorf("%q: expected %X, got %X", record[1], expected, der)
+		}
+		if !VerifyECDSA(sk.PublicKey(), hash[:], signature[:ECDSASignatureSize]) {
+			t.Errorf("%q: valid signature rejected", record[1])
+		}
+	}
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+func toDER(signature []byte) []byte {
+	der, err := asn1.Marshal(derSignature{new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64])})
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
+
+// testdata/wycheproof-ecdsa-sha256.json.gz holds the ECDSA secp256k1 SHA-256 verification
+// vectors of Wycheproof, https://github.com/C2SP/wycheproof, whose DER signatures are decoded
+// here. As many valid signatures have a high s, they are checked with verifyECDSA.
+func TestWycheproof(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "wycheproof-ecdsa-sha256.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors struct {
+		TestGroups []struct {
+			PublicKey struct {
+				Uncompressed hexBytes `json:"uncompressed"`
+			} `json:"publicKey"`
+			Tests []struct {
+				TcID    int      `json:"tcId"`
+				Comment string   `json:"comment"`
+				Msg     hexBytes `json:"msg"`
+				Sig     hexBytes `json:"sig"`
+				Result  string   `json:"result"`
+			} `json:"tests"`
+		} `json:"testGroups"`
+	}
+	if err := json.NewDecoder(gz).Decode(&vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, group := range vectors.TestGroups {
+		pk, err := NewPublicKey(group.PublicKey.Uncompressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range group.Tests {
+			hash := sha256.Sum256(test.Msg)
+			valid := false
+			if r, s, ok := fromDER(test.Sig); ok {
+				valid = verifyECDSA(pk, hash[:], r, s)
+			}
+			if valid != (test.Result == "valid") {
+				t.Errorf("tcId %d (%s): expected %s, got valid = %v", test.TcID, test.Comment, test.Result, valid)
+			}
+		}
+	}
+}
+
+func (s *scalar) bytesSlice() []byte {
+	b := s.bytes()
+	return b[:]
+}
+
+// fromDER decodes a strict DER ECDSA signature with r and s in [1, n-1].
+func fromDER(der []byte) (r, s *scalar, ok bool) {
+	var signature derSignature
+	rest, err := asn1.Unmarshal(der, &signature)
+	if err != nil || len(rest) != 0 || signature.R.Sign() <= 0 || signature.S.Sign() <= 0 ||
+		signature.R.Cmp(bigN) >= 0 || signature.S.Cmp(bigN) >= 0 {
+		return nil, nil, false
+	}
+	// encoding/asn1 accepts some BER encodings, which don't survive a round trip
+	if reencoded, err := asn1.Marshal(signature); err != nil || !bytes.Equal(reencoded, der) {
+		return nil, nil, false
+	}
+	var b [32]byte
+	r, s = &scalar{}, &scalar{}
+	r.setBytes((*[32]byte)(signature.R.FillBytes(b[:])))
+	s.setBytes((*[32]byte)(signature.S.FillBytes(b[:])))
+	return r, s, true
+}
+
+func TestECDSA(t *testing.T) {
+	sk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("message"))
+	for range 16 {
+		signature, err := sk.SignECDSARecoverable(hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(signature) != RecoverableSignatureSize {
+			t.Fatalf("expected a %d-byte signature, got %d bytes", RecoverableSignatureSize, len(signature))
+		}
+		if !VerifyECDSA(sk.PublicKey(), hash[:], signature[:ECDSASignatureSize]) {
+			t.Error("valid signature rejected")
+		}
+		otherHash := sha256.Sum256([]byte("other message"))
+		if VerifyECDSA(sk.PublicKey(), otherHash[:], signature[:ECDSASignatureSize]) {
+			t.Error("signature of another message accepted")
+		}
+
+		// the high-s signature is the same with -s
+		var s scalar
+		s.setBytes((*[32]byte)(signature[32:64]))
+		s.neg(&s)
+		highS := append(bytes.Clone(signature[:32]), s.bytesSlice()...)
+		if VerifyECDSA(sk.PublicKey(), hash[:], highS) {
+			t.Error("high-s signature accepted")
+		}
+		r, _, _ := parseECDSASignature(signature)
+		if !verifyECDSA(sk.PublicKey(), hash[:], r, &s) {
+			t.Error("high-s signature rejected by verifyECDSA")
+		}
+
+		pk, err := RecoverPublicKey(hash[:], signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pk.Bytes(), sk.PublicKey().Bytes()) {
+			t.Error("recovered another public key")
+		}
+		signature[64] ^= 1
+		if pk, err := RecoverPublicKey(hash[:], signature); err == nil && bytes.Equal(pk.Bytes(), sk.PublicKey().Bytes()) {
+			t.Error("recovered the public key with another recovery id")
+		}
+	}
+}
+
+func TestRecoverPublicKeyInvalid(t *testing.T) {
+	hash := sha256.Sum256([]byte("message"))
+	for _, signature := range []string{
+		// r = 0
+		strings.Repeat("00", 32) + strings.Repeat("11", 32) + "00",
+		// v = 4
+		strings.Repeat("11", 32) + strings.Repeat("11", 32) + "04",
+		// high s
+		strings.Repeat("11", 32) + strings.Repeat("ee", 32) + "00",
+		// r + n above p
+		strings.Repeat("11", 32) + strings.Repeat("11", 32) + "02",
+	} {
+		decoded, _ := hex.DecodeString(signature)
+		if _, err := RecoverPublicKey(hash[:], decoded); err == nil {
+			t.Errorf("invalid signature %s accepted", signature)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package secp256k1
+
+// p is the prime of the base field, 2²⁵⁶ - 2³² - 977.
+var p = limbs{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+
+var (
+	fieldModulus = newModulus(p)
+	// pMinus2 is the exponent of the inversions, by Fermat's little theorem.
+	pMinus2 = limbs{0xfffffffefffffc2d, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+	// pPlus1Over4 is the exponent of the square roots, since p ≡ 3 mod 4.
+	pPlus1Over4 = limbs{0xffffffffbfffff0c, 0xffffffffffffffff, 0xffffffffffffffff, 0x3fffffffffffffff}
+)
+
+// A fieldElement is an integer modulo p, in the Montgomery domain.
+type fieldElement struct {
+	l limbs
+}
+
+func newFieldElement(x uint64) *fieldElement {
+	e := &fieldElement{}
+	fieldModulus.toMontgomery(&e.l, &limbs{x})
+	return e
+}
+
+// setBytes decodes a big-endian field element, and reports whether it was below p.
+func (e *fieldElement) setBytes(b *[32]byte) bool {
+	canonical := fieldModulus.setBytes(&e.l, b)
+	fieldModulus.toMontgomery(&e.l, &e.l)
+	return canonical
+}
+
+// bytes returns the big-endian encoding of e.
+func (e *fieldElement) bytes() [32]byte {
+	var x limbs
+	fieldModulus.fromMontgomery(&x, &e.l)
+	return x.bytes()
+}
+
+func (e *fieldElement) add(x, y *fieldElement) *fieldElement {
+	fieldModulus.add(&e.l, &x.l, &y.l)
+	return e
+}
+
+func (e *fieldElement) sub(x, y *fieldElement) *fieldElement {
+	fieldModulus.sub(&e.l, &x.l, &y.l)
+	return e
+}
+
+func (e *fieldElement) neg(x *fieldElement) *fieldElement {
+	fieldModulus.sub(&e.l, &limbs{}, &x.l)
+	return e
+}
+
+func (e *fieldElement) mul(x, y *fieldElement) *fieldElement {
+	fieldModulus.mul(&e.l, &x.l, &y.l)
+	return e
+}
+
+func (e *fieldElement) square(x *fieldElement) *fieldElement {
+	fieldModulus.mul(&e.l, &x.l, &x.l)
+	return e
+}
+
+// invert sets e = 1/x, and 0 if x is 0.
+func (e *fieldElement) invert(x *fieldElement) *fieldElement {
+	fieldModulus.exp(&e.l, &x.l, &pMinus2)
+	return e
+}
+
+// sqrt sets e to a square root of x and reports whether x is a square. e is unchanged if it
+// isn't.
+func (e *fieldElement) sqrt(x *fieldElement) bool {
+	var candidate, square fieldElement
+	fieldModulus.exp(&candidate.l, &x.l, &pPlus1Over4)
+	if square.square(&candidate).equal(x) == 0 {
+		return false
+	}
+	*e = candidate
+	return true
+}
+
+// equal returns 1 if e == x and 0 otherwise.
+func (e *fieldElement) equal(x *fieldElement) uint64 {
+	return equalLimbs(&e.l, &x.l)
+}
+
+// isZero returns 1 if e is 0 and 0 otherwise.
+func (e *fieldElement) isZero() uint64 {
+	return equalLimbs(&e.l, &limbs{})
+}
+
+// isOdd returns the parity of the canonical value of e.
+func (e *fieldElement) isOdd() uint64 {
+	var x limbs
+	fieldModulus.fromMontgomery(&x, &e.l)
+	return x[0] & 1
+}
+
+// selectFrom sets e = a if cond is 1 and e = b if it is 0.
+func (e *fieldElement) selectFrom(a, b *fieldElement, cond uint64) *fieldElement {
+	selectLimbs(&e.l, &a.l, &b.l, cond)
+	return e
+}
@@ -0,0 +1,190 @@
+package secp256k1
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// limbs is a 256-bit integer as four 64-bit words, least significant first.
+type limbs [4]uint64
+
+// A modulus is an odd 256-bit modulus m > 2²⁵⁵, with the constants of the Montgomery
+// multiplication with R = 2²⁵⁶. The field and scalar elements are stored in the Montgomery
+// domain, as aR mod m, and every operation is constant time.
+type modulus struct {
+	m limbs
+	// m0inv is -m⁻¹ mod 2⁶⁴
+	m0inv uint64
+	// rr is R² mod m, to enter the Montgomery domain
+	rr limbs
+}
+
+func newModulus(m limbs) *modulus {
+	mod := &modulus{m: m}
+	// Newton's iteration doubles the number of correct low bits of m⁻¹ mod 2⁶⁴ at each step
+	inv := uint64(1)
+	for range 6 {
+		inv *= 2 - m[0]*inv
+	}
+	mod.m0inv = -inv
+
+	// R mod m = R - m since m > R/2, doubled 256 times
+	var r limbs
+	var borrow uint64
+	for i := range 4 {
+		r[i], borrow = bits.Sub64(0, m[i], borrow)
+	}
+	for range 256 {
+		mod.add(&r, &r, &r)
+	}
+	mod.rr = r
+	return mod
+}
+
+// mul sets z = x * y / R mod m, with the CIOS Montgomery multiplication.
+func (mod *modulus) mul(z, x, y *limbs) {
+	var t [6]uint64
+	for i := range 4 {
+		var c, carry uint64
+		for j := range 4 {
+			hi, lo := bits.Mul64(x[j], y[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		q := t[0] * mod.m0inv
+		hi, lo := bits.Mul64(q, mod.m[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(q, mod.m[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	mod.reduce(z, (*limbs)(t[:4]), t[4])
+}
+
+// reduce sets z = carry·2²⁵⁶ + x mod m, for a value below 2m.
+func (mod *modulus) reduce(z, x *limbs, carry uint64) {
+	var d limbs
+	var borrow uint64
+	d[0], borrow = bits.Sub64(x[0], mod.m[0], 0)
+	d[1], borrow = bits.Sub64(x[1], mod.m[1], borrow)
+	d[2], borrow = bits.Sub64(x[2], mod.m[2], borrow)
+	d[3], borrow = bits.Sub64(x[3], mod.m[3], borrow)
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	// keep x if the subtraction underflowed
+	selectLimbs(z, x, &d, borrow)
+}
+
+// add sets z = x + y mod m.
+func (mod *modulus) add(z, x, y *limbs) {
+	var s limbs
+	var carry uint64
+	s[0], carry = bits.Add64(x[0], y[0], 0)
+	s[1], carry = bits.Add64(x[1], y[1], carry)
+	s[2], carry = bits.Add64(x[2], y[2], carry)
+	s[3], carry = bits.Add64(x[3], y[3], carry)
+	mod.reduce(z, &s, carry)
+}
+
+// sub sets z = x - y mod m.
+func (mod *modulus) sub(z, x, y *limbs) {
+	var d limbs
+	var borrow, carry uint64
+	d[0], borrow = bits.Sub64(x[0], y[0], 0)
+	d[1], borrow = bits.Sub64(x[1], y[1], borrow)
+	d[2], borrow = bits.Sub64(x[2], y[2], borrow)
+	d[3], borrow = bits.Sub64(x[3], y[3], borrow)
+	// add m back if the subtraction underflowed
+	mask := -borrow
+	z[0], carry = bits.Add64(d[0], mod.m[0]&mask, 0)
+	z[1], carry = bits.Add64(d[1], mod.m[1]&mask, carry)
+	z[2], carry = bits.Add64(d[2], mod.m[2]&mask, carry)
+	z[3], _ = bits.Add64(d[3], mod.m[3]&mask, carry)
+}
+
+// exp sets z = x^e mod m, in the Montgomery domain, with a 4-bit fixed window. e is public: it
+// is a constant of the inversions and square roots.
+func (mod *modulus) exp(z, x *limbs, e *limbs) {
+	var powers [16]limbs
+	mod.mul(&powers[0], &limbs{1}, &mod.rr)
+	for i := 1; i < 16; i++ {
+		mod.mul(&powers[i], &powers[i-1], x)
+	}
+	result := powers[0]
+	for i := 63; i >= 0; i-- {
+		for range 4 {
+			mod.mul(&result, &result, &result)
+		}
+		if w := e[i/16] >> (i % 16 * 4) & 0xf; w != 0 {
+			mod.mul(&result, &result, &powers[w])
+		}
+	}
+	*z = result
+}
+
+// toMontgomery sets z = xR mod m, for x < m.
+func (mod *modulus) toMontgomery(z, x *limbs) {
+	mod.mul(z, x, &mod.rr)
+}
+
+// fromMontgomery sets z = x/R mod m, the canonical value of x.
+func (mod *modulus) fromMontgomery(z, x *limbs) {
+	one := limbs{1}
+	mod.mul(z, x, &one)
+}
+
+// setBytes decodes a big-endian 32-byte integer into z, reducing it once modulo m, and reports
+// whether it was already reduced.
+func (mod *modulus) setBytes(z *limbs, b *[32]byte) bool {
+	var x limbs
+	for i := range 4 {
+		x[i] = binary.BigEndian.Uint64(b[24-8*i:])
+	}
+	var d limbs
+	var borrow uint64
+	d[0], borrow = bits.Sub64(x[0], mod.m[0], 0)
+	d[1], borrow = bits.Sub64(x[1], mod.m[1], borrow)
+	d[2], borrow = bits.Sub64(x[2], mod.m[2], borrow)
+	d[3], borrow = bits.Sub64(x[3], mod.m[3], borrow)
+	selectLimbs(z, &x, &d, borrow)
+	return borrow == 1
+}
+
+// bytes returns the big-endian encoding of x, a canonical value.
+func (x *limbs) bytes() (b [32]byte) {
+	for i := range 4 {
+		binary.BigEndian.PutUint64(b[24-8*i:], x[i])
+	}
+	return b
+}
+
+// selectLimbs sets z = a if cond is 1 and z = b if it is 0.
+func selectLimbs(z, a, b *limbs, cond uint64) {
+	mask := -cond
+	for i := range 4 {
+		z[i] = b[i] ^ (a[i]^b[i])&mask
+	}
+}
+
+// equalLimbs returns 1 if x == y and 0 otherwise.
+func equalLimbs(x, y *limbs) uint64 {
+	var acc uint64
+	for i := range 4 {
+		acc |= x[i] ^ y[i]
+	}
+	// acc | -acc has its top bit set iff acc != 0
+	return 1 ^ (acc|-acc)>>63
+}
@@ -0,0 +1,292 @@
+package secp256k1
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+)
+
+var (
+	// curveB is b of the curve equation y² = x³ + 7, and curveB3 is 3b, for the addition formulas.
+	curveB  = newFieldElement(7)
+	curveB3 = newFieldElement(21)
+
+	generatorX = [32]byte{
+		0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac, 0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+		0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9, 0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98,
+	}
+	generatorY = [32]byte{
+		0x48, 0x3a, 0xda, 0x77, 0x26, 0xa3, 0xc4, 0x65, 0x5d, 0xa4, 0xfb, 0xfc, 0x0e, 0x11, 0x08, 0xa8,
+		0xfd, 0x17, 0xb4, 0x48, 0xa6, 0x85, 0x54, 0x19, 0x9c, 0x47, 0xd0, 0x8f, 0xfb, 0x10, 0xd4, 0xb8,
+	}
+)
+
+// A point is a point of the curve in projective coordinates (X:Y:Z), for (X/Z, Y/Z). The
+// identity is (0:1:0).
+type point struct {
+	x, y, z fieldElement
+}
+
+func newIdentityPoint() *point {
+	return &point{y: *newFieldElement(1)}
+}
+
+func newGeneratorPoint() *point {
+	g := &point{z: *newFieldElement(1)}
+	g.x.setBytes(&generatorX)
+	g.y.setBytes(&generatorY)
+	return g
+}
+
+// add sets q = p1 + p2 with the complete addition formulas for a = 0 of Renes, Costello and
+// Batina, Algorithm 7 of [3], which also hold for doublings and the identity.
+func (q *point) add(p1, p2 *point) *point {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+	t0.mul(&p1.x, &p2.x)
+	t1.mul(&p1.y, &p2.y)
+	t2.mul(&p1.z, &p2.z)
+	t3.add(&p1.x, &p1.y)
+	t4.add(&p2.x, &p2.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&p1.y, &p1.z)
+	x3.add(&p2.y, &p2.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&p1.x, &p1.z)
+	y3.add(&p2.x, &p2.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&x3, &t0)
+	t2.mul(curveB3, &t2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(curveB3, &y3)
+	x3.mul(&t4, &y3)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&t1, &y3)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4)
+	z3.add(&z3, &t0)
+	q.x, q.y, q.z = x3, y3, z3
+	return q
+}
+
+// double sets q = 2p, with Algorithm 9 of [3].
+func (q *point) double(p *point) *point {
+	var t0, t1, t2, x3, y3, z3 fieldElement
+	t0.square(&p.y)
+	z3.add(&t0, &t0)
+	z3.add(&z3, &z3)
+	z3.add(&z3, &z3)
+	t1.mul(&p.y, &p.z)
+	t2.square(&p.z)
+	t2.mul(curveB3, &t2)
+	x3.mul(&t2, &z3)
+	y3.add(&t0, &t2)
+	z3.mul(&t1, &z3)
+	t1.add(&t2, &t2)
+	t2.add(&t1, &t2)
+	t0.sub(&t0, &t2)
+	y3.mul(&t0, &y3)
+	y3.add(&x3, &y3)
+	t1.mul(&p.x, &p.y)
+	x3.mul(&t0, &t1)
+	x3.add(&x3, &x3)
+	q.x, q.y, q.z = x3, y3, z3
+	return q
+}
+
+// neg sets q = -p.
+func (q *point) neg(p *point) *point {
+	q.x = p.x
+	q.y.neg(&p.y)
+	q.z = p.z
+	return q
+}
+
+// isIdentity returns 1 if p is the identity and 0 otherwise.
+func (p *point) isIdentity() uint64 {
+	return p.z.isZero()
+}
+
+// selectFrom sets q = a if cond is 1 and q = b if it is 0.
+func (q *point) selectFrom(a, b *point, cond uint64) *point {
+	q.x.selectFrom(&a.x, &b.x, cond)
+	q.y.selectFrom(&a.y, &b.y, cond)
+	q.z.selectFrom(&a.z, &b.z, cond)
+	return q
+}
+
+// affine returns the affine coordinates of p, which must not be the identity.
+func (p *point) affine() (x, y *fieldElement) {
+	var zInv fieldElement
+	zInv.invert(&p.z)
+	return new(fieldElement).mul(&p.x, &zInv), new(fieldElement).mul(&p.y, &zInv)
+}
+
+// A pointTable holds the multiples 0P to 15P of a point, for the 4-bit windows.
+type pointTable [16]point
+
+func (table *pointTable) init(p *point) {
+	table[0] = *newIdentityPoint()
+	table[1] = *p
+	for i := 2; i < 16; i++ {
+		table[i].add(&table[i-1], p)
+	}
+}
+
+// lookup sets q = table[i] without revealing i through its memory accesses or timing.
+func (table *pointTable) lookup(q *point, i byte) {
+	*q = table[0]
+	for j := 1; j < 16; j++ {
+		q.selectFrom(&table[j], q, uint64(subtle.ConstantTimeByteEq(i, byte(j))))
+	}
+}
+
+// windows returns the 64 4-bit windows of s, most significant first.
+func (s *scalar) windows() (w [64]byte) {
+	b := s.bytes()
+	for i, x := range b {
+		w[2*i] = x >> 4
+		w[2*i+1] = x & 0xf
+	}
+	return w
+}
+
+// scalarMult sets q = sp in constant time, with a fixed 4-bit window.
+func (q *point) scalarMult(s *scalar, p *point) *point {
+	var table pointTable
+	table.init(p)
+	result := newIdentityPoint()
+	var t point
+	for i, w := range s.windows() {
+		if i > 0 {
+			result.double(result)
+			result.double(result)
+			result.double(result)
+			result.double(result)
+		}
+		table.lookup(&t, w)
+		result.add(result, &t)
+	}
+	*q = *result
+	return q
+}
+
+// baseTables holds, for each window i, the multiples 0G to 15G of 16^(63-i)G, so that
+// multiplications of the generator only need additions.
+var baseTables = sync.OnceValue(func() *[64]pointTable {
+	tables := new([64]pointTable)
+	p := newGeneratorPoint()
+	for i := 63; i >= 0; i-- {
+		tables[i].init(p)
+		for range 4 {
+			p.double(p)
+		}
+	}
+	return tables
+})
+
+// scalarBaseMult sets q = sG in constant time.
+func (q *point) scalarBaseMult(s *scalar) *point {
+	tables := baseTables()
+	result := newIdentityPoint()
+	var t point
+	for i, w := range s.windows() {
+		tables[i].lookup(&t, w)
+		result.add(result, &t)
+	}
+	*q = *result
+	return q
+}
+
+// doubleScalarMultVartime sets q = s1G + s2p with Straus' method. It is variable time, and
+// only used on public values by the verifications.
+func (q *point) doubleScalarMultVartime(s1 *scalar, s2 *scalar, p *point) *point {
+	g := &baseTables()[63]
+	var table pointTable
+	table.init(p)
+	w2 := s2.windows()
+	result := newIdentityPoint()
+	for i, w1 := range s1.windows() {
+		if i > 0 {
+			result.double(result)
+			result.double(result)
+			result.double(result)
+			result.double(result)
+		}
+		if w1 != 0 {
+			result.add(result, &g[w1])
+		}
+		if w2[i] != 0 {
+			result.add(result, &table[w2[i]])
+		}
+	}
+	*q = *result
+	return q
+}
+
+// setBytes decodes a SEC 1 compressed or uncompressed point. The identity has no encoding.
+func (q *point) setBytes(b []byte) error {
+	switch {
+	case len(b) == 33 && (b[0] == 2 || b[0] == 3):
+		if err := q.liftX((*[32]byte)(b[1:])); err != nil {
+			return err
+		}
+		if q.y.isOdd() != uint64(b[0]&1) {
+			q.y.neg(&q.y)
+		}
+		return nil
+	case len(b) == 65 && b[0] == 4:
+		var x, y fieldElement
+		if !x.setBytes((*[32]byte)(b[1:33])) || !y.setBytes((*[32]byte)(b[33:])) {
+			return errors.New("secp256k1: invalid point coordinate")
+		}
+		var lhs, rhs fieldElement
+		lhs.square(&y)
+		rhs.square(&x).mul(&rhs, &x).add(&rhs, curveB)
+		if lhs.equal(&rhs) == 0 {
+			return errors.New("secp256k1: point not on the curve")
+		}
+		q.x, q.y, q.z = x, y, *newFieldElement(1)
+		return nil
+	default:
+		return errors.New("secp256k1: invalid point encoding")
+	}
+}
+
+// liftX sets q to the point of x-coordinate x with an even y, which is lift_x of BIP-340.
+func (q *point) liftX(xBytes *[32]byte) error {
+	var x, y, c fieldElement
+	if !x.setBytes(xBytes) {
+		return errors.New("secp256k1: invalid point coordinate")
+	}
+	c.square(&x).mul(&c, &x).add(&c, curveB)
+	if !y.sqrt(&c) {
+		return errors.New("secp256k1: point not on the curve")
+	}
+	var negY fieldElement
+	negY.neg(&y)
+	y.selectFrom(&negY, &y, y.isOdd())
+	q.x, q.y, q.z = x, y, *newFieldElement(1)
+	return nil
+}
+
+// bytes returns the SEC 1 encoding of p, which must not be the identity.
+func (p *point) bytes(compressed bool) []byte {
+	x, y := p.affine()
+	xBytes := x.bytes()
+	if compressed {
+		return append([]byte{2 | byte(y.isOdd())}, xBytes[:]...)
+	}
+	yBytes := y.bytes()
+	return append(append([]byte{4}, xBytes[:]...), yBytes[:]...)
+}
@@ -0,0 +1,78 @@
+package secp256k1
+
+import "math/bits"
+
+// n is the order of the group.
+var n = limbs{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+
+var (
+	scalarModulus = newModulus(n)
+	// nMinus2 is the exponent of the inversions, by Fermat's little theorem.
+	nMinus2 = limbs{0xbfd25e8cd036413f, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+	// halfN is ⌊n/2⌋, the largest low s of an ECDSA signature.
+	halfN = limbs{0xdfe92f46681b20a0, 0x5d576e7357a4501d, 0xffffffffffffffff, 0x7fffffffffffffff}
+)
+
+// A scalar is an integer modulo n, in the Montgomery domain.
+type scalar struct {
+	l limbs
+}
+
+// setBytes sets s to the big-endian integer b modulo n, and reports whether b was below n. As
+// n > 2²⁵⁵, a single subtraction reduces any 256-bit integer.
+func (s *scalar) setBytes(b *[32]byte) bool {
+	canonical := scalarModulus.setBytes(&s.l, b)
+	scalarModulus.toMontgomery(&s.l, &s.l)
+	return canonical
+}
+
+// bytes returns the big-endian encoding of s.
+func (s *scalar) bytes() [32]byte {
+	var x limbs
+	scalarModulus.fromMontgomery(&x, &s.l)
+	return x.bytes()
+}
+
+func (s *scalar) add(x, y *scalar) *scalar {
+	scalarModulus.add(&s.l, &x.l, &y.l)
+	return s
+}
+
+func (s *scalar) neg(x *scalar) *scalar {
+	scalarModulus.sub(&s.l, &limbs{}, &x.l)
+	return s
+}
+
+func (s *scalar) mul(x, y *scalar) *scalar {
+	scalarModulus.mul(&s.l, &x.l, &y.l)
+	return s
+}
+
+// invert sets s = 1/x, and 0 if x is 0.
+func (s *scalar) invert(x *scalar) *scalar {
+	scalarModulus.exp(&s.l, &x.l, &nMinus2)
+	return s
+}
+
+// isZero returns 1 if s is 0 and 0 otherwise.
+func (s *scalar) isZero() uint64 {
+	return equalLimbs(&s.l, &limbs{})
+}
+
+// isHigh returns 1 if s > n/2 and 0 otherwise.
+func (s *scalar) isHigh() uint64 {
+	var x limbs
+	scalarModulus.fromMontgomery(&x, &s.l)
+	var borrow uint64
+	_, borrow = bits.Sub64(halfN[0], x[0], 0)
+	_, borrow = bits.Sub64(halfN[1], x[1], borrow)
+	_, borrow = bits.Sub64(halfN[2], x[2], borrow)
+	_, borrow = bits.Sub64(halfN[3], x[3], borrow)
+	return borrow
+}
+
+// selectFrom sets s = a if cond is 1 and s = b if it is 0.
+func (s *scalar) selectFrom(a, b *scalar, cond uint64) *scalar {
+	selectLimbs(&s.l, &a.l, &b.l, cond)
+	return s
+}
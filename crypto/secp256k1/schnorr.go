@@ -0,0 +1,97 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// SchnorrSignatureSize is the size of the BIP-340 signatures, the x-coordinate of R followed by s.
+const SchnorrSignatureSize = 64
+
+// taggedHash is hash_tag(x) of BIP-340, SHA-256(SHA-256(tag) || SHA-256(tag) || x).
+func taggedHash(tag string, x ...[]byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, b := range x {
+		h.Write(b)
+	}
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// SignSchnorr returns the BIP-340 signature of message, drawing the auxiliary random data from
+// crypto/rand. The signature verifies with the x-only public key of sk.
+func (sk *PrivateKey) SignSchnorr(message []byte) ([]byte, error) {
+	var auxRand [32]byte
+	if _, err := rand.Read(auxRand[:]); err != nil {
+		return nil, err
+	}
+	return sk.signSchnorr(message, &auxRand)
+}
+
+// signSchnorr is the signing algorithm of BIP-340 with the auxiliary random data auxRand.
+func (sk *PrivateKey) signSchnorr(message []byte, auxRand *[32]byte) ([]byte, error) {
+	// d is the private scalar of the point of P.x with an even y
+	var d, negD scalar
+	d.selectFrom(negD.neg(&sk.d), &sk.d, sk.pk.p.y.isOdd())
+	pX := sk.pk.p.x.bytes()
+
+	t := d.bytes()
+	auxHash := taggedHash("BIP0340/aux", auxRand[:])
+	for i := range t {
+		t[i] ^= auxHash[i]
+	}
+	nonce := taggedHash("BIP0340/nonce", t[:], pX[:], message)
+	var k scalar
+	k.setBytes(&nonce)
+	if k.isZero() == 1 {
+		return nil, errors.New("secp256k1: zero nonce")
+	}
+	var rPoint point
+	x, y := rPoint.scalarBaseMult(&k).affine()
+	var negK scalar
+	k.selectFrom(negK.neg(&k), &k, y.isOdd())
+	rX := x.bytes()
+
+	challenge := taggedHash("BIP0340/challenge", rX[:], pX[:], message)
+	var e, s scalar
+	e.setBytes(&challenge)
+	s.mul(&e, &d).add(&s, &k)
+	sBytes := s.bytes()
+	return append(rX[:], sBytes[:]...), nil
+}
+
+// VerifySchnorr reports whether signature is a valid BIP-340 signature of message by the x-only
+// public key of pk: only the x-coordinate of pk is used.
+func VerifySchnorr(pk *PublicKey, message, signature []byte) bool {
+	if len(signature) != SchnorrSignatureSize {
+		return false
+	}
+	pX := pk.p.x.bytes()
+	var p point
+	if err := p.liftX(&pX); err != nil {
+		return false
+	}
+	var r fieldElement
+	var s scalar
+	if !r.setBytes((*[32]byte)(signature[:32])) || !s.setBytes((*[32]byte)(signature[32:])) {
+		return false
+	}
+
+	// R = sG - eP
+	challenge := taggedHash("BIP0340/challenge", signature[:32], pX[:], message)
+	var e scalar
+	e.setBytes(&challenge)
+	e.neg(&e)
+	var rPoint point
+	rPoint.doubleScalarMultVartime(&s, &e, &p)
+	if rPoint.isIdentity() == 1 {
+		return false
+	}
+	x, y := rPoint.affine()
+	return y.isOdd() == 0 && x.equal(&r) == 1
+}
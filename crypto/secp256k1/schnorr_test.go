@@ -0,0 +1,93 @@
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testdata/bip-0340-test-vectors.csv holds the official test vectors of BIP-340, from
+// https://github.com/bitcoin/bips/blob/master/bip-0340/test-vectors.csv: signatures for the
+// vectors with a secret key, and verifications for all of them.
+func TestBIP340(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "bip-0340-test-vectors.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	for _, record := range records[1:] {
+		index, secretKey, publicKey, auxRand := record[0], decode(record[1]), decode(record[2]), decode(record[3])
+		message, signature, expected := decode(record[4]), decode(record[5]), record[6] == "TRUE"
+
+		if len(secretKey) > 0 {
+			sk, err := NewPrivateKey(secretKey)
+			if err != nil {
+				t.Fatalf("vector %s: %v", index, err)
+			}
+			if !bytes.Equal(sk.PublicKey().XOnlyBytes(), publicKey) {
+				t.Errorf("vector %s: expected public key %X, got %X", index, publicKey, sk.PublicKey().XOnlyBytes())
+			}
+			got, err := sk.signSchnorr(message, (*[32]byte)(auxRand))
+			if err != nil {
+				t.Fatalf("vector %s: %v", index, err)
+			}
+			if !bytes.Equal(got, signature) {
+				t.Errorf("vector %s: expected signature %X, got %X", index, signature, got)
+			}
+		}
+
+		valid := false
+		if pk, err := NewXOnlyPublicKey(publicKey); err == nil {
+			valid = VerifySchnorr(pk, message, signature)
+		}
+		if valid != expected {
+			t.Errorf("vector %s (%s): expected %v, got %v", index, record[7], expected, valid)
+		}
+	}
+}
+
+func TestSchnorr(t *testing.T) {
+	// a public key with an odd y, whose private key is negated to sign
+	var sk *PrivateKey
+	for sk == nil || sk.PublicKey().p.y.isOdd() == 0 {
+		var err error
+		if sk, err = GenerateKey(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	message := []byte("message")
+	signature, err := sk.SignSchnorr(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signature) != SchnorrSignatureSize {
+		t.Errorf("expected a %d-byte signature, got %d bytes", SchnorrSignatureSize, len(signature))
+	}
+	pk, err := NewXOnlyPublicKey(sk.PublicKey().XOnlyBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pk := range []*PublicKey{sk.PublicKey(), pk} {
+		if !VerifySchnorr(pk, message, signature) {
+			t.Error("valid signature rejected")
+		}
+		if VerifySchnorr(pk, []byte("other message"), signature) {
+			t.Error("signature of another message accepted")
+		}
+	}
+}
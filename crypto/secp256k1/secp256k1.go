@@ -0,0 +1,131 @@
+// Package secp256k1 implements the Koblitz curve secp256k1 of SEC 2 [1], with the ECDSA
+// signatures and public key recovery of Bitcoin and Ethereum, and the Schnorr signatures of
+// BIP-340 [2].
+//
+// The field and scalar arithmetic use 64-bit Montgomery multiplications, and the points the
+// complete projective formulas of [3]. Everything which handles private keys or nonces is constant
+// time, while the verifications, which only handle public values, use a faster variable-time
+// double scalar multiplication.
+//
+// ECDSA signatures are r || s with a low s, at most n/2, as required by Bitcoin (BIP-146) and
+// Ethereum (EIP-2): VerifyECDSA rejects the high-s ones, whose negation would be another valid
+// signature. Their nonces follow RFC 6979 with random additional data, so that they are hedged
+// against a broken random source.
+//
+// [1] https://www.secg.org/sec2-v2.pdf
+// [2] https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+// [3] https://eprint.iacr.org/2015/1060
+package secp256k1
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+const (
+	// PrivateKeySize is the size of the private keys, a big-endian scalar.
+	PrivateKeySize = 32
+	// PublicKeySize is the size of the compressed SEC 1 public keys.
+	PublicKeySize = 33
+	// UncompressedPublicKeySize is the size of the uncompressed SEC 1 public keys.
+	UncompressedPublicKeySize = 65
+	// XOnlyPublicKeySize is the size of the x-only public keys of BIP-340.
+	XOnlyPublicKeySize = 32
+)
+
+// A PublicKey is a secp256k1 public key, a point other than the identity.
+type PublicKey struct {
+	// p is in affine coordinates, with z = 1
+	p point
+}
+
+// A PrivateKey is a secp256k1 private key, a scalar in [1, n-1].
+type PrivateKey struct {
+	d  scalar
+	pk PublicKey
+}
+
+// GenerateKey generates a new private key, drawing random bytes from crypto/rand.
+func GenerateKey() (*PrivateKey, error) {
+	var b [PrivateKeySize]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, err
+		}
+		// the probability to retry is about 2⁻¹²⁸
+		if sk, err := NewPrivateKey(b[:]); err == nil {
+			return sk, nil
+		}
+	}
+}
+
+// NewPrivateKey decodes a big-endian private scalar, which must be in [1, n-1], and computes
+// its public key.
+func NewPrivateKey(privateKey []byte) (*PrivateKey, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, errors.New("secp256k1: invalid private key length")
+	}
+	sk := &PrivateKey{}
+	if !sk.d.setBytes((*[32]byte)(privateKey)) || sk.d.isZero() == 1 {
+		return nil, errors.New("secp256k1: invalid private key")
+	}
+	var p point
+	p.scalarBaseMult(&sk.d)
+	sk.pk.setPoint(&p)
+	return sk, nil
+}
+
+// setPoint sets pk to p, which isn't the identity, normalized to z = 1.
+func (pk *PublicKey) setPoint(p *point) {
+	x, y := p.affine()
+	pk.p = point{x: *x, y: *y, z: *newFieldElement(1)}
+}
+
+// NewPublicKey decodes a compressed or uncompressed SEC 1 public key.
+func NewPublicKey(publicKey []byte) (*PublicKey, error) {
+	pk := &PublicKey{}
+	if err := pk.p.setBytes(publicKey); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// NewXOnlyPublicKey decodes an x-only public key of BIP-340, the x-coordinate of the point with
+// an even y.
+func NewXOnlyPublicKey(publicKey []byte) (*PublicKey, error) {
+	if len(publicKey) != XOnlyPublicKeySize {
+		return nil, errors.New("secp256k1: invalid x-only public key length")
+	}
+	pk := &PublicKey{}
+	if err := pk.p.liftX((*[32]byte)(publicKey)); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// Bytes returns the encoded private key.
+func (sk *PrivateKey) Bytes() []byte {
+	b := sk.d.bytes()
+	return b[:]
+}
+
+// PublicKey returns the public key of the private key.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return &sk.pk
+}
+
+// Bytes returns the compressed SEC 1 encoding of the public key.
+func (pk *PublicKey) Bytes() []byte {
+	return pk.p.bytes(true)
+}
+
+// BytesUncompressed returns the uncompressed SEC 1 encoding of the public key.
+func (pk *PublicKey) BytesUncompressed() []byte {
+	return pk.p.bytes(false)
+}
+
+// XOnlyBytes returns the x-only encoding of BIP-340 of the public key.
+func (pk *PublicKey) XOnlyBytes() []byte {
+	x := pk.p.x.bytes()
+	return x[:]
+}
@@ -0,0 +1,196 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var (
+	bigP = new(big.Int).SetBytes(fromLimbs(&p))
+	bigN = new(big.Int).SetBytes(fromLimbs(&n))
+)
+
+func fromLimbs(x *limbs) []byte {
+	b := x.bytes()
+	return b[:]
+}
+
+// bigPoint is an affine point with math/big coordinates, nil for the identity, as a reference
+// implementation of the curve.
+type bigPoint struct {
+	x, y *big.Int
+}
+
+func bigAdd(p1, p2 *bigPoint) *bigPoint {
+	if p1 == nil {
+		return p2
+	}
+	if p2 == nil {
+		return p1
+	}
+	var lambda *big.Int
+	if p1.x.Cmp(p2.x) == 0 {
+		if p1.y.Cmp(p2.y) != 0 || p1.y.Sign() == 0 {
+			return nil
+		}
+		// λ = 3x² / 2y
+		lambda = new(big.Int).Mul(p1.x, p1.x)
+		lambda.Mul(lambda, big.NewInt(3))
+		lambda.Mul(lambda, new(big.Int).ModInverse(new(big.Int).Lsh(p1.y, 1), bigP))
+	} else {
+		// λ = (y2 - y1) / (x2 - x1)
+		lambda = new(big.Int).Sub(p2.y, p1.y)
+		lambda.Mul(lambda, new(big.Int).ModInverse(new(big.Int).Sub(p2.x, p1.x), bigP))
+	}
+	lambda.Mod(lambda, bigP)
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, p1.x).Sub(x, p2.x).Mod(x, bigP)
+	y := new(big.Int).Sub(p1.x, x)
+	y.Mul(y, lambda).Sub(y, p1.y).Mod(y, bigP)
+	return &bigPoint{x, y}
+}
+
+func bigScalarMult(k *big.Int, p *bigPoint) *bigPoint {
+	var result *bigPoint
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = bigAdd(result, result)
+		if k.Bit(i) == 1 {
+			result = bigAdd(result, p)
+		}
+	}
+	return result
+}
+
+func toBigPoint(t *testing.T, p *point) *bigPoint {
+	t.Helper()
+	if p.isIdentity() == 1 {
+		return nil
+	}
+	x, y := p.affine()
+	xBytes, yBytes := x.bytes(), y.bytes()
+	return &bigPoint{new(big.Int).SetBytes(xBytes[:]), new(big.Int).SetBytes(yBytes[:])}
+}
+
+func equalBigPoints(p1, p2 *bigPoint) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return p1.x.Cmp(p2.x) == 0 && p1.y.Cmp(p2.y) == 0
+}
+
+func newScalar(t *testing.T, k *big.Int) *scalar {
+	t.Helper()
+	var b [32]byte
+	k.FillBytes(b[:])
+	s := &scalar{}
+	if !s.setBytes(&b) {
+		t.Fatalf("%x isn't below n", k)
+	}
+	return s
+}
+
+func TestConstants(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		exponent limbs
+		expected *big.Int
+	}{
+		{"p-2", pMinus2, new(big.Int).Sub(bigP, big.NewInt(2))},
+		{"(p+1)/4", pPlus1Over4, new(big.Int).Rsh(new(big.Int).Add(bigP, big.NewInt(1)), 2)},
+		{"n-2", nMinus2, new(big.Int).Sub(bigN, big.NewInt(2))},
+		{"n/2", halfN, new(big.Int).Rsh(bigN, 1)},
+	} {
+		if got := new(big.Int).SetBytes(fromLimbs(&test.exponent)); got.Cmp(test.expected) != 0 {
+			t.Errorf("%s: expected %x, got %x", test.name, test.expected, got)
+		}
+	}
+}
+
+// TestScalarMult checks the scalar multiplications against the math/big reference, with random
+// and edge case scalars.
+func TestScalarMult(t *testing.T) {
+	g := newGeneratorPoint()
+	bigG := toBigPoint(t, g)
+	scalars := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		big.NewInt(15),
+		big.NewInt(16),
+		new(big.Int).Sub(bigN, big.NewInt(1)),
+		new(big.Int).Rsh(bigN, 1),
+	}
+	for range 16 {
+		k, err := rand.Int(rand.Reader, bigN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalars = append(scalars, k)
+	}
+
+	var q point
+	q.scalarBaseMult(newScalar(t, big.NewInt(7)))
+	bigQ := bigScalarMult(big.NewInt(7), bigG)
+	for _, k := range scalars {
+		expected := bigScalarMult(k, bigG)
+		var got point
+		if !equalBigPoints(toBigPoint(t, got.scalarBaseMult(newScalar(t, k))), expected) {
+			t.Errorf("scalarBaseMult(%x) mismatch", k)
+		}
+		if !equalBigPoints(toBigPoint(t, got.scalarMult(newScalar(t, k), g)), expected) {
+			t.Errorf("scalarMult(%x, G) mismatch", k)
+		}
+
+		// kG + (n-k)Q
+		other := new(big.Int).Sub(bigN, k)
+		other.Mod(other, bigN)
+		expected = bigAdd(expected, bigScalarMult(other, bigQ))
+		if !equalBigPoints(toBigPoint(t, got.doubleScalarMultVartime(newScalar(t, k), newScalar(t, other), &q)), expected) {
+			t.Errorf("doubleScalarMultVartime(%x, %x, Q) mismatch", k, other)
+		}
+	}
+
+	var identity point
+	if identity.scalarBaseMult(newScalar(t, new(big.Int).Sub(bigN, big.NewInt(1)))).add(&identity, g).isIdentity() != 1 {
+		t.Error("(n-1)G + G isn't the identity")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	sk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := NewPrivateKey(sk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.PublicKey().BytesUncompressed(), sk.PublicKey().BytesUncompressed()) {
+		t.Error("the decoded private key has another public key")
+	}
+	for _, encoded := range [][]byte{sk.PublicKey().Bytes(), sk.PublicKey().BytesUncompressed()} {
+		pk, err := NewPublicKey(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pk.BytesUncompressed(), sk.PublicKey().BytesUncompressed()) {
+			t.Errorf("%x decoded to another public key", encoded)
+		}
+	}
+
+	for _, invalid := range [][]byte{make([]byte, 32), fromLimbs(&n), bytes.Repeat([]byte{0xff}, 32), make([]byte, 31)} {
+		if _, err := NewPrivateKey(invalid); err == nil {
+			t.Errorf("invalid private key %x accepted", invalid)
+		}
+	}
+	offCurve := sk.PublicKey().BytesUncompressed()
+	offCurve[64] ^= 1
+	if _, err := NewPublicKey(offCurve); err == nil {
+		t.Error("point not on the curve accepted")
+	}
+	if _, err := NewPublicKey(append([]byte{2}, fromLimbs(&p)...)); err == nil {
+		t.Error("non-canonical x-coordinate accepted")
+	}
+}
@@ -0,0 +1,115 @@
+// Package siphash implements the SipHash-2-4 and SipHash-1-3 keyed hash functions [1].
+//
+// SipHash is a PRF optimized for short inputs. It is used to hash the keys of hash tables so
+// that an attacker who doesn't know the key can't craft inputs that collide, which would
+// degrade the table to a linked list (hash flooding). SipHash-1-3 is the reduced-round variant
+// used by Rust and CPython for this purpose.
+//
+// [1] https://www.aumasson.jp/siphash/siphash.pdf
+package siphash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// KeySize is the size in bytes of a SipHash key.
+const KeySize = 16
+
+// Key splits a 16-byte key into the two little-endian words used by Sum24 and Sum13.
+func Key(key []byte) (k0, k1 uint64) {
+	if len(key) != KeySize {
+		panic("siphash: bad key length")
+	}
+	return binary.LittleEndian.Uint64(key[0:8]), binary.LittleEndian.Uint64(key[8:16])
+}
+
+// Sum24 returns the SipHash-2-4 of p: 2 compression rounds per 8-byte block and 4 finalization
+// rounds.
+func Sum24(k0, k1 uint64, p []byte) uint64 {
+	v0, v1, v2, v3 := initState(k0, k1)
+	length := len(p)
+
+	for ; len(p) >= 8; p = p[8:] {
+		m := binary.LittleEndian.Uint64(p)
+		v3 ^= m
+		v0, v1, v2, v3 = round(v0, v1, v2, v3)
+		v0, v1, v2, v3 = round(v0, v1, v2, v3)
+		v0 ^= m
+	}
+
+	m := lastBlock(p, length)
+	v3 ^= m
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0 ^= m
+
+	v2 ^= 0xff
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// Sum13 returns the SipHash-1-3 of p: 1 compression round per 8-byte block and 3 finalization
+// rounds.
+func Sum13(k0, k1 uint64, p []byte) uint64 {
+	v0, v1, v2, v3 := initState(k0, k1)
+	length := len(p)
+
+	for ; len(p) >= 8; p = p[8:] {
+		m := binary.LittleEndian.Uint64(p)
+		v3 ^= m
+		v0, v1, v2, v3 = round(v0, v1, v2, v3)
+		v0 ^= m
+	}
+
+	m := lastBlock(p, length)
+	v3 ^= m
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0 ^= m
+
+	v2 ^= 0xff
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+func initState(k0, k1 uint64) (v0, v1, v2, v3 uint64) {
+	return k0 ^ 0x736f6d6570736575,
+		k1 ^ 0x646f72616e646f6d,
+		k0 ^ 0x6c7967656e657261,
+		k1 ^ 0x7465646279746573
+}
+
+// lastBlock returns the last block: the fewer than 8 bytes remaining after the full blocks, in
+// little-endian order, with the input length modulo 256 in the most significant byte.
+func lastBlock(tail []byte, length int) uint64 {
+	m := uint64(length) << 56
+	for i := len(tail) - 1; i >= 0; i-- {
+		m |= uint64(tail[i]) << (8 * i)
+	}
+	return m
+}
+
+func round(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13)
+	v1 ^= v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16)
+	v3 ^= v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21)
+	v3 ^= v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17)
+	v1 ^= v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
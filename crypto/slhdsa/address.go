@@ -0,0 +1,68 @@
+package slhdsa
+
+import "encoding/binary"
+
+// The address types of FIPS 205, Section 4.2.
+const (
+	addressWOTSHash = iota
+	addressWOTSPK
+	addressTree
+	addressFORSTree
+	addressFORSRoots
+	addressWOTSPRF
+	addressFORSPRF
+)
+
+// An address is the 32-byte ADRS of FIPS 205, Section 4.2, which domain-separates the calls to
+// the tweakable hash functions: the layer, the tree in the layer, the type of the address, and
+// three type-dependent words.
+type address [32]byte
+
+func (adrs *address) setLayer(layer int) {
+	binary.BigEndian.PutUint32(adrs[0:4], uint32(layer))
+}
+
+// setTree sets the index of the tree in its layer. It is 12 bytes long, of which only the last 8
+// are used by the parameter sets of FIPS 205.
+func (adrs *address) setTree(tree uint64) {
+	binary.BigEndian.PutUint64(adrs[8:16], tree)
+}
+
+// setTypeAndClear sets the type of the address and clears the three following words.
+func (adrs *address) setTypeAndClear(typ int) {
+	binary.BigEndian.PutUint32(adrs[16:20], uint32(typ))
+	clear(adrs[20:])
+}
+
+func (adrs *address) setKeyPair(keyPair uint32) {
+	binary.BigEndian.PutUint32(adrs[20:24], keyPair)
+}
+
+func (adrs *address) keyPair() uint32 {
+	return binary.BigEndian.Uint32(adrs[20:24])
+}
+
+func (adrs *address) setChain(chain int) {
+	binary.BigEndian.PutUint32(adrs[24:28], uint32(chain))
+}
+
+func (adrs *address) setTreeHeight(height int) {
+	binary.BigEndian.PutUint32(adrs[24:28], uint32(height))
+}
+
+func (adrs *address) setHash(hash int) {
+	binary.BigEndian.PutUint32(adrs[28:32], uint32(hash))
+}
+
+func (adrs *address) setTreeIndex(index uint32) {
+	binary.BigEndian.PutUint32(adrs[28:32], index)
+}
+
+// compress sets c to ADRSᶜ, the 22-byte address of the SHA2 instantiations of FIPS 205,
+// Section 11.2.
+func (adrs *address) compress(c *[22]byte) {
+	c[0] = adrs[3]
+	copy(c[1:9], adrs[8:16])
+	c[9] = adrs[19]
+	copy(c[10:], adrs[20:32])
+}
@@ -0,0 +1,92 @@
+package slhdsa
+
+// forsSize is the size of a FORS signature: for each of the k trees, a secret value and its
+// authentication path.
+func (params *Parameters) forsSize() int {
+	return params.k * (1 + params.a) * params.n
+}
+
+// forsSecret derives the secret value of the leaf index of the FORS key pair of adrs into out,
+// which is fors_skGen of FIPS 205, Algorithm 14.
+func (s *state) forsSecret(out []byte, index uint32, adrs *address) {
+	skADRS := &s.scratch
+	*skADRS = *adrs
+	skADRS.setTypeAndClear(addressFORSPRF)
+	skADRS.setKeyPair(adrs.keyPair())
+	skADRS.setTreeIndex(index)
+	s.h.prf(out, skADRS, s.skSeed)
+}
+
+// forsCompress computes the FORS public key of adrs from the roots of its trees.
+func (s *state) forsCompress(pk, roots []byte, adrs *address) {
+	pkADRS := &s.scratch
+	*pkADRS = *adrs
+	pkADRS.setTypeAndClear(addressFORSRoots)
+	pkADRS.setKeyPair(adrs.keyPair())
+	s.h.thash(pk, pkADRS, roots)
+}
+
+// forsSign is fors_sign of FIPS 205, Algorithm 16, which signs the message digest md into sig
+// and also returns the FORS public key into pk. As for the XMSS trees, each FORS tree is computed
+// level by level rather than by fors_node.
+func (s *state) forsSign(sig, pk, md []byte, adrs *address) {
+	n, a := s.params.n, s.params.a
+	nodes := make([]byte, n<<a)
+	roots := make([]byte, s.params.k*n)
+	for i, leaf := range base2b(md, a, s.params.k) {
+		treeSig := sig[i*(1+a)*n : (i+1)*(1+a)*n]
+		offset := uint32(i) << a
+		adrs.setTreeHeight(0)
+		for j := range uint32(1) << a {
+			x := nodes[int(j)*n : int(j+1)*n]
+			s.forsSecret(x, offset+j, adrs)
+			if j == leaf {
+				copy(treeSig, x)
+			}
+			adrs.setTreeIndex(offset + j)
+			s.h.thash(x, adrs, x)
+		}
+
+		for z := 1; z <= a; z++ {
+			sibling := int(leaf>>(z-1)^1) * n
+			copy(treeSig[z*n:(z+1)*n], nodes[sibling:sibling+n])
+			adrs.setTreeHeight(z)
+			for j := range uint32(1) << (a - z) {
+				adrs.setTreeIndex(offset>>z + j)
+				s.h.thash(nodes[int(j)*n:], adrs, nodes[2*int(j)*n:(2*int(j)+2)*n])
+			}
+		}
+		copy(roots[i*n:], nodes[:n])
+	}
+	s.forsCompress(pk, roots, adrs)
+}
+
+// forsPKFromSig is fors_pkFromSig of FIPS 205, Algorithm 17.
+func (s *state) forsPKFromSig(pk, sig, md []byte, adrs *address) {
+	n, a := s.params.n, s.params.a
+	roots := make([]byte, s.params.k*n)
+	buf := make([]byte, 2*n)
+	for i, leaf := range base2b(md, a, s.params.k) {
+		treeSig := sig[i*(1+a)*n : (i+1)*(1+a)*n]
+		index := uint32(i)<<a + leaf
+		node := roots[i*n : (i+1)*n]
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(index)
+		s.h.thash(node, adrs, treeSig[:n])
+
+		for j := range a {
+			auth := treeSig[(j+1)*n : (j+2)*n]
+			adrs.setTreeHeight(j + 1)
+			adrs.setTreeIndex(index >> (j + 1))
+			if leaf>>j&1 == 0 {
+				copy(buf, node)
+				copy(buf[n:], auth)
+			} else {
+				copy(buf, auth)
+				copy(buf[n:], node)
+			}
+			s.h.thash(node, adrs, buf)
+		}
+	}
+	s.forsCompress(pk, roots, adrs)
+}
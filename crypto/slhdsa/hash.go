@@ -0,0 +1,131 @@
+package slhdsa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding"
+	"encoding/binary"
+	"hash"
+)
+
+// A hasher is an instantiation of the hash functions of FIPS 205, Section 11, for a given PK.seed.
+// The outputs are n bytes long, except for hMsg whose output is m bytes long. The message M' is
+// passed as a prefix and the message, to avoid copying the message.
+type hasher interface {
+	// prfMsg is PRF_msg(SK.prf, opt_rand, M'), which computes the randomizer R.
+	prfMsg(out, skPRF, optRand, prefix, message []byte)
+	// hMsg is H_msg(R, PK.seed, PK.root, M'), which computes the message digest.
+	hMsg(out, r, pkRoot, prefix, message []byte)
+	// prf is PRF(PK.seed, SK.seed, ADRS), which derives the WOTS+ and FORS secret values.
+	prf(out []byte, adrs *address, skSeed []byte)
+	// thash is F, H and T_l(PK.seed, ADRS, in), which are the same function for the parameter
+	// sets of security category 1.
+	thash(out []byte, adrs *address, in []byte)
+}
+
+// shakeHasher is the SHAKE instantiation of FIPS 205, Section 11.1.
+type shakeHasher struct {
+	n      int
+	pkSeed []byte
+	h      *sha3.SHAKE
+}
+
+func newSHAKEHasher(n int, pkSeed []byte) *shakeHasher {
+	return &shakeHasher{n: n, pkSeed: pkSeed, h: sha3.NewSHAKE256()}
+}
+
+func (s *shakeHasher) prfMsg(out, skPRF, optRand, prefix, message []byte) {
+	s.h.Reset()
+	s.h.Write(skPRF)
+	s.h.Write(optRand)
+	s.h.Write(prefix)
+	s.h.Write(message)
+	s.h.Read(out[:s.n])
+}
+
+func (s *shakeHasher) hMsg(out, r, pkRoot, prefix, message []byte) {
+	s.h.Reset()
+	s.h.Write(r)
+	s.h.Write(s.pkSeed)
+	s.h.Write(pkRoot)
+	s.h.Write(prefix)
+	s.h.Write(message)
+	s.h.Read(out)
+}
+
+func (s *shakeHasher) prf(out []byte, adrs *address, skSeed []byte) {
+	s.h.Reset()
+	s.h.Write(s.pkSeed)
+	s.h.Write(adrs[:])
+	s.h.Write(skSeed)
+	s.h.Read(out[:s.n])
+}
+
+func (s *shakeHasher) thash(out []byte, adrs *address, in []byte) {
+	s.h.Reset()
+	s.h.Write(s.pkSeed)
+	s.h.Write(adrs[:])
+	s.h.Write(in)
+	s.h.Read(out[:s.n])
+}
+
+// sha2Hasher is the SHA2 instantiation of FIPS 205, Section 11.2.1, for security category 1.
+// PK.seed is padded to a full SHA-256 block, so the state after that block is computed once and
+// restored at each call of prf and thash.
+type sha2Hasher struct {
+	n      int
+	pkSeed []byte
+	h      hash.Hash
+	seeded []byte
+	adrsc  [22]byte
+	sum    [sha256.Size]byte
+}
+
+func newSHA2Hasher(n int, pkSeed []byte) *sha2Hasher {
+	s := &sha2Hasher{n: n, pkSeed: pkSeed, h: sha256.New()}
+	s.h.Write(pkSeed)
+	s.h.Write(make([]byte, sha256.BlockSize-n))
+	s.seeded, _ = s.h.(encoding.BinaryMarshaler).MarshalBinary()
+	return s
+}
+
+func (s *sha2Hasher) prfMsg(out, skPRF, optRand, prefix, message []byte) {
+	mac := hmac.New(sha256.New, skPRF)
+	mac.Write(optRand)
+	mac.Write(prefix)
+	mac.Write(message)
+	copy(out[:s.n], mac.Sum(s.sum[:0]))
+}
+
+// hMsg is MGF1-SHA-256(R || PK.seed || SHA-256(R || PK.seed || PK.root || M'), m).
+func (s *sha2Hasher) hMsg(out, r, pkRoot, prefix, message []byte) {
+	h := sha256.New()
+	h.Write(r)
+	h.Write(s.pkSeed)
+	h.Write(pkRoot)
+	h.Write(prefix)
+	h.Write(message)
+	seed := h.Sum(append(append([]byte{}, r...), s.pkSeed...))
+
+	var counter [4]byte
+	for i := uint32(0); len(out) > 0; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		out = out[copy(out, h.Sum(s.sum[:0])):]
+	}
+}
+
+func (s *sha2Hasher) prf(out []byte, adrs *address, skSeed []byte) {
+	s.thash(out, adrs, skSeed)
+}
+
+func (s *sha2Hasher) thash(out []byte, adrs *address, in []byte) {
+	s.h.(encoding.BinaryUnmarshaler).UnmarshalBinary(s.seeded)
+	adrs.compress(&s.adrsc)
+	s.h.Write(s.adrsc[:])
+	s.h.Write(in)
+	copy(out[:s.n], s.h.Sum(s.sum[:0]))
+}
@@ -0,0 +1,286 @@
+// Package slhdsa implements SLH-DSA, the Stateless Hash-Based Digital Signature Algorithm of
+// FIPS 205 [1], with the parameter sets of security category 1: SLH-DSA-SHA2-128s,
+// SLH-DSA-SHAKE-128s, SLH-DSA-SHA2-128f and SLH-DSA-SHAKE-128f.
+//
+// SLH-DSA is SPHINCS+: a hypertree of XMSS trees of WOTS+ one-time signatures signs the public key
+// of a FORS few-time signature of the message. Its security only relies on the hash functions.
+// The "s" parameter sets have smaller signatures and the "f" ones faster signing.
+//
+// Only the pure variant is implemented, HashSLH-DSA isn't. Signatures are hedged by default, and
+// can also be deterministic.
+//
+// [1] https://nvlpubs.nist.gov/nistpubs/FIPS/NIST.FIPS.205.pdf
+package slhdsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+)
+
+// MaxContextSize is the maximum size of a context string.
+const MaxContextSize = 255
+
+// Parameters are an SLH-DSA parameter set.
+type Parameters struct {
+	name string
+	// n is the security parameter, the size of the hashes
+	n int
+	// h is the height of the hypertree, made of d layers of XMSS trees of height hPrime
+	h, d, hPrime int
+	// the FORS signatures are made of k trees of height a
+	a, k int
+	// m is the size of the message digest
+	m    int
+	sha2 bool
+}
+
+// The parameter sets of FIPS 205, Table 2, of security category 1.
+var (
+	SHA2_128s  = &Parameters{name: "SLH-DSA-SHA2-128s", n: 16, h: 63, d: 7, hPrime: 9, a: 12, k: 14, m: 30, sha2: true}
+	SHAKE_128s = &Parameters{name: "SLH-DSA-SHAKE-128s", n: 16, h: 63, d: 7, hPrime: 9, a: 12, k: 14, m: 30}
+	SHA2_128f  = &Parameters{name: "SLH-DSA-SHA2-128f", n: 16, h: 66, d: 22, hPrime: 3, a: 6, k: 33, m: 34, sha2: true}
+	SHAKE_128f = &Parameters{name: "SLH-DSA-SHAKE-128f", n: 16, h: 66, d: 22, hPrime: 3, a: 6, k: 33, m: 34}
+)
+
+// String returns the name of the parameter set, e.g. SLH-DSA-SHA2-128s.
+func (params *Parameters) String() string {
+	return params.name
+}
+
+// SeedSize returns the size of the seeds of the private keys: SK.seed, SK.prf and PK.seed.
+func (params *Parameters) SeedSize() int {
+	return 3 * params.n
+}
+
+// PrivateKeySize returns the size of the encoded private keys: the seeds and PK.root.
+func (params *Parameters) PrivateKeySize() int {
+	return 4 * params.n
+}
+
+// PublicKeySize returns the size of the encoded public keys: PK.seed and PK.root.
+func (params *Parameters) PublicKeySize() int {
+	return 2 * params.n
+}
+
+// SignatureSize returns the size of the signatures: the randomizer R, the FORS signature and the
+// hypertree signature.
+func (params *Parameters) SignatureSize() int {
+	return params.n + params.forsSize() + params.d*params.xmssSize()
+}
+
+// state holds the hash functions of a key and scratch space, for one operation.
+type state struct {
+	params *Parameters
+	h      hasher
+	skSeed []byte
+	// wotsBuf holds the WOTS+ chains
+	wotsBuf []byte
+	// scratch is the address of the PRF and public key compression calls, which would escape
+	// to the heap as a local variable
+	scratch address
+}
+
+func (params *Parameters) newState(pkSeed, skSeed []byte) *state {
+	s := &state{params: params, skSeed: skSeed, wotsBuf: make([]byte, params.wotsLen()*params.n)}
+	if params.sha2 {
+		s.h = newSHA2Hasher(params.n, pkSeed)
+	} else {
+		s.h = newSHAKEHasher(params.n, pkSeed)
+	}
+	return s
+}
+
+// A PublicKey is an SLH-DSA public key.
+type PublicKey struct {
+	params *Parameters
+	seed   []byte
+	root   []byte
+}
+
+// A PrivateKey is an SLH-DSA private key.
+type PrivateKey struct {
+	params *Parameters
+	seed   []byte
+	prf    []byte
+	pk     PublicKey
+}
+
+// GenerateKey generates a new private key, drawing random bytes from crypto/rand.
+func GenerateKey(params *Parameters) (*PrivateKey, error) {
+	seed := make([]byte, params.SeedSize())
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return NewPrivateKeyFromSeed(params, seed)
+}
+
+// NewPrivateKeyFromSeed derives a private key from SK.seed || SK.prf || PK.seed, which is
+// slh_keygen_internal of FIPS 205, Algorithm 18. It computes the root of the top XMSS tree, and
+// is as slow as GenerateKey.
+func NewPrivateKeyFromSeed(params *Parameters, seed []byte) (*PrivateKey, error) {
+	if len(seed) != params.SeedSize() {
+		return nil, errors.New("slhdsa: invalid seed length")
+	}
+	n := params.n
+	seed = bytes.Clone(seed)
+	sk := &PrivateKey{
+		params: params,
+		seed:   seed[:n],
+		prf:    seed[n : 2*n],
+		pk:     PublicKey{params: params, seed: seed[2*n:], root: make([]byte, n)},
+	}
+	var adrs address
+	adrs.setLayer(params.d - 1)
+	params.newState(sk.pk.seed, sk.seed).xmssTree(sk.pk.root, 0, nil, &adrs)
+	return sk, nil
+}
+
+// NewPrivateKey decodes an encoded private key. PK.root isn't checked against the seeds, which
+// would cost a key generation.
+func NewPrivateKey(params *Parameters, privateKey []byte) (*PrivateKey, error) {
+	if len(privateKey) != params.PrivateKeySize() {
+		return nil, errors.New("slhdsa: invalid private key length")
+	}
+	n := params.n
+	privateKey = bytes.Clone(privateKey)
+	return &PrivateKey{
+		params: params,
+		seed:   privateKey[:n],
+		prf:    privateKey[n : 2*n],
+		pk:     PublicKey{params: params, seed: privateKey[2*n : 3*n], root: privateKey[3*n:]},
+	}, nil
+}
+
+// NewPublicKey decodes an encoded public key.
+func NewPublicKey(params *Parameters, publicKey []byte) (*PublicKey, error) {
+	if len(publicKey) != params.PublicKeySize() {
+		return nil, errors.New("slhdsa: invalid public key length")
+	}
+	n := params.n
+	publicKey = bytes.Clone(publicKey)
+	return &PublicKey{params: params, seed: publicKey[:n], root: publicKey[n:]}, nil
+}
+
+// Bytes returns the encoded private key, SK.seed || SK.prf || PK.seed || PK.root.
+func (sk *PrivateKey) Bytes() []byte {
+	return bytes.Join([][]byte{sk.seed, sk.prf, sk.pk.seed, sk.pk.root}, nil)
+}
+
+// PublicKey returns the public key of the private key.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return &sk.pk
+}
+
+// Bytes returns the encoded public key, PK.seed || PK.root.
+func (pk *PublicKey) Bytes() []byte {
+	return bytes.Join([][]byte{pk.seed, pk.root}, nil)
+}
+
+// Parameters returns the parameter set of the public key.
+func (pk *PublicKey) Parameters() *Parameters {
+	return pk.params
+}
+
+// domainPrefix returns the prefix of the message of the pure SLH-DSA signatures,
+// 0 || |ctx| || ctx.
+func domainPrefix(context []byte) ([]byte, error) {
+	if len(context) > MaxContextSize {
+		return nil, errors.New("slhdsa: context too long")
+	}
+	return append([]byte{0, byte(len(context))}, context...), nil
+}
+
+// Sign returns a hedged signature of message with the context string context, which is at most
+// MaxContextSize bytes and must be the same to verify the signature. It is slh_sign of FIPS 205,
+// Algorithm 22, drawing opt_rand from crypto/rand.
+func (sk *PrivateKey) Sign(message, context []byte) ([]byte, error) {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return nil, err
+	}
+	optRand := make([]byte, sk.params.n)
+	if _, err := rand.Read(optRand); err != nil {
+		return nil, err
+	}
+	return sk.signInternal(prefix, message, optRand), nil
+}
+
+// SignDeterministic is the deterministic variant of Sign, for which opt_rand is PK.seed.
+func (sk *PrivateKey) SignDeterministic(message, context []byte) ([]byte, error) {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return nil, err
+	}
+	return sk.signInternal(prefix, message, sk.pk.seed), nil
+}
+
+// splitDigest splits the message digest into the FORS message and the indexes of the XMSS tree
+// and of its leaf which sign the FORS public key.
+func (params *Parameters) splitDigest(digest []byte) (md []byte, idxTree uint64, idxLeaf uint32) {
+	mdSize := (params.k*params.a + 7) / 8
+	treeSize := (params.h - params.hPrime + 7) / 8
+	md, digest = digest[:mdSize], digest[mdSize:]
+	for _, b := range digest[:treeSize] {
+		idxTree = idxTree<<8 | uint64(b)
+	}
+	for _, b := range digest[treeSize:] {
+		idxLeaf = idxLeaf<<8 | uint32(b)
+	}
+	idxTree &= 1<<(params.h-params.hPrime) - 1
+	idxLeaf &= 1<<params.hPrime - 1
+	return md, idxTree, idxLeaf
+}
+
+// signInternal is slh_sign_internal of FIPS 205, Algorithm 19, for M' = prefix || message.
+func (sk *PrivateKey) signInternal(prefix, message, optRand []byte) []byte {
+	params := sk.params
+	n := params.n
+	s := params.newState(sk.pk.seed, sk.seed)
+	signature := make([]byte, params.SignatureSize())
+	r := signature[:n]
+	s.h.prfMsg(r, sk.prf, optRand, prefix, message)
+	digest := make([]byte, params.m)
+	s.h.hMsg(digest, r, sk.pk.root, prefix, message)
+	md, idxTree, idxLeaf := params.splitDigest(digest)
+
+	var adrs address
+	adrs.setTree(idxTree)
+	adrs.setTypeAndClear(addressFORSTree)
+	adrs.setKeyPair(idxLeaf)
+	pkFORS := make([]byte, n)
+	s.forsSign(signature[n:n+params.forsSize()], pkFORS, md, &adrs)
+	s.htSign(signature[n+params.forsSize():], pkFORS, idxTree, idxLeaf)
+	return signature
+}
+
+// Verify reports whether signature is a valid signature of message with the context string
+// context by pk. It is slh_verify of FIPS 205, Algorithm 24.
+func Verify(pk *PublicKey, message, signature, context []byte) bool {
+	prefix, err := domainPrefix(context)
+	if err != nil {
+		return false
+	}
+	return pk.verifyInternal(prefix, message, signature)
+}
+
+// verifyInternal is slh_verify_internal of FIPS 205, Algorithm 20, for M' = prefix || message.
+func (pk *PublicKey) verifyInternal(prefix, message, signature []byte) bool {
+	params := pk.params
+	n := params.n
+	if len(signature) != params.SignatureSize() {
+		return false
+	}
+	s := params.newState(pk.seed, nil)
+	digest := make([]byte, params.m)
+	s.h.hMsg(digest, signature[:n], pk.root, prefix, message)
+	md, idxTree, idxLeaf := params.splitDigest(digest)
+
+	var adrs address
+	adrs.setTree(idxTree)
+	adrs.setTypeAndClear(addressFORSTree)
+	adrs.setKeyPair(idxLeaf)
+	pkFORS := make([]byte, n)
+	s.forsPKFromSig(pkFORS, signature[n:n+params.forsSize()], md, &adrs)
+	return s.htVerify(pkFORS, signature[n+params.forsSize():], idxTree, idxLeaf, pk.root)
+}
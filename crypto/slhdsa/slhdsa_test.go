@@ -0,0 +1,205 @@
+package slhdsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+var parameterSets = []*Parameters{SHA2_128s, SHAKE_128s, SHA2_128f, SHAKE_128f}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	*h = decoded
+	return err
+}
+
+// testdata/vectors.json.gz holds ACVP sample vectors of the security category 1 parameter sets,
+// from sign/slhdsa/testdata of github.com/cloudflare/circl v1.6.5, which are those of
+// https://github.com/usnistgov/ACVP-Server: all the keyGen vectors, the first test of each sigGen
+// group and one valid and two invalid signatures of each sigVer group, without the HashSLH-DSA
+// groups. The prompts and the expected results are merged. For the internal interface, the
+// message is M'.
+type vectors struct {
+	KeyGen []struct {
+		ParameterSet string   `json:"parameterSet"`
+		SKSeed       hexBytes `json:"skSeed"`
+		SKPRF        hexBytes `json:"skPrf"`
+		PKSeed       hexBytes `json:"pkSeed"`
+		SK           hexBytes `json:"sk"`
+		PK           hexBytes `json:"pk"`
+	} `json:"keyGen"`
+	SigGen []struct {
+		ParameterSet         string   `json:"parameterSet"`
+		Interface            string   `json:"interface"`
+		SK                   hexBytes `json:"sk"`
+		Message              hexBytes `json:"message"`
+		Context              hexBytes `json:"context"`
+		AdditionalRandomness hexBytes `json:"additionalRandomness"`
+		Signature            hexBytes `json:"signature"`
+	} `json:"sigGen"`
+	SigVer []struct {
+		ParameterSet string   `json:"parameterSet"`
+		Interface    string   `json:"interface"`
+		PK           hexBytes `json:"pk"`
+		Message      hexBytes `json:"message"`
+		Context      hexBytes `json:"context"`
+		Signature    hexBytes `json:"signature"`
+		TestPassed   bool     `json:"testPassed"`
+	} `json:"sigVer"`
+}
+
+func readVectors(t *testing.T) *vectors {
+	t.Helper()
+	f, err := os.Open("testdata/vectors.json.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := new(vectors)
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func parametersByName(t *testing.T, name string) *Parameters {
+	t.Helper()
+	for _, params := range parameterSets {
+		if params.String() == name {
+			return params
+		}
+	}
+	t.Fatalf("unknown parameter set %s", name)
+	return nil
+}
+
+func TestACVPKeyGen(t *testing.T) {
+	for i, v := range readVectors(t).KeyGen {
+		params := parametersByName(t, v.ParameterSet)
+		seed := bytes.Join([][]byte{v.SKSeed, v.SKPRF, v.PKSeed}, nil)
+		sk, err := NewPrivateKeyFromSeed(params, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sk.Bytes(), v.SK) {
+			t.Errorf("#%d %s: wrong private key", i, params)
+		}
+		if !bytes.Equal(sk.PublicKey().Bytes(), v.PK) {
+			t.Errorf("#%d %s: wrong public key", i, params)
+		}
+	}
+}
+
+func TestACVPSigGen(t *testing.T) {
+	for i, v := range readVectors(t).SigGen {
+		params := parametersByName(t, v.ParameterSet)
+		sk, err := NewPrivateKey(params, v.SK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var prefix []byte
+		if v.Interface == "external" {
+			if prefix, err = domainPrefix(v.Context); err != nil {
+				t.Fatal(err)
+			}
+		}
+		optRand := []byte(v.AdditionalRandomness)
+		if optRand == nil {
+			optRand = sk.pk.seed
+		}
+		signature := sk.signInternal(prefix, v.Message, optRand)
+		if !bytes.Equal(signature, v.Signature) {
+			t.Errorf("#%d %s %s: wrong signature", i, params, v.Interface)
+		}
+		if !sk.PublicKey().verifyInternal(prefix, v.Message, signature) {
+			t.Errorf("#%d %s %s: signature rejected", i, params, v.Interface)
+		}
+	}
+}
+
+func TestACVPSigVer(t *testing.T) {
+	for i, v := range readVectors(t).SigVer {
+		params := parametersByName(t, v.ParameterSet)
+		pk, err := NewPublicKey(params, v.PK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var valid bool
+		if v.Interface == "external" {
+			valid = Verify(pk, v.Message, v.Signature, v.Context)
+		} else {
+			valid = pk.verifyInternal(nil, v.Message, v.Signature)
+		}
+		if valid != v.TestPassed {
+			t.Errorf("#%d %s %s: expected %v, got %v", i, params, v.Interface, v.TestPassed, valid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	message := []byte("message")
+	context := []byte("context")
+	for _, params := range parameterSets {
+		t.Run(params.String(), func(t *testing.T) {
+			sk, err := GenerateKey(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := NewPrivateKey(params, sk.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, err := NewPublicKey(params, sk.PublicKey().Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signature, err := parsed.Sign(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(signature) != params.SignatureSize() {
+				t.Errorf("expected a %d-byte signature, got %d bytes", params.SignatureSize(), len(signature))
+			}
+			if !Verify(pk, message, signature, context) {
+				t.Error("valid signature rejected")
+			}
+			if Verify(pk, message, signature, nil) {
+				t.Error("signature accepted with another context")
+			}
+			if Verify(pk, []byte("other message"), signature, context) {
+				t.Error("signature of another message accepted")
+			}
+			signature[len(signature)-1] ^= 1
+			if Verify(pk, message, signature, context) {
+				t.Error("corrupted signature accepted")
+			}
+
+			deterministic, err := sk.SignDeterministic(message, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, _ := sk.SignDeterministic(message, context)
+			if !bytes.Equal(deterministic, again) {
+				t.Error("deterministic signatures differ")
+			}
+			if !Verify(pk, message, deterministic, context) {
+				t.Error("valid deterministic signature rejected")
+			}
+
+			if _, err := sk.Sign(message, make([]byte, MaxContextSize+1)); err == nil {
+				t.Error("context too long accepted")
+			}
+		})
+	}
+}
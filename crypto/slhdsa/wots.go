@@ -0,0 +1,102 @@
+package slhdsa
+
+// w is the Winternitz parameter of all the parameter sets of FIPS 205, 2^lg_w with lg_w = 4.
+const w = 16
+
+// wotsLen is len of FIPS 205: 2n base-16 digits of the message and 3 of the checksum.
+func (params *Parameters) wotsLen() int {
+	return 2*params.n + 3
+}
+
+// wotsDigits returns the base-16 digits of msg followed by those of its checksum, the lengths of
+// the WOTS+ chains of the signature of msg.
+func wotsDigits(msg []byte) []uint32 {
+	digits := base2b(msg, 4, 2*len(msg))
+	var checksum uint32
+	for _, digit := range digits {
+		checksum += w - 1 - digit
+	}
+	// the checksum is shifted left by 4 bits and encoded on 2 bytes, of which the first 3
+	// digits are used: these are its own 3 digits
+	return append(digits, checksum>>8&0xf, checksum>>4&0xf, checksum&0xf)
+}
+
+// chain is chain of FIPS 205, Algorithm 5: it applies F steps times to x in place, starting at
+// position start of the chain.
+func (s *state) chain(x []byte, start, steps uint32, adrs *address) {
+	for j := start; j < start+steps; j++ {
+		adrs.setHash(int(j))
+		s.h.thash(x, adrs, x)
+	}
+}
+
+// wotsSecret derives the secret value of chain i of the WOTS+ key pair of adrs into out.
+func (s *state) wotsSecret(out []byte, i int, adrs *address) {
+	skADRS := &s.scratch
+	*skADRS = *adrs
+	skADRS.setTypeAndClear(addressWOTSPRF)
+	skADRS.setKeyPair(adrs.keyPair())
+	skADRS.setChain(i)
+	s.h.prf(out, skADRS, s.skSeed)
+}
+
+// wotsCompress computes the WOTS+ public key of adrs from the ends of its chains.
+func (s *state) wotsCompress(pk, ends []byte, adrs *address) {
+	pkADRS := &s.scratch
+	*pkADRS = *adrs
+	pkADRS.setTypeAndClear(addressWOTSPK)
+	pkADRS.setKeyPair(adrs.keyPair())
+	s.h.thash(pk, pkADRS, ends)
+}
+
+// wotsPKGen is wots_pkGen of FIPS 205, Algorithm 6.
+func (s *state) wotsPKGen(pk []byte, adrs *address) {
+	n := s.params.n
+	for i := range s.params.wotsLen() {
+		x := s.wotsBuf[i*n : (i+1)*n]
+		s.wotsSecret(x, i, adrs)
+		adrs.setChain(i)
+		s.chain(x, 0, w-1, adrs)
+	}
+	s.wotsCompress(pk, s.wotsBuf, adrs)
+}
+
+// wotsSign is wots_sign of FIPS 205, Algorithm 7, which signs the n-byte msg into sig.
+func (s *state) wotsSign(sig, msg []byte, adrs *address) {
+	n := s.params.n
+	for i, digit := range wotsDigits(msg) {
+		x := sig[i*n : (i+1)*n]
+		s.wotsSecret(x, i, adrs)
+		adrs.setChain(i)
+		s.chain(x, 0, digit, adrs)
+	}
+}
+
+// wotsPKFromSig is wots_pkFromSig of FIPS 205, Algorithm 8.
+func (s *state) wotsPKFromSig(pk, sig, msg []byte, adrs *address) {
+	n := s.params.n
+	copy(s.wotsBuf, sig)
+	for i, digit := range wotsDigits(msg) {
+		adrs.setChain(i)
+		s.chain(s.wotsBuf[i*n:(i+1)*n], digit, w-1-digit, adrs)
+	}
+	s.wotsCompress(pk, s.wotsBuf, adrs)
+}
+
+// base2b is base_2b of FIPS 205, Algorithm 4: the first outLen b-bit integers of x, most
+// significant bits first.
+func base2b(x []byte, b, outLen int) []uint32 {
+	out := make([]uint32, outLen)
+	var total uint64
+	bits := 0
+	for i := range out {
+		for bits < b {
+			total = total<<8 | uint64(x[0])
+			x = x[1:]
+			bits += 8
+		}
+		bits -= b
+		out[i] = uint32(total>>bits) & (1<<b - 1)
+	}
+	return out
+}
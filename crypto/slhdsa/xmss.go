@@ -0,0 +1,109 @@
+package slhdsa
+
+// xmssTree computes the XMSS tree of the layer and tree of adrs into root, which is xmss_node of
+// FIPS 205, Algorithm 9, for the root. If authPath is not nil, it also computes the
+// authentication path of leaf idx, as xmss_sign does. The whole tree is computed level by level
+// instead of recursively, so that each node is computed once.
+func (s *state) xmssTree(root []byte, idx uint32, authPath []byte, adrs *address) {
+	n, height := s.params.n, s.params.hPrime
+	nodes := make([]byte, n<<height)
+	adrs.setTypeAndClear(addressWOTSHash)
+	for i := range uint32(1) << height {
+		adrs.setKeyPair(i)
+		s.wotsPKGen(nodes[int(i)*n:], adrs)
+	}
+
+	adrs.setTypeAndClear(addressTree)
+	for z := 1; z <= height; z++ {
+		if authPath != nil {
+			sibling := int(idx>>(z-1)^1) * n
+			copy(authPath[(z-1)*n:z*n], nodes[sibling:sibling+n])
+		}
+		adrs.setTreeHeight(z)
+		for i := range uint32(1) << (height - z) {
+			adrs.setTreeIndex(i)
+			// the node i of this level overwrites a node of the previous level which has been
+			// consumed already
+			s.h.thash(nodes[int(i)*n:], adrs, nodes[2*int(i)*n:(2*int(i)+2)*n])
+		}
+	}
+	copy(root, nodes[:n])
+}
+
+// xmssSize is the size of an XMSS signature: the WOTS+ signature and the authentication path.
+func (params *Parameters) xmssSize() int {
+	return (params.wotsLen() + params.hPrime) * params.n
+}
+
+// xmssSign is xmss_sign of FIPS 205, Algorithm 10, which also returns the root of the tree.
+func (s *state) xmssSign(sig, root, msg []byte, idx uint32, adrs *address) {
+	wotsSize := s.params.wotsLen() * s.params.n
+	s.xmssTree(root, idx, sig[wotsSize:], adrs)
+	adrs.setTypeAndClear(addressWOTSHash)
+	adrs.setKeyPair(idx)
+	s.wotsSign(sig[:wotsSize], msg, adrs)
+}
+
+// xmssPKFromSig is xmss_pkFromSig of FIPS 205, Algorithm 11, which computes the root of the tree
+// from the signature sig of msg by leaf idx. root and msg may be the same buffer.
+func (s *state) xmssPKFromSig(root []byte, idx uint32, sig, msg []byte, adrs *address) {
+	n := s.params.n
+	wotsSize := s.params.wotsLen() * n
+	adrs.setTypeAndClear(addressWOTSHash)
+	adrs.setKeyPair(idx)
+	s.wotsPKFromSig(root, sig[:wotsSize], msg, adrs)
+
+	adrs.setTypeAndClear(addressTree)
+	buf := make([]byte, 2*n)
+	for k := range s.params.hPrime {
+		adrs.setTreeHeight(k + 1)
+		adrs.setTreeIndex(idx >> (k + 1))
+		auth := sig[wotsSize+k*n : wotsSize+(k+1)*n]
+		if idx>>k&1 == 0 {
+			copy(buf, root)
+			copy(buf[n:], auth)
+		} else {
+			copy(buf, auth)
+			copy(buf[n:], root)
+		}
+		s.h.thash(root, adrs, buf)
+	}
+}
+
+// htSign is ht_sign of FIPS 205, Algorithm 12: each layer signs the root of the tree below it,
+// which is known from the computation of its authentication path.
+func (s *state) htSign(sig, msg []byte, idxTree uint64, idxLeaf uint32) {
+	n, hPrime := s.params.n, s.params.hPrime
+	xmssSize := s.params.xmssSize()
+	var adrs address
+	adrs.setTree(idxTree)
+	root := make([]byte, n)
+	s.xmssSign(sig[:xmssSize], root, msg, idxLeaf, &adrs)
+	msg = make([]byte, n)
+	for j := 1; j < s.params.d; j++ {
+		root, msg = msg, root
+		idxLeaf = uint32(idxTree & (1<<hPrime - 1))
+		idxTree >>= hPrime
+		adrs.setLayer(j)
+		adrs.setTree(idxTree)
+		s.xmssSign(sig[j*xmssSize:(j+1)*xmssSize], root, msg, idxLeaf, &adrs)
+	}
+}
+
+// htVerify is ht_verify of FIPS 205, Algorithm 13.
+func (s *state) htVerify(msg, sig []byte, idxTree uint64, idxLeaf uint32, pkRoot []byte) bool {
+	n, hPrime := s.params.n, s.params.hPrime
+	xmssSize := s.params.xmssSize()
+	var adrs address
+	adrs.setTree(idxTree)
+	node := make([]byte, n)
+	s.xmssPKFromSig(node, idxLeaf, sig[:xmssSize], msg, &adrs)
+	for j := 1; j < s.params.d; j++ {
+		idxLeaf = uint32(idxTree & (1<<hPrime - 1))
+		idxTree >>= hPrime
+		adrs.setLayer(j)
+		adrs.setTree(idxTree)
+		s.xmssPKFromSig(node, idxLeaf, sig[j*xmssSize:(j+1)*xmssSize], node, &adrs)
+	}
+	return string(node) == string(pkRoot)
+}
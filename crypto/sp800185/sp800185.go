@@ -0,0 +1,214 @@
+// Package sp800185 implements the cSHAKE, TupleHash and ParallelHash functions of
+// NIST SP 800-185 [1]. KMAC lives in the kmac package, whose encoding helpers are used here.
+//
+// [1] https://nvlpubs.nist.gov/nistpubs/SpecialPublications/NIST.SP.800-185.pdf
+package sp800185
+
+import (
+	"crypto/sha3"
+	"runtime"
+	"sync"
+
+	"github.com/skerkour/go-benchmarks/crypto/kmac"
+)
+
+const (
+	// used to define functions based on cSHAKE
+	tupleHashFunctionName    = "TupleHash"
+	parallelHashFunctionName = "ParallelHash"
+)
+
+// SumCSHAKE128 returns outputLen bytes of cSHAKE128 of data with the given function name and
+// customization string. With both empty, it is SHAKE128.
+//
+// specified in 3 of [1].
+func SumCSHAKE128(data []byte, outputLen int, functionName, customization []byte) []byte {
+	c := sha3.NewCSHAKE128(functionName, customization)
+	c.Write(data)
+	out := make([]byte, outputLen)
+	c.Read(out)
+	return out
+}
+
+// SumCSHAKE256 returns outputLen bytes of cSHAKE256 of data with the given function name and
+// customization string. With both empty, it is SHAKE256.
+//
+// specified in 3 of [1].
+func SumCSHAKE256(data []byte, outputLen int, functionName, customization []byte) []byte {
+	c := sha3.NewCSHAKE256(functionName, customization)
+	c.Write(data)
+	out := make([]byte, outputLen)
+	c.Read(out)
+	return out
+}
+
+// TupleHash hashes a sequence of byte strings so that the boundaries between them can't be
+// moved: ("ab", "c") and ("a", "bc") give different digests. It is typically used for
+// transcript hashing.
+//
+// specified in 5 of [1].
+type TupleHash struct {
+	shake *sha3.SHAKE
+	// outputLen is 0 for TupleHashXOF
+	outputLen int
+	xof       bool
+	squeezing bool
+}
+
+// NewTupleHash128 creates a TupleHash128 instance generating outputLen bytes and using the
+// given customization string.
+func NewTupleHash128(outputLen int, customization []byte) *TupleHash {
+	return &TupleHash{
+		shake:     sha3.NewCSHAKE128([]byte(tupleHashFunctionName), customization),
+		outputLen: outputLen,
+	}
+}
+
+// NewTupleHash256 creates a TupleHash256 instance generating outputLen bytes and using the
+// given customization string.
+func NewTupleHash256(outputLen int, customization []byte) *TupleHash {
+	return &TupleHash{
+		shake:     sha3.NewCSHAKE256([]byte(tupleHashFunctionName), customization),
+		outputLen: outputLen,
+	}
+}
+
+// NewTupleHashXOF128 creates a TupleHashXOF128 instance, which output can be read with Read
+// for any length. Sum panics as there is no output length.
+func NewTupleHashXOF128(customization []byte) *TupleHash {
+	t := NewTupleHash128(0, customization)
+	t.xof = true
+	return t
+}
+
+// NewTupleHashXOF256 creates a TupleHashXOF256 instance, which output can be read with Read
+// for any length. Sum panics as there is no output length.
+func NewTupleHashXOF256(customization []byte) *TupleHash {
+	t := NewTupleHash256(0, customization)
+	t.xof = true
+	return t
+}
+
+// WriteElement appends element to the tuple. It panics if output has already been read.
+func (t *TupleHash) WriteElement(element []byte) {
+	if t.squeezing {
+		panic("sp800185: WriteElement after Read")
+	}
+	t.shake.Write(kmac.EncodeString(element))
+}
+
+// Read squeezes output. For a fixed-length TupleHash, only the first outputLen bytes are the
+// TupleHash digest, Sum should be used instead.
+func (t *TupleHash) Read(p []byte) (int, error) {
+	if !t.squeezing {
+		t.shake.Write(kmac.RightEncode(uint64(t.outputLen) * 8))
+		t.squeezing = true
+	}
+	return t.shake.Read(p)
+}
+
+// Sum appends the outputLen bytes digest of the tuple to b. Like Read, it finalizes the
+// TupleHash so no more elements can be written. It panics for TupleHashXOF, whose output must be
+// read with Read.
+func (t *TupleHash) Sum(b []byte) []byte {
+	if t.xof {
+		panic("sp800185: Sum on a TupleHashXOF, use Read")
+	}
+	out := make([]byte, t.outputLen)
+	t.Read(out)
+	return append(b, out...)
+}
+
+// SumTupleHash128 returns the outputLen bytes TupleHash128 digest of tuple.
+func SumTupleHash128(tuple [][]byte, outputLen int, customization []byte) []byte {
+	t := NewTupleHash128(outputLen, customization)
+	for _, element := range tuple {
+		t.WriteElement(element)
+	}
+	return t.Sum(nil)
+}
+
+// SumTupleHash256 returns the outputLen bytes TupleHash256 digest of tuple.
+func SumTupleHash256(tuple [][]byte, outputLen int, customization []byte) []byte {
+	t := NewTupleHash256(outputLen, customization)
+	for _, element := range tuple {
+		t.WriteElement(element)
+	}
+	return t.Sum(nil)
+}
+
+// ParallelHash128 returns outputLen bytes of ParallelHash128 of data, split in blocks of
+// blockSize bytes. The blocks are hashed by up to GOMAXPROCS goroutines.
+//
+// specified in 6 of [1].
+func ParallelHash128(data []byte, blockSize, outputLen int, customization []byte) []byte {
+	return parallelHash(false, data, blockSize, outputLen, outputLen, customization)
+}
+
+// ParallelHash256 returns outputLen bytes of ParallelHash256 of data, split in blocks of
+// blockSize bytes. The blocks are hashed by up to GOMAXPROCS goroutines.
+//
+// specified in 6 of [1].
+func ParallelHash256(data []byte, blockSize, outputLen int, customization []byte) []byte {
+	return parallelHash(true, data, blockSize, outputLen, outputLen, customization)
+}
+
+// ParallelHashXOF128 returns outputLen bytes of ParallelHashXOF128 of data, split in blocks
+// of blockSize bytes.
+func ParallelHashXOF128(data []byte, blockSize, outputLen int, customization []byte) []byte {
+	return parallelHash(false, data, blockSize, 0, outputLen, customization)
+}
+
+// ParallelHashXOF256 returns outputLen bytes of ParallelHashXOF256 of data, split in blocks
+// of blockSize bytes.
+func ParallelHashXOF256(data []byte, blockSize, outputLen int, customization []byte) []byte {
+	return parallelHash(true, data, blockSize, 0, outputLen, customization)
+}
+
+// parallelHash encodes encodedLen in the output, it is 0 for the XOF variants.
+func parallelHash(is256 bool, data []byte, blockSize, encodedLen, outputLen int, customization []byte) []byte {
+	if blockSize <= 0 {
+		panic("sp800185: invalid block size")
+	}
+
+	// leaves are hashed with SHAKE, i.e. cSHAKE with empty N and S, with an output of twice
+	// the security level
+	newCSHAKE, newSHAKE, leafSize := sha3.NewCSHAKE128, sha3.NewSHAKE128, 32
+	if is256 {
+		newCSHAKE, newSHAKE, leafSize = sha3.NewCSHAKE256, sha3.NewSHAKE256, 64
+	}
+
+	blocks := (len(data) + blockSize - 1) / blockSize
+	leaves := make([]byte, blocks*leafSize)
+	sumLeaves := func(first, step int) {
+		for i := first; i < blocks; i += step {
+			shake := newSHAKE()
+			shake.Write(data[i*blockSize : min((i+1)*blockSize, len(data))])
+			shake.Read(leaves[i*leafSize : (i+1)*leafSize])
+		}
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), blocks)
+	if workers <= 1 {
+		sumLeaves(0, 1)
+	} else {
+		var wg sync.WaitGroup
+		for worker := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sumLeaves(worker, workers)
+			}()
+		}
+		wg.Wait()
+	}
+
+	c := newCSHAKE([]byte(parallelHashFunctionName), customization)
+	c.Write(kmac.LeftEncode(uint64(blockSize)))
+	c.Write(leaves)
+	c.Write(kmac.RightEncode(uint64(blocks)))
+	c.Write(kmac.RightEncode(uint64(encodedLen) * 8))
+	out := make([]byte, outputLen)
+	c.Read(out)
+	return out
+}
@@ -0,0 +1,151 @@
+package sp800185
+
+import (
+	"bytes"
+	"encoding/hex"
+	"runtime"
+	"testing"
+)
+
+// Samples from https://csrc.nist.gov/projects/cryptographic-standards-and-guidelines/example-values
+// (cSHAKE_samples.pdf, TupleHash_samples.pdf, TupleHashXOF_samples.pdf, ParallelHash_samples.pdf
+// and ParallelHashXOF_samples.pdf).
+
+func sequence(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	return buf
+}
+
+func mustDecode(s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+func TestCSHAKESamples(t *testing.T) {
+	for i, sample := range []struct {
+		sum    func(data []byte, outputLen int, functionName, customization []byte) []byte
+		data   []byte
+		output string
+	}{
+		{SumCSHAKE128, sequence(4), "C1C36925B6409A04F1B504FCBCA9D82B4017277CB5ED2B2065FC1D3814D5AAF5"},
+		{SumCSHAKE128, sequence(200), "C5221D50E4F822D96A2E8881A961420F294B7B24FE3D2094BAED2C6524CC166B"},
+		{SumCSHAKE256, sequence(4), "D008828E2B80AC9D2218FFEE1D070C48B8E4C87BFF32C9699D5B6896EEE0EDD164020E2BE0560858D9C00C037E34A96937C561A74C412BB4C746469527281C8C"},
+		{SumCSHAKE256, sequence(200), "07DC27B11E51FBAC75BC7B3C1D983E8B4B85FB1DEFAF218912AC86430273091727F42B17ED1DF63E8EC118F04B23633C1DFB1574C8FB55CB45DA8E25AFB092BB"},
+	} {
+		want := mustDecode(sample.output)
+		got := sample.sum(sample.data, len(want), nil, []byte("Email Signature"))
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %X, got %X", i+1, want, got)
+		}
+	}
+}
+
+func TestTupleHashSamples(t *testing.T) {
+	twoElements := [][]byte{mustDecode("000102"), mustDecode("101112131415")}
+	threeElements := [][]byte{mustDecode("000102"), mustDecode("101112131415"), mustDecode("202122232425262728")}
+
+	for i, sample := range []struct {
+		is256         bool
+		xof           bool
+		tuple         [][]byte
+		customization string
+		output        string
+	}{
+		{false, false, twoElements, "", "C5D8786C1AFB9B82111AB34B65B2C0048FA64E6D48E263264CE1707D3FFC8ED1"},
+		{false, false, twoElements, "My Tuple App", "75CDB20FF4DB1154E841D758E24160C54BAE86EB8C13E7F5F40EB35588E96DFB"},
+		{false, false, threeElements, "My Tuple App", "E60F202C89A2631EDA8D4C588CA5FD07F39E5151998DECCF973ADB3804BB6E84"},
+		{true, false, twoElements, "", "CFB7058CACA5E668F81A12A20A2195CE97A925F1DBA3E7449A56F82201EC607311AC2696B1AB5EA2352DF1423BDE7BD4BB78C9AED1A853C78672F9EB23BBE194"},
+		{true, false, twoElements, "My Tuple App", "147C2191D5ED7EFD98DBD96D7AB5A11692576F5FE2A5065F3E33DE6BBA9F3AA1C4E9A068A289C61C95AAB30AEE1E410B0B607DE3620E24A4E3BF9852A1D4367E"},
+		{true, false, threeElements, "My Tuple App", "45000BE63F9B6BFD89F54717670F69A9BC763591A4F05C50D68891A744BCC6E7D6D5B5E82C018DA999ED35B0BB49C9678E526ABD8E85C13ED254021DB9E790CE"},
+		{false, true, twoElements, "", "2F103CD7C32320353495C68DE1A8129245C6325F6F2A3D608D92179C96E68488"},
+		{false, true, twoElements, "My Tuple App", "3FC8AD69453128292859A18B6C67D7AD85F01B32815E22CE839C49EC374E9B9A"},
+		{false, true, threeElements, "My Tuple App", "900FE16CAD098D28E74D632ED852F99DAAB7F7DF4D99E775657885B4BF76D6F8"},
+		{true, true, twoElements, "", "03DED4610ED6450A1E3F8BC44951D14FBC384AB0EFE57B000DF6B6DF5AAE7CD568E77377DAF13F37EC75CF5FC598B6841D51DD207C991CD45D210BA60AC52EB9"},
+		{true, true, twoElements, "My Tuple App", "6483CB3C9952EB20E830AF4785851FC597EE3BF93BB7602C0EF6A65D741AECA7E63C3B128981AA05C6D27438C79D2754BB1B7191F125D6620FCA12CE658B2442"},
+	} {
+		want := mustDecode(sample.output)
+		customization := []byte(sample.customization)
+
+		var tupleHash *TupleHash
+		switch {
+		case sample.is256 && sample.xof:
+			tupleHash = NewTupleHashXOF256(customization)
+		case sample.is256:
+			tupleHash = NewTupleHash256(len(want), customization)
+		case sample.xof:
+			tupleHash = NewTupleHashXOF128(customization)
+		default:
+			tupleHash = NewTupleHash128(len(want), customization)
+		}
+		for _, element := range sample.tuple {
+			tupleHash.WriteElement(element)
+		}
+		got := make([]byte, len(want))
+		tupleHash.Read(got)
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %X, got %X", i+1, want, got)
+		}
+	}
+}
+
+// TestTupleHashXOFSum checks that Sum, which has no output length to use, panics for TupleHashXOF.
+func TestTupleHashXOFSum(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Sum didn't panic for TupleHashXOF128")
+		}
+	}()
+	NewTupleHashXOF128(nil).Sum(nil)
+}
+
+func TestParallelHashSamples(t *testing.T) {
+	shortData := mustDecode("000102030405060710111213141516172021222324252627")
+	longData := mustDecode("000102030405060708090A0B101112131415161718191A1B202122232425262728292A2B303132333435363738393A3B404142434445464748494A4B505152535455565758595A5B")
+
+	for i, sample := range []struct {
+		sum           func(data []byte, blockSize, outputLen int, customization []byte) []byte
+		data          []byte
+		blockSize     int
+		customization string
+		output        string
+	}{
+		{ParallelHash128, shortData, 8, "", "BA8DC1D1D979331D3F813603C67F72609AB5E44B94A0B8F9AF46514454A2B4F5"},
+		{ParallelHash128, shortData, 8, "Parallel Data", "FC484DCB3F84DCEEDC353438151BEE58157D6EFED0445A81F165E495795B7206"},
+		{ParallelHash128, longData, 12, "Parallel Data", "F7FD5312896C6685C828AF7E2ADB97E393E7F8D54E3C2EA4B95E5ACA3796E8FC"},
+		{ParallelHash256, shortData, 8, "", "BC1EF124DA34495E948EAD207DD9842235DA432D2BBC54B4C110E64C451105531B7F2A3E0CE055C02805E7C2DE1FB746AF97A1DD01F43B824E31B87612410429"},
+		{ParallelHash256, shortData, 8, "Parallel Data", "CDF15289B54F6212B4BC270528B49526006DD9B54E2B6ADD1EF6900DDA3963BB33A72491F236969CA8AFAEA29C682D47A393C065B38E29FAE651A2091C833110"},
+		{ParallelHash256, longData, 12, "Parallel Data", "69D0FCB764EA055DD09334BC6021CB7E4B61348DFF375DA262671CDEC3EFFA8D1B4568A6CCE16B1CAD946DDDE27F6CE2B8DEE4CD1B24851EBF00EB90D43813E9"},
+		{ParallelHashXOF128, shortData, 8, "", "FE47D661E49FFE5B7D999922C062356750CAF552985B8E8CE6667F2727C3C8D3"},
+		{ParallelHashXOF128, shortData, 8, "Parallel Data", "EA2A793140820F7A128B8EB70A9439F93257C6E6E79B4A540D291D6DAE7098D7"},
+		{ParallelHashXOF128, longData, 12, "Parallel Data", "0127AD9772AB904691987FCC4A24888F341FA0DB2145E872D4EFD255376602F0"},
+		{ParallelHashXOF256, shortData, 8, "", "C10A052722614684144D28474850B410757E3CBA87651BA167A5CBDDFF7F466675FBF84BCAE7378AC444BE681D729499AFCA667FB879348BFDDA427863C82F1C"},
+		{ParallelHashXOF256, shortData, 8, "Parallel Data", "538E105F1A22F44ED2F5CC1674FBD40BE803D9C99BF5F8D90A2C8193F3FE6EA768E5C1A20987E2C9C65FEBED03887A51D35624ED12377594B5585541DC377EFC"},
+		{ParallelHashXOF256, longData, 12, "Parallel Data", "6B3E790B330C889A204C2FBC728D809F19367328D852F4002DC829F73AFD6BCEFB7FE5B607B13A801C0BE5C1170BDB794E339458FDB0E62A6AF3D42558970249"},
+	} {
+		want := mustDecode(sample.output)
+		got := sample.sum(sample.data, sample.blockSize, len(want), []byte(sample.customization))
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: expected %X, got %X", i+1, want, got)
+		}
+	}
+}
+
+// TestParallelHashWorkers checks that the digest doesn't depend on the number of goroutines.
+func TestParallelHashWorkers(t *testing.T) {
+	data := sequence(100_000)
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	want := ParallelHash128(data, 1024, 32, nil)
+	for _, procs := range []int{2, 3, 8} {
+		runtime.GOMAXPROCS(procs)
+		if got := ParallelHash128(data, 1024, 32, nil); !bytes.Equal(got, want) {
+			t.Fatalf("GOMAXPROCS=%d: expected %X, got %X", procs, want, got)
+		}
+	}
+}
@@ -2,26 +2,50 @@
 // method X-Wing, which combines X25519, ML-KEM-768, and SHA3-256 as specified
 // in [draft-connolly-cfrg-xwing-kem].
 //
+// It conforms to the current revision of the draft: testdata contains the
+// test vectors of the draft's source repository [spec], which the package is
+// tested against. The construction hasn't changed since draft-05: the key
+// expansion (SHAKE256 of the seed into the 64-byte ML-KEM-768 seed d || z
+// followed by the X25519 private key) and the combiner (SHA3-256 of
+// ss_M || ss_X || ct_X || pk_X || label) are the same, and draft-05
+// implementations such as github.com/cloudflare/circl/kem/xwing reproduce
+// these vectors.
+//
 // adapted from https://github.com/FiloSottile/mlkem768/blob/main/xwing/xwing.go
 //
-// [draft-connolly-cfrg-xwing-kem]: https://www.ietf.org/archive/id/draft-connolly-cfrg-xwing-kem-05.html
+// [draft-connolly-cfrg-xwing-kem]: https://datatracker.ietf.org/doc/draft-connolly-cfrg-xwing-kem/
+// [spec]: https://github.com/dconnolly/draft-connolly-cfrg-xwing-kem/blob/main/spec/test-vectors.txt
 package xwing
 
 import (
 	"bytes"
 	"crypto/ecdh"
 	"crypto/mlkem"
+	"crypto/mlkem/mlkemtest"
 	"crypto/rand"
 	"errors"
 
 	"crypto/sha3"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/x25519"
 )
 
 const (
-	CiphertextSize       = mlkem.CiphertextSize768 + 32
-	EncapsulationKeySize = mlkem.EncapsulationKeySize768 + 32
-	SharedKeySize        = 32
-	SeedSize             = 32
+	// CiphertextSize is the size of an X-Wing ciphertext: an ML-KEM-768 ciphertext followed by
+	// an X25519 public key.
+	CiphertextSize = mlkem.CiphertextSize768 + x25519Size
+	// EncapsulationKeySize is the size of an X-Wing encapsulation key: an ML-KEM-768
+	// encapsulation key followed by an X25519 public key.
+	EncapsulationKeySize = mlkem.EncapsulationKeySize768 + x25519Size
+	// DecapsulationKeySize is the size of an X-Wing decapsulation key, which is a seed.
+	DecapsulationKeySize = SeedSize
+	// EncapsulationSeedSize is the size of the randomness used by EncapsulateDerand: the
+	// ML-KEM-768 encapsulation randomness followed by the X25519 ephemeral private key.
+	EncapsulationSeedSize = 32 + x25519Size
+	SharedKeySize         = 32
+	SeedSize              = 32
+
+	x25519Size = 32
 )
 
 // A DecapsulationKey is the secret key used to decapsulate a shared key from a
@@ -30,12 +54,15 @@ type DecapsulationKey struct {
 	sk  [SeedSize]byte
 	skM *mlkem.DecapsulationKey768
 	skX *ecdh.PrivateKey
-	pk  [EncapsulationKeySize]byte
+	ek  *EncapsulationKey
 }
 
+// An EncapsulationKey is the public key used to produce ciphertexts to be decapsulated by the
+// corresponding DecapsulationKey.
 type EncapsulationKey struct {
 	mlKemEncapsulationKey *mlkem.EncapsulationKey768
 	x25519Key             *ecdh.PublicKey
+	pk                    [EncapsulationKeySize]byte
 }
 
 // Bytes returns the decapsulation key as a 32-byte seed.
@@ -46,23 +73,42 @@ func (dk *DecapsulationKey) Bytes() []byte {
 // EncapsulationKey returns the public encapsulation key necessary to produce
 // ciphertexts.
 func (dk *DecapsulationKey) EncapsulationKey() *EncapsulationKey {
-	pkMBytes := dk.pk[:mlkem.EncapsulationKeySize768]
-	pkX := dk.pk[mlkem.EncapsulationKeySize768:]
+	return dk.ek
+}
 
-	peerKey, err := ecdh.X25519().NewPublicKey(pkX)
+// NewEncapsulationKey parses an encapsulation key from its encoded form. It returns an error if
+// the ML-KEM-768 key fails the modulus check of FIPS 203 or if the X25519 key is a point of
+// small order, for which the X25519 shared secret would be all zeros.
+func NewEncapsulationKey(encapsulationKey []byte) (*EncapsulationKey, error) {
+	if len(encapsulationKey) != EncapsulationKeySize {
+		return nil, errors.New("xwing: invalid encapsulation key length")
+	}
+
+	pkM, err := mlkem.NewEncapsulationKey768(encapsulationKey[:mlkem.EncapsulationKeySize768])
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	pkM, err := mlkem.NewEncapsulationKey768(pkMBytes)
+	pkXBytes := encapsulationKey[mlkem.EncapsulationKeySize768:]
+	if x25519.IsSmallOrder(pkXBytes) {
+		return nil, errors.New("xwing: invalid X25519 public key")
+	}
+	pkX, err := ecdh.X25519().NewPublicKey(pkXBytes)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return &EncapsulationKey{
+	ek := &EncapsulationKey{
 		mlKemEncapsulationKey: pkM,
-		x25519Key:             peerKey,
+		x25519Key:             pkX,
 	}
+	copy(ek.pk[:], encapsulationKey)
+	return ek, nil
+}
+
+// Bytes returns the encapsulation key in its encoded form.
+func (ek *EncapsulationKey) Bytes() []byte {
+	return bytes.Clone(ek.pk[:])
 }
 
 // GenerateKey generates a new decapsulation key, drawing random bytes from
@@ -84,7 +130,7 @@ func NewKeyFromSeed(sk []byte) (*DecapsulationKey, error) {
 
 	s := sha3.NewSHAKE256()
 	s.Write(sk)
-	expanded := make([]byte, mlkem.SeedSize+32)
+	expanded := make([]byte, mlkem.SeedSize+x25519Size)
 	if _, err := s.Read(expanded); err != nil {
 		return nil, err
 	}
@@ -100,13 +146,21 @@ func NewKeyFromSeed(sk []byte) (*DecapsulationKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	pkX := x.PublicKey().Bytes()
+	pkX := x.PublicKey()
+
+	// the encapsulation key of a valid decapsulation key is valid, it is not checked again
+	ek := &EncapsulationKey{
+		mlKemEncapsulationKey: pkM,
+		x25519Key:             pkX,
+	}
+	copy(ek.pk[:mlkem.EncapsulationKeySize768], pkM.Bytes())
+	copy(ek.pk[mlkem.EncapsulationKeySize768:], pkX.Bytes())
 
 	dk := &DecapsulationKey{}
 	copy(dk.sk[:], sk)
 	dk.skM = skM
 	dk.skX = x
-	copy(dk.pk[:], append(pkM.Bytes(), pkX...))
+	dk.ek = ek
 	return dk, nil
 }
 
@@ -126,30 +180,63 @@ func combiner(ssM, ssX, ctX, pkX []byte) []byte {
 
 // Encapsulate generates a shared key and an associated ciphertext from an
 // encapsulation key, drawing random bytes from crypto/rand.
-// If the encapsulation key is not valid, Encapsulate returns an error.
 //
 // The shared key must be kept secret.
-func (encapsulationKey *EncapsulationKey) Encapsulate() (ciphertext, sharedKey []byte) {
+func (encapsulationKey *EncapsulationKey) Encapsulate() (ciphertext, sharedKey []byte, err error) {
 	ephemeralKey, err := ecdh.X25519().GenerateKey(rand.Reader)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
+	ssM, ctM := encapsulationKey.mlKemEncapsulationKey.Encapsulate()
+	return encapsulationKey.encapsulate(ssM, ctM, ephemeralKey)
+}
+
+// EncapsulateDerand is the derandomized version of Encapsulate, using the
+// 64-byte eseed as randomness: the first 32 bytes are the ML-KEM-768
+// encapsulation randomness and the last 32 bytes the X25519 ephemeral private
+// key.
+//
+// Like mlkemtest.Encapsulate768 which it uses, it must only be used for
+// known-answer tests: reusing or biasing eseed breaks the security of the KEM.
+func (encapsulationKey *EncapsulationKey) EncapsulateDerand(eseed []byte) (ciphertext, sharedKey []byte, err error) {
+	if len(eseed) != EncapsulationSeedSize {
+		return nil, nil, errors.New("xwing: invalid encapsulation seed length")
+	}
+
+	ephemeralKey, err := ecdh.X25519().NewPrivateKey(eseed[32:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ssM, ctM, err := mlkemtest.Encapsulate768(encapsulationKey.mlKemEncapsulationKey, eseed[:32])
+	if err != nil {
+		return nil, nil, err
+	}
+	return encapsulationKey.encapsulate(ssM, ctM, ephemeralKey)
+}
+
+// encapsulate completes the encapsulation of the ML-KEM-768 shared key ssM and ciphertext ctM
+// with the X25519 ephemeral key.
+func (encapsulationKey *EncapsulationKey) encapsulate(ssM, ctM []byte, ephemeralKey *ecdh.PrivateKey) (ciphertext, sharedKey []byte, err error) {
 	ctX := ephemeralKey.PublicKey().Bytes()
+	// ECDH fails for the X25519 keys of small order, which NewEncapsulationKey rejects
 	ssX, err := ephemeralKey.ECDH(encapsulationKey.x25519Key)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
-	ssM, ctM := encapsulationKey.mlKemEncapsulationKey.Encapsulate()
-
-	ss := combiner(ssM, ssX, ctX, encapsulationKey.x25519Key.Bytes())
-	ct := append(ctM, ctX...)
-	return ct, ss
+	ss := combiner(ssM, ssX, ctX, encapsulationKey.pk[mlkem.EncapsulationKeySize768:])
+	ct := make([]byte, 0, CiphertextSize)
+	ct = append(ct, ctM...)
+	ct = append(ct, ctX...)
+	return ct, ss, nil
 }
 
 // Decapsulate generates a shared key from a ciphertext and a decapsulation key.
-// If the ciphertext is not valid, Decapsulate returns an error.
+// If the ciphertext is not valid, Decapsulate returns an error. Unlike the
+// specification, which doesn't check it, that includes an X25519 ciphertext of
+// small order, for which the X25519 shared secret would be all zeros.
 //
 // The shared key must be kept secret.
 func (dk *DecapsulationKey) Decapsulate(ciphertext []byte) (sharedKey []byte, err error) {
@@ -159,7 +246,7 @@ func (dk *DecapsulationKey) Decapsulate(ciphertext []byte) (sharedKey []byte, er
 
 	ctM := ciphertext[:mlkem.CiphertextSize768]
 	ctX := ciphertext[mlkem.CiphertextSize768:]
-	pkX := dk.pk[mlkem.EncapsulationKeySize768:]
+	pkX := dk.ek.pk[mlkem.EncapsulationKeySize768:]
 
 	ssM, err := dk.skM.Decapsulate(ctM)
 	if err != nil {
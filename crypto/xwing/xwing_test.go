@@ -0,0 +1,210 @@
+package xwing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/mlkem"
+	"crypto/sha3"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/internal/x25519"
+)
+
+// readVectors parses testdata/test-vectors.txt, a verbatim copy of spec/test-vectors.txt of
+// https://github.com/dconnolly/draft-connolly-cfrg-xwing-kem. Its SHAKE128 checksum,
+// 1bcd0057d861d6b866239936cadcaeee1ec0164dedc181c386e9e54fe46156fe, is checked by TestVectors.
+// The file consists of blocks
+// of "name value" lines separated by empty lines, where long hex values continue on the
+// following indented lines.
+func readVectors(t *testing.T) []map[string][]byte {
+	file, err := os.Open("testdata/test-vectors.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var vectors []map[string][]byte
+	var vector map[string][]byte
+	var name string
+	var value strings.Builder
+	flush := func() {
+		if name == "" {
+			return
+		}
+		decoded, err := hex.DecodeString(value.String())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		vector[name] = decoded
+		name = ""
+		value.Reset()
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+			vector = nil
+		case strings.HasPrefix(line, " "):
+			value.WriteString(strings.TrimSpace(line))
+		default:
+			flush()
+			if vector == nil {
+				vector = make(map[string][]byte)
+				vectors = append(vectors, vector)
+			}
+			fields := strings.Fields(line)
+			name = fields[0]
+			if len(fields) > 1 {
+				value.WriteString(fields[1])
+			}
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return vectors
+}
+
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/test-vectors.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := make([]byte, 32)
+	shake := sha3.NewSHAKE128()
+	shake.Write(data)
+	shake.Read(checksum)
+	if hex.EncodeToString(checksum) != "1bcd0057d861d6b866239936cadcaeee1ec0164dedc181c386e9e54fe46156fe" {
+		t.Fatalf("testdata/test-vectors.txt differs from the specification, checksum %x", checksum)
+	}
+
+	vectors := readVectors(t)
+	if len(vectors) != 3 {
+		t.Fatalf("expected 3 vectors, got %d", len(vectors))
+	}
+
+	for _, vector := range vectors {
+		dk, err := NewKeyFromSeed(vector["seed"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dk.Bytes(), vector["sk"]) {
+			t.Errorf("expected decapsulation key %x, got %x", vector["sk"], dk.Bytes())
+		}
+		if !bytes.Equal(dk.EncapsulationKey().Bytes(), vector["pk"]) {
+			t.Fatalf("expected encapsulation key %x, got %x", vector["pk"], dk.EncapsulationKey().Bytes())
+		}
+
+		ek, err := NewEncapsulationKey(vector["pk"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, sharedKey, err := ek.EncapsulateDerand(vector["eseed"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, vector["ct"]) {
+			t.Errorf("expected ciphertext %x, got %x", vector["ct"], ciphertext)
+		}
+		if !bytes.Equal(sharedKey, vector["ss"]) {
+			t.Errorf("expected shared key %x, got %x", vector["ss"], sharedKey)
+		}
+
+		decapsulated, err := dk.Decapsulate(vector["ct"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decapsulated, vector["ss"]) {
+			t.Errorf("expected decapsulated shared key %x, got %x", vector["ss"], decapsulated)
+		}
+	}
+}
+
+func TestEncapsulateDerandInvalidSeed(t *testing.T) {
+	dk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := dk.EncapsulationKey().EncapsulateDerand(make([]byte, EncapsulationSeedSize-1)); err == nil {
+		t.Error("short encapsulation seed accepted")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := NewEncapsulationKey(dk.EncapsulationKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ek.Bytes()) != EncapsulationKeySize {
+		t.Fatalf("expected a %d-byte encapsulation key, got %d bytes", EncapsulationKeySize, len(ek.Bytes()))
+	}
+
+	ciphertext, sharedKey, err := ek.Encapsulate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext) != CiphertextSize || len(sharedKey) != SharedKeySize {
+		t.Fatalf("expected a %d-byte ciphertext and a %d-byte shared key, got %d and %d bytes",
+			CiphertextSize, SharedKeySize, len(ciphertext), len(sharedKey))
+	}
+	decapsulated, err := dk.Decapsulate(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decapsulated, sharedKey) {
+		t.Fatal("decapsulated shared key differs from the encapsulated one")
+	}
+
+	dk2, err := NewKeyFromSeed(dk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dk.Bytes()) != DecapsulationKeySize || !bytes.Equal(dk2.EncapsulationKey().Bytes(), ek.Bytes()) {
+		t.Fatal("decapsulation key doesn't round trip through its seed")
+	}
+}
+
+func TestNewEncapsulationKeyInvalid(t *testing.T) {
+	dk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek := dk.EncapsulationKey().Bytes()
+
+	if _, err := NewEncapsulationKey(ek[:EncapsulationKeySize-1]); err == nil {
+		t.Error("truncated encapsulation key accepted")
+	}
+	if _, err := NewEncapsulationKey(append(ek, 0)); err == nil {
+		t.Error("too long encapsulation key accepted")
+	}
+
+	// a coefficient of the ML-KEM key equal to q = 3329 fails the modulus check
+	invalidM := bytes.Clone(ek)
+	invalidM[0] = 0x01
+	invalidM[1] = invalidM[1]&0xf0 | 0x0d
+	if _, err := NewEncapsulationKey(invalidM); err == nil {
+		t.Error("encapsulation key with an invalid ML-KEM key accepted")
+	}
+
+	for _, point := range x25519.SmallOrderPoints {
+		for _, msb := range []byte{0, 0x80} {
+			invalidX := bytes.Clone(ek)
+			copy(invalidX[mlkem.EncapsulationKeySize768:], point[:])
+			invalidX[EncapsulationKeySize-1] |= msb
+			if _, err := NewEncapsulationKey(invalidX); err == nil {
+				t.Errorf("encapsulation key with the small order X25519 key %x accepted", invalidX[mlkem.EncapsulationKeySize768:])
+			}
+		}
+	}
+}
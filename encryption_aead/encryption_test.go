@@ -60,7 +60,8 @@ func BenchmarkEncryptAEAD(b *testing.B) {
 		// benchmarkEncrypt(b, size, "XChaCha20-BLAKE3", newXChaCha20Blake3Cipher(b, xChaCha20Key), xChaCha20Nonce, additionalData)
 		benchmarkEncrypt(b, size, "BChaCha20-BLAKE3", newBChaCha20Blake3Cipher(b, xChaCha20Key), bChaCha20Nonce, additionalData)
 		// benchmarkEncrypt(b, size, "SChaCha20-BLAKE3", newSChaCha20Blake3Cipher(b, xChaCha20Key), bChaCha20Nonce, additionalData)
-		benchmarkEncrypt(b, size, "Ascon", newAsconCipher(b, asconKey), asconNonce, additionalData)
+		benchmarkEncrypt(b, size, "Ascon-128a", newAsconCipher(b, ascon.New128a, asconKey), asconNonce, additionalData)
+		benchmarkEncrypt(b, size, "Ascon-AEAD128", newAsconCipher(b, ascon.NewAEAD128, asconKey), asconNonce, additionalData)
 	}
 }
 
@@ -92,7 +93,8 @@ func BenchmarkDecryptAEAD(b *testing.B) {
 		benchmarkDecrypt(b, size, "ChaCha20-BLAKE3", newChaCha20Blake3Cipher(b, xChaCha20Key), xChaCha20Nonce, additionalData)
 		benchmarkDecrypt(b, size, "BChaCha20-BLAKE3", newBChaCha20Blake3Cipher(b, xChaCha20Key), bChaCha20Nonce, additionalData)
 		// benchmarkDecrypt(b, size, "SChaCha20-BLAKE3", newSChaCha20Blake3Cipher(b, xChaCha20Key), bChaCha20Nonce, additionalData)
-		benchmarkDecrypt(b, size, "Ascon", newAsconCipher(b, asconKey), asconNonce, additionalData)
+		benchmarkDecrypt(b, size, "Ascon-128a", newAsconCipher(b, ascon.New128a, asconKey), asconNonce, additionalData)
+		benchmarkDecrypt(b, size, "Ascon-AEAD128", newAsconCipher(b, ascon.NewAEAD128, asconKey), asconNonce, additionalData)
 	}
 }
 
@@ -248,8 +250,8 @@ type asconCipher struct {
 	cipher cipher.AEAD
 }
 
-func newAsconCipher(b *testing.B, key []byte) asconCipher {
-	cipher, err := ascon.New128a(key)
+func newAsconCipher(b *testing.B, newAead func(key []byte) (cipher.AEAD, error), key []byte) asconCipher {
+	cipher, err := newAead(key)
 	if err != nil {
 		b.Error(err)
 	}
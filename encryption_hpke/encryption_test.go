@@ -0,0 +1,224 @@
+package encryption_hpke
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/hpke"
+	"github.com/skerkour/go-benchmarks/utils"
+)
+
+var (
+	// BENCHMARKS are the message sizes of the encryption_aead benchmarks
+	BENCHMARKS = []int64{
+		64,
+		1024,
+		16 * 1024,
+		64 * 1024,
+		1024 * 1024,
+		10 * 1024 * 1024,
+		100 * 1024 * 1024,
+	}
+
+	kems = []hpke.KEM{hpke.DHKEMX25519, hpke.MLKEM768, hpke.XWing}
+	kdfs = []*hpke.KDF{hpke.HKDFSHA256, hpke.HKDFSHA512}
+)
+
+// BenchmarkSetupSender benchmarks the setup of a sender context, which is dominated by the
+// encapsulation of the KEM, for each KEM and KDF.
+func BenchmarkSetupSender(b *testing.B) {
+	info := utils.RandBytes(b, 32)
+
+	for _, kem := range kems {
+		recipient, sender := generateKeys(b, kem)
+		for _, kdf := range kdfs {
+			suite := hpke.NewSuite(kem, kdf, hpke.AES128GCM)
+			benchmarkSetup(b, "Base-"+suite.String(), func() error {
+				_, _, err := suite.NewSender(recipient.PublicKey(), info)
+				return err
+			})
+			if kem == hpke.DHKEMX25519 {
+				benchmarkSetup(b, "Auth-"+suite.String(), func() error {
+					_, _, err := suite.NewAuthSender(recipient.PublicKey(), sender, info)
+					return err
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkSetupRecipient benchmarks the setup of a recipient context, which is dominated by the
+// decapsulation of the KEM, for each KEM and KDF.
+func BenchmarkSetupRecipient(b *testing.B) {
+	info := utils.RandBytes(b, 32)
+
+	for _, kem := range kems {
+		recipient, sender := generateKeys(b, kem)
+		for _, kdf := range kdfs {
+			suite := hpke.NewSuite(kem, kdf, hpke.AES128GCM)
+			enc, _, err := suite.NewSender(recipient.PublicKey(), info)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchmarkSetup(b, "Base-"+suite.String(), func() error {
+				_, err := suite.NewRecipient(recipient, enc, info)
+				return err
+			})
+			if kem == hpke.DHKEMX25519 {
+				authEnc, _, err := suite.NewAuthSender(recipient.PublicKey(), sender, info)
+				if err != nil {
+					b.Fatal(err)
+				}
+				benchmarkSetup(b, "Auth-"+suite.String(), func() error {
+					_, err := suite.NewAuthRecipient(recipient, sender.PublicKey(), authEnc, info)
+					return err
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkSeal benchmarks single-shot encryption, a setup followed by the encryption of one
+// message, for each KEM.
+func BenchmarkSeal(b *testing.B) {
+	info := utils.RandBytes(b, 32)
+	additionalData := utils.RandBytes(b, 100)
+
+	for _, size := range BENCHMARKS {
+		for _, kem := range kems {
+			recipient, _ := generateKeys(b, kem)
+			suite := hpke.NewSuite(kem, hpke.HKDFSHA256, hpke.AES128GCM)
+			b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), suite), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(size)
+				plaintext := utils.RandBytes(b, size)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, _, err := suite.Seal(recipient.PublicKey(), info, additionalData, plaintext); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkOpen benchmarks single-shot decryption for each KEM.
+func BenchmarkOpen(b *testing.B) {
+	info := utils.RandBytes(b, 32)
+	additionalData := utils.RandBytes(b, 100)
+
+	for _, size := range BENCHMARKS {
+		for _, kem := range kems {
+			recipient, _ := generateKeys(b, kem)
+			suite := hpke.NewSuite(kem, hpke.HKDFSHA256, hpke.AES128GCM)
+			b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), suite), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(size)
+				plaintext := utils.RandBytes(b, size)
+				enc, ciphertext, err := suite.Seal(recipient.PublicKey(), info, additionalData, plaintext)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := suite.Open(recipient, enc, info, additionalData, ciphertext); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkContextSeal benchmarks the per-message cost of encrypting with an established sender
+// context, which only depends on the AEAD.
+func BenchmarkContextSeal(b *testing.B) {
+	additionalData := utils.RandBytes(b, 100)
+	recipient, _ := generateKeys(b, hpke.XWing)
+
+	for _, size := range BENCHMARKS {
+		for _, aead := range []*hpke.AEAD{hpke.AES128GCM, hpke.AES256GCM, hpke.ChaCha20Poly1305} {
+			suite := hpke.NewSuite(hpke.XWing, hpke.HKDFSHA256, aead)
+			b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), aead), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(size)
+				plaintext := utils.RandBytes(b, size)
+				_, sender, err := suite.NewSender(recipient.PublicKey(), nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := sender.Seal(additionalData, plaintext); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkContextOpen benchmarks the per-message cost of decrypting with an established
+// recipient context. As each message has its own nonce, the next message is encrypted outside of
+// the timer at each iteration.
+func BenchmarkContextOpen(b *testing.B) {
+	additionalData := utils.RandBytes(b, 100)
+	recipient, _ := generateKeys(b, hpke.XWing)
+
+	for _, size := range BENCHMARKS {
+		for _, aead := range []*hpke.AEAD{hpke.AES128GCM, hpke.AES256GCM, hpke.ChaCha20Poly1305} {
+			suite := hpke.NewSuite(hpke.XWing, hpke.HKDFSHA256, aead)
+			b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), aead), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(size)
+				plaintext := utils.RandBytes(b, size)
+				enc, sender, err := suite.NewSender(recipient.PublicKey(), nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				recipientCtx, err := suite.NewRecipient(recipient, enc, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					ciphertext, err := sender.Seal(additionalData, plaintext)
+					if err != nil {
+						b.Fatal(err)
+					}
+					b.StartTimer()
+					if _, err := recipientCtx.Open(additionalData, ciphertext); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func benchmarkSetup(b *testing.B, name string, setup func() error) {
+	b.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := setup(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// generateKeys returns a recipient key and a sender key for the auth mode.
+func generateKeys(b *testing.B, kem hpke.KEM) (recipient, sender hpke.PrivateKey) {
+	recipient, err := kem.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	sender, err = kem.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	return recipient, sender
+}
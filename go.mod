@@ -3,6 +3,7 @@ module github.com/skerkour/go-benchmarks
 go 1.26.0
 
 require (
+	filippo.io/edwards25519 v1.2.0
 	github.com/DataDog/zstd v1.5.5
 	github.com/akamensky/base58 v0.0.0-20210829145138-ce8bf8802e8f
 	github.com/cespare/xxhash/v2 v2.2.0
@@ -24,7 +25,7 @@ require (
 	golang.org/x/crypto v0.49.0
 	golang.org/x/sys v0.42.0
 	golang.org/x/text v0.35.0
-	lukechampine.com/blake3 v1.2.1
+	lukechampine.com/blake3 v1.4.1
 )
 
 require (
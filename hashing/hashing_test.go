@@ -6,8 +6,13 @@ import (
 	"crypto/sha3"
 	"crypto/sha512"
 	"fmt"
+	"runtime"
+	"sync"
 	"testing"
 
+	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon"
+	"github.com/skerkour/go-benchmarks/crypto/k12"
+	"github.com/skerkour/go-benchmarks/crypto/sp800185"
 	"github.com/skerkour/go-benchmarks/utils"
 	zeeboblake3 "github.com/zeebo/blake3"
 	"golang.org/x/crypto/blake2b"
@@ -39,10 +44,16 @@ func BenchmarkHashing(b *testing.B) {
 		benchmarkHasher(size, "SHA3-512", sha3_512Hasher{}, b)
 		benchmarkHasher(size, "SHAKE128-256", shake128_256Hasher{}, b)
 		benchmarkHasher(size, "SHAKE256-512", shake256_512Hasher{}, b)
+		benchmarkHasher(size, "TurboSHAKE128-256", turboShake128_256Hasher{}, b)
+		benchmarkHasher(size, "TurboSHAKE256-512", turboShake256_512Hasher{}, b)
+		benchmarkHasher(size, "KT128", kt128Hasher{}, b)
+		benchmarkHasher(size, "KT256", kt256Hasher{}, b)
 		benchmarkHasher(size, "BLAKE2s-256", blake2sHasher{}, b)
 		benchmarkHasher(size, "BLAKE2b-512", blake2bHasher{}, b)
 		benchmarkHasher(size, "BLAKE3_zeebo", zeeboBlake3Hasher{}, b)
 		benchmarkHasher(size, "BLAKE3_lukechampine", lukechampineBlake3Hasher{}, b)
+		benchmarkHasher(size, "Ascon-Hash256", asconHash256Hasher{}, b)
+		benchmarkHasher(size, "Ascon-XOF128-256", asconXOF128_256Hasher{}, b)
 		// benchmarkHasher("sha512/256", sha512_256Hasher{}, b)
 
 		// benchmarkHasher(size, "zeebo_blake3_512", zeeboBlake3_512Hasher{}, b)
@@ -51,6 +62,54 @@ func BenchmarkHashing(b *testing.B) {
 	}
 }
 
+// BenchmarkHashingParallel hashes a single large object with multi-threaded hashers and reports the
+// throughput for each core count, which is what a server can sustain when hashing one large object.
+func BenchmarkHashingParallel(b *testing.B) {
+	benchmarks := []int64{
+		10 * 1024 * 1024,
+		100 * 1024 * 1024,
+		1024 * 1024 * 1024,
+	}
+
+	for _, size := range benchmarks {
+		buf := utils.RandBytes(b, size)
+
+		for _, cores := range coreCounts() {
+			// zeebo/blake3 has no multi-threaded mode, it is kept as the single-threaded SIMD reference
+			benchmarkParallelHasher(size, cores, "BLAKE3_zeebo", zeeboBlake3Hasher{}, buf, b)
+			benchmarkParallelHasher(size, cores, "BLAKE3_lukechampine", lukechampineBlake3Hasher{}, buf, b)
+			benchmarkParallelHasher(size, cores, "SHA-256-tree", sha256TreeHasher{leafSize: 64 * 1024}, buf, b)
+			benchmarkParallelHasher(size, cores, "ParallelHash128", parallelHash128Hasher{blockSize: 64 * 1024}, buf, b)
+			benchmarkParallelHasher(size, cores, "ParallelHash256", parallelHash256Hasher{blockSize: 64 * 1024}, buf, b)
+			benchmarkParallelHasher(size, cores, "KT128", kt128Hasher{}, buf, b)
+		}
+	}
+}
+
+func benchmarkParallelHasher[H Hasher](size int64, cores int, algorithm string, hasher H, buf []byte, b *testing.B) {
+	b.Run(fmt.Sprintf("%s-%s-%dcores", utils.BytesCount(size), algorithm, cores), func(b *testing.B) {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(cores))
+
+		b.ReportAllocs()
+		b.SetBytes(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			hasher.Hash(buf)
+		}
+		b.StopTimer()
+		b.ReportMetric(float64(size)*float64(b.N)/b.Elapsed().Seconds()/float64(cores)/1e6, "MB/s/core")
+	})
+}
+
+// coreCounts returns the powers of two up to the number of CPUs, and the number of CPUs itself.
+func coreCounts() (counts []int) {
+	numCPU := runtime.NumCPU()
+	for cores := 1; cores < numCPU; cores *= 2 {
+		counts = append(counts, cores)
+	}
+	return append(counts, numCPU)
+}
+
 func benchmarkHasher[H Hasher](size int64, algorithm string, hasher H, b *testing.B) {
 	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
 		b.ReportAllocs()
@@ -116,6 +175,18 @@ func (sha512Hasher) Hash(input []byte) {
 // 	sha512.Sum512_256(input)
 // }
 
+type asconHash256Hasher struct{}
+
+func (asconHash256Hasher) Hash(input []byte) {
+	ascon.SumHash256(input)
+}
+
+type asconXOF128_256Hasher struct{}
+
+func (asconXOF128_256Hasher) Hash(input []byte) {
+	ascon.SumXOF128(input, 32)
+}
+
 type sha1Hasher struct{}
 
 func (sha1Hasher) Hash(input []byte) {
@@ -145,3 +216,79 @@ type shake256_512Hasher struct{}
 func (shake256_512Hasher) Hash(input []byte) {
 	sha3.SumSHAKE256(input, 64)
 }
+
+type turboShake128_256Hasher struct{}
+
+func (turboShake128_256Hasher) Hash(input []byte) {
+	k12.SumTurboSHAKE128(input, 32, k12.DefaultSeparator)
+}
+
+type turboShake256_512Hasher struct{}
+
+func (turboShake256_512Hasher) Hash(input []byte) {
+	k12.SumTurboSHAKE256(input, 64, k12.DefaultSeparator)
+}
+
+type kt128Hasher struct{}
+
+func (kt128Hasher) Hash(input []byte) {
+	k12.SumKT128(input, 32, nil)
+}
+
+type kt256Hasher struct{}
+
+func (kt256Hasher) Hash(input []byte) {
+	k12.SumKT256(input, 64, nil)
+}
+
+// sha256TreeHasher splits the input into leaves of leafSize bytes that are hashed concurrently with
+// SHA-256 by GOMAXPROCS goroutines, then hashes the concatenation of the leaf digests.
+// Leaves and root are prefixed with a different byte so a leaf can't be confused with the root.
+type sha256TreeHasher struct {
+	leafSize int
+}
+
+func (hasher sha256TreeHasher) Hash(input []byte) {
+	leaves := (len(input) + hasher.leafSize - 1) / hasher.leafSize
+	digests := make([]byte, leaves*sha256.Size)
+	workers := min(runtime.GOMAXPROCS(0), leaves)
+
+	var wg sync.WaitGroup
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			leafHasher := sha256.New()
+			for leaf := worker; leaf < leaves; leaf += workers {
+				start := leaf * hasher.leafSize
+				end := min(start+hasher.leafSize, len(input))
+				leafHasher.Reset()
+				leafHasher.Write([]byte{0x00})
+				leafHasher.Write(input[start:end])
+				copy(digests[leaf*sha256.Size:], leafHasher.Sum(nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	rootHasher := sha256.New()
+	rootHasher.Write([]byte{0x01})
+	rootHasher.Write(digests)
+	rootHasher.Sum(nil)
+}
+
+type parallelHash128Hasher struct {
+	blockSize int
+}
+
+func (hasher parallelHash128Hasher) Hash(input []byte) {
+	sp800185.ParallelHash128(input, hasher.blockSize, 32, nil)
+}
+
+type parallelHash256Hasher struct {
+	blockSize int
+}
+
+func (hasher parallelHash256Hasher) Hash(input []byte) {
+	sp800185.ParallelHash256(input, hasher.blockSize, 64, nil)
+}
@@ -1,33 +1,91 @@
 package kdf
 
 import (
+	"bytes"
 	"crypto/hkdf"
-	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha3"
 	"crypto/sha512"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"testing"
 
+	"github.com/skerkour/go-benchmarks/crypto/ericlagergren/lwcrypto/ascon"
+	"github.com/skerkour/go-benchmarks/crypto/k12"
 	"github.com/skerkour/go-benchmarks/crypto/kmac"
 	"github.com/skerkour/go-benchmarks/utils"
 	"github.com/skerkour/stdx-go/crypto/chacha20"
 	zeeboblake3 "github.com/zeebo/blake3"
 	lukechampineblake3 "lukechampine.com/blake3"
+	lukechampineguts "lukechampine.com/blake3/guts"
 )
 
 type KDF interface {
 	DeriveKey(secret, info, out []byte)
 }
 
-func BenchmarkKDF(b *testing.B) {
-	benchmarks := []int64{
-		32,
-		64,
-		128,
-		256,
+// PreparableKDF is implemented by the KDFs that can precompute, once, the state of a fixed info
+// to derive keys from many secrets: the BLAKE3 derive-key context, the sponge state after
+// absorbing info...
+type PreparableKDF interface {
+	KDF
+	// Prepare returns a PreparedKDF whose Derive(secret, out) writes to out the same bytes as
+	// DeriveKey(secret, info, out).
+	Prepare(info []byte) PreparedKDF
+}
+
+// KeyPreparableKDF is implemented by the KDFs that can precompute, once, the state of a fixed
+// secret to derive many keys from it: the HKDF pseudorandom key, the absorbed KMAC key, the
+// ChaCha20 key schedule...
+type KeyPreparableKDF interface {
+	KDF
+	// PrepareKey returns a PreparedKDF whose Derive(info, out) writes to out the same bytes as
+	// DeriveKey(secret, info, out).
+	PrepareKey(secret []byte) PreparedKDF
+}
+
+// PreparedKDF derives keys from a precomputed state and the input that wasn't prepared: the
+// secret for a PreparableKDF and the info for a KeyPreparableKDF.
+type PreparedKDF interface {
+	Derive(input, out []byte)
+}
+
+// prepareKDF prepares kdf and returns the input that varies with each derivation, or false if
+// kdf can't be prepared.
+func prepareKDF(kdf KDF, secret, info []byte) (PreparedKDF, []byte, bool) {
+	switch kdf := kdf.(type) {
+	case PreparableKDF:
+		return kdf.Prepare(info), secret, true
+	case KeyPreparableKDF:
+		return kdf.PrepareKey(secret), info, true
 	}
+	return nil, nil, false
+}
+
+// boundedKDF is implemented by the KDFs that can't derive more than MaxOutputSize bytes, such as
+// HKDF which is limited to 255 blocks of the hash output.
+type boundedKDF interface {
+	MaxOutputSize() int
+}
+
+// outputSizes goes from the size of a single AEAD key to the output needed by protocols that
+// derive a lot of keying material at once.
+var outputSizes = []int64{
+	16,
+	32,
+	64,
+	128,
+	256,
+	1024,
+	4096,
+	8192,
+}
+
+func BenchmarkKDF(b *testing.B) {
+	benchmarks := outputSizes
 
 	info := []byte(base64.StdEncoding.EncodeToString(utils.RandBytes(b, 30)))
 	key := utils.RandBytes(b, 32)
@@ -36,16 +94,23 @@ func BenchmarkKDF(b *testing.B) {
 		benchmarkKDF(size, "HKDF-SHA2-256", sha256KDF{}, key, info, b)
 		benchmarkKDF(size, "HKDF-SHA2-512", sha512KDF{}, key, info, b)
 		benchmarkKDF(size, "SHAKE-256", shake256Kdf{}, key, info, b)
+		benchmarkKDF(size, "TurboSHAKE-256", turboShake256KDF{}, key, info, b)
+		benchmarkKDF(size, "KT128", kt128KDF{}, key, info, b)
+
+		benchmarkKDF(size, "Ascon-XOF128", asconXOF128KDF{}, key, info, b)
+		benchmarkKDF(size, "Ascon-CXOF128", asconCXOF128KDF{}, key, info, b)
 
 		benchmarkKDF(size, "KMAC-128", kmac128{}, key, info, b)
 		benchmarkKDF(size, "KMAC-256", kmac256{}, key, info, b)
+		benchmarkKDF(size, "KMAC-XOF-128", kmacXof128{}, key, info, b)
+		benchmarkKDF(size, "KMAC-XOF-256", kmacXof256{}, key, info, b)
 
 		benchmarkKDF(size, "BLAKE3_zeebo", zeeboBlake3KDF{}, key, info, b)
 		// benchmarkKDF(size, "BLAKE3-512_zeebo", zeeboBlake3_512KDF{}, key, info, output512, b)
 		benchmarkKDF(size, "BLAKE3_lukechampine", lukechampineBlake3KDF{}, key, info, b)
 		// benchmarkKDF(size, "BLAKE3-512_lukechampine", lukechampineBlake3_512KDF{}, key, info, output512, b)
 
-		benchmarkKDF(size, "ChaCha20", newChacha20KDF(key), key, info, b)
+		benchmarkKDF(size, "ChaCha20", chacha20KDF{}, key, info, b)
 		// benchmarkHasher(size, "blake2b_256", blake2bHasher{}, b)
 		// benchmarkHasher(size, "blake2s_256", blake2sHasher{}, b)
 		// benchmarkHasher("sha512/256", sha512_256Hasher{}, b)
@@ -57,6 +122,9 @@ func BenchmarkKDF(b *testing.B) {
 }
 
 func benchmarkKDF[H KDF](size int64, algorithm string, kdf H, key, info []byte, b *testing.B) {
+	if bounded, ok := any(kdf).(boundedKDF); ok && size > int64(bounded.MaxOutputSize()) {
+		return
+	}
 	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
 		b.ReportAllocs()
 		b.SetBytes(size)
@@ -68,12 +136,391 @@ func benchmarkKDF[H KDF](size int64, algorithm string, kdf H, key, info []byte,
 	})
 }
 
+// BenchmarkPreparedKDF benchmarks the per-derivation cost of the KDFs once the state of their
+// fixed input has been prepared, to compare with the cold one-shot cost of BenchmarkKDF. The other
+// input is still processed by each derivation.
+func BenchmarkPreparedKDF(b *testing.B) {
+	info := []byte(base64.StdEncoding.EncodeToString(utils.RandBytes(b, 30)))
+	key := utils.RandBytes(b, 32)
+
+	for _, size := range outputSizes {
+		benchmarkPreparedKDF(size, "HKDF-SHA2-256", sha256KDF{}, key, info, b)
+		benchmarkPreparedKDF(size, "HKDF-SHA2-512", sha512KDF{}, key, info, b)
+		benchmarkPreparedKDF(size, "SHAKE-256", shake256Kdf{}, key, info, b)
+		benchmarkPreparedKDF(size, "TurboSHAKE-256", turboShake256KDF{}, key, info, b)
+		benchmarkPreparedKDF(size, "KT128", kt128KDF{}, key, info, b)
+
+		benchmarkPreparedKDF(size, "Ascon-XOF128", asconXOF128KDF{}, key, info, b)
+		benchmarkPreparedKDF(size, "Ascon-CXOF128", asconCXOF128KDF{}, key, info, b)
+
+		benchmarkPreparedKDF(size, "KMAC-XOF-128", kmacXof128{}, key, info, b)
+		benchmarkPreparedKDF(size, "KMAC-XOF-256", kmacXof256{}, key, info, b)
+
+		benchmarkPreparedKDF(size, "BLAKE3_zeebo", zeeboBlake3KDF{}, key, info, b)
+		benchmarkPreparedKDF(size, "BLAKE3_lukechampine", lukechampineBlake3KDF{}, key, info, b)
+
+		benchmarkPreparedKDF(size, "ChaCha20", chacha20KDF{}, key, info, b)
+	}
+}
+
+func benchmarkPreparedKDF[H KDF](size int64, algorithm string, kdf H, key, info []byte, b *testing.B) {
+	if bounded, ok := any(kdf).(boundedKDF); ok && size > int64(bounded.MaxOutputSize()) {
+		return
+	}
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		prepared, input, ok := prepareKDF(kdf, key, info)
+		if !ok {
+			b.Fatalf("%s can't be prepared", algorithm)
+		}
+		b.ReportAllocs()
+		b.SetBytes(size)
+		b.ResetTimer()
+		output := make([]byte, size)
+		for i := 0; i < b.N; i++ {
+			prepared.Derive(input, output)
+		}
+	})
+}
+
+// TestPreparedKDF checks that the prepared KDFs derive the same keys as the one-shot ones, for
+// several values of the input that wasn't prepared.
+func TestPreparedKDF(t *testing.T) {
+	info := []byte("test info")
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	for _, test := range []struct {
+		name string
+		kdf  KDF
+	}{
+		{"HKDF-SHA2-256", sha256KDF{}},
+		{"HKDF-SHA2-512", sha512KDF{}},
+		{"SHAKE-256", shake256Kdf{}},
+		{"TurboSHAKE-256", turboShake256KDF{}},
+		{"KT128", kt128KDF{}},
+		{"Ascon-XOF128", asconXOF128KDF{}},
+		{"Ascon-CXOF128", asconCXOF128KDF{}},
+		{"KMAC-XOF-128", kmacXof128{}},
+		{"KMAC-XOF-256", kmacXof256{}},
+		{"BLAKE3_zeebo", zeeboBlake3KDF{}},
+		{"BLAKE3_lukechampine", lukechampineBlake3KDF{}},
+		{"ChaCha20", chacha20KDF{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			prepared, _, ok := prepareKDF(test.kdf, key, info)
+			if !ok {
+				t.Fatal("the KDF can't be prepared")
+			}
+			_, keyPrepared := test.kdf.(KeyPreparableKDF)
+			// the prepared state must not be modified by a derivation, so the first input is
+			// derived again after one longer than a block of all the KDFs
+			inputs := [][]byte{key, bytes.Repeat([]byte{0x17}, 300), key}
+			if keyPrepared {
+				inputs = [][]byte{info, bytes.Repeat([]byte{0x17}, 300), info}
+			}
+			// more output than a 1 KiB buffer of BLAKE3 blocks
+			for _, input := range inputs {
+				secret, info := input, info
+				if keyPrepared {
+					secret, info = key, input
+				}
+				expected := make([]byte, 1500)
+				test.kdf.DeriveKey(secret, info, expected)
+				if bytes.Equal(expected, make([]byte, 1500)) {
+					t.Fatal("DeriveKey didn't write the output")
+				}
+				got := make([]byte, 1500)
+				prepared.Derive(input, got)
+				if !bytes.Equal(got, expected) {
+					t.Fatalf("expected %x, got %x", expected, got)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHKDFExtract benchmarks HKDF-Extract on input keying material of the size of the
+// X25519, P-384 and P-521 shared secrets.
+func BenchmarkHKDFExtract(b *testing.B) {
+	benchmarks := []int64{
+		32,
+		48,
+		66,
+	}
+
+	salt := utils.RandBytes(b, 32)
+
+	for _, size := range benchmarks {
+		benchmarkHKDFExtract(size, "HKDF-SHA2-256", sha256.New, salt, b)
+		benchmarkHKDFExtract(size, "HKDF-SHA2-512", sha512.New, salt, b)
+	}
+}
+
+func benchmarkHKDFExtract(size int64, algorithm string, h func() hash.Hash, salt []byte, b *testing.B) {
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		secret := utils.RandBytes(b, size)
+		b.ReportAllocs()
+		b.SetBytes(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := hkdf.Extract(h, secret, salt)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkHKDFExpand benchmarks HKDF-Expand alone, from an already extracted pseudorandom key,
+// which is what protocols like TLS 1.3 or MLS do many times per Extract.
+func BenchmarkHKDFExpand(b *testing.B) {
+	info := "tls13 c hs traffic"
+
+	for _, size := range outputSizes {
+		benchmarkHKDFExpand(size, "HKDF-SHA2-256", sha256.New, info, b)
+		benchmarkHKDFExpand(size, "HKDF-SHA2-512", sha512.New, info, b)
+	}
+}
+
+func benchmarkHKDFExpand(size int64, algorithm string, h func() hash.Hash, info string, b *testing.B) {
+	if size > int64(255*h().Size()) {
+		return
+	}
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		prk := utils.RandBytes(b, int64(h().Size()))
+		b.ReportAllocs()
+		b.SetBytes(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := hkdf.Expand(h, prk, info, int(size))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkTLS13KeySchedule benchmarks the key schedule of a full TLS 1.3 handshake without PSK
+// (section 7.1 of RFC 8446): 3 Extract and 7 Derive-Secret to get the handshake, application,
+// exporter and resumption secrets, then the finished keys and the traffic keys and IVs.
+func BenchmarkTLS13KeySchedule(b *testing.B) {
+	benchmarkTLS13KeySchedule("TLS_AES_128_GCM_SHA256", sha256.New, 16, b)
+	benchmarkTLS13KeySchedule("TLS_AES_256_GCM_SHA384", sha512.New384, 32, b)
+}
+
+func benchmarkTLS13KeySchedule(cipherSuite string, h func() hash.Hash, keySize int, b *testing.B) {
+	b.Run(cipherSuite, func(b *testing.B) {
+		sharedSecret := utils.RandBytes(b, 32)
+		helloHash := utils.RandBytes(b, int64(h().Size()))
+		finishedHash := utils.RandBytes(b, int64(h().Size()))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := tls13KeySchedule(h, keySize, sharedSecret, helloHash, finishedHash)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// tls13Keys are the secrets and keys derived by the TLS 1.3 key schedule of a full handshake.
+type tls13Keys struct {
+	handshakeSecret                           []byte
+	clientHandshakeTrafficSecret              []byte
+	serverHandshakeTrafficSecret              []byte
+	masterSecret                              []byte
+	clientApplicationTrafficSecret            []byte
+	serverApplicationTrafficSecret            []byte
+	exporterMasterSecret                      []byte
+	resumptionMasterSecret                    []byte
+	clientFinishedKey                         []byte
+	serverFinishedKey                         []byte
+	clientHandshakeKey, clientHandshakeIV     []byte
+	serverHandshakeKey, serverHandshakeIV     []byte
+	clientApplicationKey, clientApplicationIV []byte
+	serverApplicationKey, serverApplicationIV []byte
+}
+
+// tls13KeySchedule runs the key schedule of section 7.1 of RFC 8446 without PSK. helloHash is the
+// transcript hash up to the ServerHello and finishedHash up to the server Finished. The
+// resumption master secret is derived from finishedHash too, instead of the hash up to the
+// client Finished, as it doesn't change the cost.
+func tls13KeySchedule(h func() hash.Hash, keySize int, sharedSecret, helloHash, finishedHash []byte) (keys tls13Keys, err error) {
+	zeros := make([]byte, h().Size())
+
+	earlySecret, err := hkdf.Extract(h, zeros, nil)
+	if err != nil {
+		return
+	}
+	derived, err := tls13DeriveSecret(h, earlySecret, "derived", nil)
+	if err != nil {
+		return
+	}
+	keys.handshakeSecret, err = hkdf.Extract(h, sharedSecret, derived)
+	if err != nil {
+		return
+	}
+	keys.clientHandshakeTrafficSecret, err = tls13DeriveSecret(h, keys.handshakeSecret, "c hs traffic", helloHash)
+	if err != nil {
+		return
+	}
+	keys.serverHandshakeTrafficSecret, err = tls13DeriveSecret(h, keys.handshakeSecret, "s hs traffic", helloHash)
+	if err != nil {
+		return
+	}
+	derived, err = tls13DeriveSecret(h, keys.handshakeSecret, "derived", nil)
+	if err != nil {
+		return
+	}
+	keys.masterSecret, err = hkdf.Extract(h, zeros, derived)
+	if err != nil {
+		return
+	}
+	keys.clientApplicationTrafficSecret, err = tls13DeriveSecret(h, keys.masterSecret, "c ap traffic", finishedHash)
+	if err != nil {
+		return
+	}
+	keys.serverApplicationTrafficSecret, err = tls13DeriveSecret(h, keys.masterSecret, "s ap traffic", finishedHash)
+	if err != nil {
+		return
+	}
+	keys.exporterMasterSecret, err = tls13DeriveSecret(h, keys.masterSecret, "exp master", finishedHash)
+	if err != nil {
+		return
+	}
+	keys.resumptionMasterSecret, err = tls13DeriveSecret(h, keys.masterSecret, "res master", finishedHash)
+	if err != nil {
+		return
+	}
+
+	keys.clientFinishedKey, err = tls13ExpandLabel(h, keys.clientHandshakeTrafficSecret, "finished", nil, h().Size())
+	if err != nil {
+		return
+	}
+	keys.serverFinishedKey, err = tls13ExpandLabel(h, keys.serverHandshakeTrafficSecret, "finished", nil, h().Size())
+	if err != nil {
+		return
+	}
+
+	for _, traffic := range []struct {
+		secret  []byte
+		key, iv *[]byte
+	}{
+		{keys.clientHandshakeTrafficSecret, &keys.clientHandshakeKey, &keys.clientHandshakeIV},
+		{keys.serverHandshakeTrafficSecret, &keys.serverHandshakeKey, &keys.serverHandshakeIV},
+		{keys.clientApplicationTrafficSecret, &keys.clientApplicationKey, &keys.clientApplicationIV},
+		{keys.serverApplicationTrafficSecret, &keys.serverApplicationKey, &keys.serverApplicationIV},
+	} {
+		*traffic.key, err = tls13ExpandLabel(h, traffic.secret, "key", nil, keySize)
+		if err != nil {
+			return
+		}
+		*traffic.iv, err = tls13ExpandLabel(h, traffic.secret, "iv", nil, 12)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// tls13ExpandLabel is HKDF-Expand-Label, specified in section 7.1 of RFC 8446.
+func tls13ExpandLabel(h func() hash.Hash, secret []byte, label string, context []byte, length int) ([]byte, error) {
+	hkdfLabel := make([]byte, 0, 2+1+6+len(label)+1+len(context))
+	hkdfLabel = binary.BigEndian.AppendUint16(hkdfLabel, uint16(length))
+	hkdfLabel = append(hkdfLabel, byte(6+len(label)))
+	hkdfLabel = append(hkdfLabel, "tls13 "...)
+	hkdfLabel = append(hkdfLabel, label...)
+	hkdfLabel = append(hkdfLabel, byte(len(context)))
+	hkdfLabel = append(hkdfLabel, context...)
+	return hkdf.Expand(h, secret, string(hkdfLabel), length)
+}
+
+// tls13DeriveSecret is Derive-Secret, specified in section 7.1 of RFC 8446, with the transcript
+// hash already computed. A nil transcriptHash is the hash of the empty string.
+func tls13DeriveSecret(h func() hash.Hash, secret []byte, label string, transcriptHash []byte) ([]byte, error) {
+	if transcriptHash == nil {
+		transcriptHash = h().Sum(nil)
+	}
+	return tls13ExpandLabel(h, secret, label, transcriptHash, h().Size())
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// TestTLS13KeySchedule checks the key schedule against the "Simple 1-RTT Handshake" trace of
+// RFC 8448.
+func TestTLS13KeySchedule(t *testing.T) {
+	sharedSecret := decodeHex(t, "8bd4054fb55b9d63fdfbacf9f04b9f0d35e6d63f537563efd46272900f89492d")
+	helloHash := decodeHex(t, "860c06edc07858ee8e78f0e7428c58edd6b43f2ca3e6e95f02ed063cf0e1cad8")
+	finishedHash := decodeHex(t, "9608102a0f1ccc6db6250b7b7e417b1a000eaada3daae4777a7686c9ff83df13")
+
+	keys, err := tls13KeySchedule(sha256.New, 16, sharedSecret, helloHash, finishedHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		got      []byte
+		expected string
+	}{
+		{"handshake secret", keys.handshakeSecret, "1dc826e93606aa6fdc0aadc12f741b01046aa6b99f691ed221a9f0ca043fbeac"},
+		{"client handshake traffic secret", keys.clientHandshakeTrafficSecret, "b3eddb126e067f35a780b3abf45e2d8f3b1a950738f52e9600746a0e27a55a21"},
+		{"server handshake traffic secret", keys.serverHandshakeTrafficSecret, "b67b7d690cc16c4e75e54213cb2d37b4e9c912bcded9105d42befd59d391ad38"},
+		{"master secret", keys.masterSecret, "18df06843d13a08bf2a449844c5f8a478001bc4d4c627984d5a41da8d0402919"},
+		{"server handshake key", keys.serverHandshakeKey, "3fce516009c21727d0f2e4e86ee403bc"},
+		{"server handshake iv", keys.serverHandshakeIV, "5d313eb2671276ee13000b30"},
+	} {
+		if hex.EncodeToString(test.got) != test.expected {
+			t.Errorf("%s: expected %s, got %x", test.name, test.expected, test.got)
+		}
+	}
+}
+
 type lukechampineBlake3KDF struct{}
 
 func (lukechampineBlake3KDF) DeriveKey(secret, info, out []byte) {
 	lukechampineblake3.DeriveKey(out, string(info), secret)
 }
 
+// Prepare hashes the derive-key context with the compression function of the guts package, as
+// the package doesn't export a Hasher for the key material. Both the context and the secrets must
+// fit in a single chunk, which they do in this benchmark.
+func (lukechampineBlake3KDF) Prepare(info []byte) PreparedKDF {
+	if len(info) > lukechampineguts.ChunkSize {
+		panic("BLAKE3 context longer than a chunk")
+	}
+	node := lukechampineguts.CompressChunk(info, &lukechampineguts.IV, 0, lukechampineguts.FlagDeriveKeyContext)
+	node.Flags |= lukechampineguts.FlagRoot
+	return lukechampineBlake3PreparedKDF{key: lukechampineguts.ChainingValue(node)}
+}
+
+type lukechampineBlake3PreparedKDF struct {
+	key [8]uint32
+}
+
+func (kdf lukechampineBlake3PreparedKDF) Derive(secret, out []byte) {
+	if len(secret) > lukechampineguts.ChunkSize {
+		panic("BLAKE3 key material longer than a chunk")
+	}
+	node := lukechampineguts.CompressChunk(secret, &kdf.key, 0, lukechampineguts.FlagDeriveKeyMaterial)
+	node.Flags |= lukechampineguts.FlagRoot
+	// the output blocks are the root node compressed with successive counters
+	var buf [lukechampineguts.MaxSIMD * lukechampineguts.BlockSize]byte
+	for len(out) > 0 {
+		lukechampineguts.CompressBlocks(&buf, node)
+		out = out[copy(out, buf[:]):]
+		node.Counter += lukechampineguts.MaxSIMD
+	}
+}
+
 // type lukechampineBlake3_512KDF struct{}
 
 // func (lukechampineBlake3_512KDF) DeriveKey(secret, info, out []byte) {
@@ -83,7 +530,22 @@ func (lukechampineBlake3KDF) DeriveKey(secret, info, out []byte) {
 type zeeboBlake3KDF struct{}
 
 func (zeeboBlake3KDF) DeriveKey(secret, info, out []byte) {
-	zeeboblake3.DeriveKey(string(info), secret, out[:0])
+	zeeboblake3.DeriveKey(string(info), secret, out)
+}
+
+func (zeeboBlake3KDF) Prepare(info []byte) PreparedKDF {
+	return zeeboBlake3PreparedKDF{hasher: zeeboblake3.NewDeriveKey(string(info))}
+}
+
+// zeeboBlake3PreparedKDF has already hashed the derive-key context, and Reset keeps it.
+type zeeboBlake3PreparedKDF struct {
+	hasher *zeeboblake3.Hasher
+}
+
+func (kdf zeeboBlake3PreparedKDF) Derive(secret, out []byte) {
+	kdf.hasher.Reset()
+	kdf.hasher.Write(secret)
+	kdf.hasher.Digest().Read(out)
 }
 
 // type zeeboBlake3_512KDF struct{}
@@ -92,26 +554,37 @@ func (zeeboBlake3KDF) DeriveKey(secret, info, out []byte) {
 // 	zeeboblake3.DeriveKey(string(info), secret, out)
 // }
 
-type chacha20KDF struct {
-	cipher chacha20.StreamCipher
-}
+// chacha20KDF uses the secret as the key of a ChaCha20 keystream, with a zero nonce. It ignores
+// info.
+type chacha20KDF struct{}
 
-func newChacha20KDF(secret []byte) chacha20KDF {
+func (chacha20KDF) DeriveKey(secret, info, out []byte) {
 	var nonce [8]byte
-	rand.Read(nonce[:])
-
 	cipher, err := chacha20.New(secret, nonce[:])
 	if err != nil {
 		panic(err)
 	}
+	clear(out)
+	cipher.XORKeyStream(out, out)
+}
 
-	return chacha20KDF{
-		cipher: cipher,
+func (chacha20KDF) PrepareKey(secret []byte) PreparedKDF {
+	var nonce [8]byte
+	cipher, err := chacha20.New(secret, nonce[:])
+	if err != nil {
+		panic(err)
 	}
+	return chacha20PreparedKDF{cipher: cipher}
+}
+
+type chacha20PreparedKDF struct {
+	cipher chacha20.StreamCipher
 }
 
-func (kdf chacha20KDF) DeriveKey(secret, info, out []byte) {
-	kdf.cipher.XORKeyStream(out[:], out[:])
+func (kdf chacha20PreparedKDF) Derive(info, out []byte) {
+	kdf.cipher.SetCounter(0)
+	clear(out)
+	kdf.cipher.XORKeyStream(out, out)
 }
 
 // type blake2sHasher struct{}
@@ -135,19 +608,59 @@ func (kdf chacha20KDF) DeriveKey(secret, info, out []byte) {
 type sha256KDF struct{}
 
 func (sha256KDF) DeriveKey(secret, info, out []byte) {
-	out, err := hkdf.Key(sha256.New, secret, nil, string(info), len(out))
+	key, err := hkdf.Key(sha256.New, secret, nil, string(info), len(out))
 	if err != nil {
 		panic(err)
 	}
+	copy(out, key)
+}
+
+func (sha256KDF) MaxOutputSize() int {
+	return 255 * sha256.Size
+}
+
+func (sha256KDF) PrepareKey(secret []byte) PreparedKDF {
+	return newHKDFPrepared(sha256.New, secret)
 }
 
 type sha512KDF struct{}
 
 func (sha512KDF) DeriveKey(secret, info, out []byte) {
-	out, err := hkdf.Key(sha512.New, secret, nil, string(info), len(out))
+	key, err := hkdf.Key(sha512.New, secret, nil, string(info), len(out))
+	if err != nil {
+		panic(err)
+	}
+	copy(out, key)
+}
+
+func (sha512KDF) MaxOutputSize() int {
+	return 255 * sha512.Size
+}
+
+func (sha512KDF) PrepareKey(secret []byte) PreparedKDF {
+	return newHKDFPrepared(sha512.New, secret)
+}
+
+// hkdfPreparedKDF has already run HKDF-Extract.
+type hkdfPreparedKDF struct {
+	h   func() hash.Hash
+	prk []byte
+}
+
+func newHKDFPrepared(h func() hash.Hash, secret []byte) hkdfPreparedKDF {
+	prk, err := hkdf.Extract(h, secret, nil)
+	if err != nil {
+		panic(err)
+	}
+	return hkdfPreparedKDF{h: h, prk: prk}
+}
+
+func (kdf hkdfPreparedKDF) Derive(info, out []byte) {
+	key, err := hkdf.Expand(kdf.h, kdf.prk, string(info), len(out))
 	if err != nil {
 		panic(err)
 	}
+	copy(out, key)
 }
 
 type shake256Kdf struct{}
@@ -159,6 +672,129 @@ func (shake256Kdf) DeriveKey(secret, info, out []byte) {
 	hasher.Read(out)
 }
 
+// Prepare keeps the marshaled state of the SHAKE after absorbing info, as crypto/sha3 has no
+// Clone method.
+func (shake256Kdf) Prepare(info []byte) PreparedKDF {
+	hasher := sha3.NewSHAKE256()
+	hasher.Write(info)
+	state, err := hasher.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return shake256PreparedKDF{hasher: hasher, state: state}
+}
+
+type shake256PreparedKDF struct {
+	hasher *sha3.SHAKE
+	state  []byte
+}
+
+func (kdf shake256PreparedKDF) Derive(secret, out []byte) {
+	if err := kdf.hasher.UnmarshalBinary(kdf.state); err != nil {
+		panic(err)
+	}
+	kdf.hasher.Write(secret)
+	kdf.hasher.Read(out)
+}
+
+type turboShake256KDF struct{}
+
+func (turboShake256KDF) DeriveKey(secret, info, out []byte) {
+	hasher := k12.NewTurboSHAKE256(k12.DefaultSeparator)
+	hasher.Write(info)
+	hasher.Write(secret)
+	hasher.Read(out)
+}
+
+func (turboShake256KDF) Prepare(info []byte) PreparedKDF {
+	hasher := k12.NewTurboSHAKE256(k12.DefaultSeparator)
+	hasher.Write(info)
+	return turboShake256PreparedKDF{hasher: hasher}
+}
+
+type turboShake256PreparedKDF struct {
+	hasher *k12.TurboSHAKE
+}
+
+func (kdf turboShake256PreparedKDF) Derive(secret, out []byte) {
+	// copy the absorbed state by value to not allocate a clone
+	hasher := *kdf.hasher
+	hasher.Write(secret)
+	hasher.Read(out)
+}
+
+// kt128KDF uses info as the customization string
+type kt128KDF struct{}
+
+func (kt128KDF) DeriveKey(secret, info, out []byte) {
+	xof := k12.NewKT128(info)
+	xof.Write(secret)
+	xof.Read(out)
+}
+
+// Prepare only creates the KT128 instance: the customization string is absorbed after the
+// message, so there is no state to precompute.
+func (kt128KDF) Prepare(info []byte) PreparedKDF {
+	return kt128PreparedKDF{xof: k12.NewKT128(info)}
+}
+
+type kt128PreparedKDF struct {
+	xof *k12.KT
+}
+
+func (kdf kt128PreparedKDF) Derive(secret, out []byte) {
+	kdf.xof.Reset()
+	kdf.xof.Write(secret)
+	kdf.xof.Read(out)
+}
+
+type asconXOF128KDF struct{}
+
+func (asconXOF128KDF) DeriveKey(secret, info, out []byte) {
+	xof := ascon.NewXOF128()
+	xof.Write(info)
+	xof.Write(secret)
+	xof.Read(out)
+}
+
+func (asconXOF128KDF) Prepare(info []byte) PreparedKDF {
+	xof := ascon.NewXOF128()
+	xof.Write(info)
+	return asconPreparedKDF{xof: xof}
+}
+
+// asconPreparedKDF is shared by Ascon-XOF128 and Ascon-CXOF128.
+type asconPreparedKDF struct {
+	xof *ascon.XOF
+}
+
+func (kdf asconPreparedKDF) Derive(secret, out []byte) {
+	// copy the absorbed state by value to not allocate a clone
+	xof := *kdf.xof
+	xof.Write(secret)
+	xof.Read(out)
+}
+
+// asconCXOF128KDF uses info as the customization string
+type asconCXOF128KDF struct{}
+
+func (asconCXOF128KDF) DeriveKey(secret, info, out []byte) {
+	xof, err := ascon.NewCXOF128(info)
+	if err != nil {
+		panic(err)
+	}
+	xof.Write(secret)
+	xof.Read(out)
+}
+
+func (asconCXOF128KDF) Prepare(info []byte) PreparedKDF {
+	xof, err := ascon.NewCXOF128(info)
+	if err != nil {
+		panic(err)
+	}
+	return asconPreparedKDF{xof: xof}
+}
+
 // type sha512_256Hasher struct{}
 // func (sha512_256Hasher) Hash(input []byte) {
 // 	sha512.Sum512_256(input)
@@ -179,7 +815,7 @@ func (shake256Kdf) DeriveKey(secret, info, out []byte) {
 type kmac128 struct{}
 
 func (kmac128) DeriveKey(key, input, output []byte) {
-	hasher := kmac.NewKMAC128(key, 64, []byte("KDF"))
+	hasher := kmac.NewKMAC128(key, len(output), []byte("KDF"))
 	hasher.Write(input)
 	hasher.Sum(output[:0])
 }
@@ -191,3 +827,41 @@ func (kmac256) DeriveKey(key, input, output []byte) {
 	hasher.Write(input)
 	hasher.Sum(output[:0])
 }
+
+type kmacXof128 struct{}
+
+func (kmacXof128) DeriveKey(key, input, output []byte) {
+	xof := kmac.NewKMACXOF128(key, []byte("KDF"))
+	xof.Write(input)
+	xof.Read(output)
+}
+
+func (kmacXof128) PrepareKey(key []byte) PreparedKDF {
+	xof := kmac.NewKMACXOF128(key, []byte("KDF"))
+	return kmacXofPreparedKDF{xof: xof}
+}
+
+// kmacXofPreparedKDF is shared by KMAC-XOF-128 and KMAC-XOF-256. KMAC with a fixed output length
+// isn't prepared as the output length is bound when creating it.
+type kmacXofPreparedKDF struct {
+	xof *kmac.KmacXOF
+}
+
+func (kdf kmacXofPreparedKDF) Derive(input, out []byte) {
+	xof := kdf.xof.Clone()
+	xof.Write(input)
+	xof.Read(out)
+}
+
+type kmacXof256 struct{}
+
+func (kmacXof256) DeriveKey(key, input, output []byte) {
+	xof := kmac.NewKMACXOF256(key, []byte("KDF"))
+	xof.Write(input)
+	xof.Read(output)
+}
+
+func (kmacXof256) PrepareKey(key []byte) PreparedKDF {
+	xof := kmac.NewKMACXOF256(key, []byte("KDF"))
+	return kmacXofPreparedKDF{xof: xof}
+}
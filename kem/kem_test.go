@@ -1,32 +1,53 @@
 package kem
 
 import (
+	"crypto/ecdh"
 	"crypto/mlkem"
 	"testing"
 
+	"github.com/skerkour/go-benchmarks/crypto/dhkem"
+	"github.com/skerkour/go-benchmarks/crypto/hybridkem"
 	"github.com/skerkour/go-benchmarks/crypto/xwing"
+	"github.com/skerkour/go-benchmarks/utils"
 )
 
 type EncapulationKey interface {
-	Encapsulate() (ciphertext, sharedKey []byte)
+	Encapsulate() (ciphertext, sharedKey []byte, err error)
 }
 
 type DecapsulationKey interface {
 	Decapsulate(ciphertext []byte) (sharedKey []byte, err error)
 }
 
+// dhkemSchemes are the classical KEMs, which are the baseline of the post-quantum and hybrid
+// KEMs.
+var dhkemSchemes = []*dhkem.Scheme{
+	dhkem.X25519HKDFSHA256,
+	dhkem.P256HKDFSHA256,
+	dhkem.P384HKDFSHA384,
+	dhkem.P521HKDFSHA512,
+}
+
 func BenchmarkEncapsulate(b *testing.B) {
+	for _, scheme := range dhkemSchemes {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkEncapsulate(scheme.String(), dk.EncapsulationKey(), b)
+	}
+
 	mlKem768, err := mlkem.GenerateKey768()
 	if err != nil {
 		b.Fatal(err)
 	}
-	mlKem768EncapsulationKey := mlKem768.EncapsulationKey()
+	mlKem768EncapsulationKey := mlKem768EncapsulationKey{mlKem768.EncapsulationKey()}
 
 	mlKem1024, err := mlkem.GenerateKey1024()
 	if err != nil {
 		b.Fatal(err)
 	}
-	mlKem1024EncapsulationKey := mlKem1024.EncapsulationKey()
+	mlKem1024EncapsulationKey := mlKem1024EncapsulationKey{mlKem1024.EncapsulationKey()}
 
 	xwing, err := xwing.GenerateKey()
 	if err != nil {
@@ -37,9 +58,29 @@ func BenchmarkEncapsulate(b *testing.B) {
 	benchmarkEncapsulate("ML-KEM-768", mlKem768EncapsulationKey, b)
 	benchmarkEncapsulate("ML-KEM-1024", mlKem1024EncapsulationKey, b)
 	benchmarkEncapsulate("X-Wing", xwingEncapsulationKey, b)
+
+	for _, scheme := range hybridSchemes(b) {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkEncapsulate(scheme.String(), dk.EncapsulationKey(), b)
+	}
 }
 
 func BenchmarkDecapsulate(b *testing.B) {
+	for _, scheme := range dhkemSchemes {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		ciphertext, _, err := dk.EncapsulationKey().Encapsulate()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkDecapsulate(scheme.String(), dk, ciphertext, b)
+	}
+
 	mlKem768, err := mlkem.GenerateKey768()
 	if err != nil {
 		b.Fatal(err)
@@ -69,11 +110,197 @@ func BenchmarkDecapsulate(b *testing.B) {
 		b.Fatal(err)
 	}
 	xwingKemEncapsulationKey := xwingKem.EncapsulationKey()
-	xwingCiphertext, _ := xwingKemEncapsulationKey.Encapsulate()
+	xwingCiphertext, _, err := xwingKemEncapsulationKey.Encapsulate()
+	if err != nil {
+		b.Fatal(err)
+	}
 
 	benchmarkDecapsulate("ML-KEM-768", mlKem768, mlKem768Ciphertext, b)
 	benchmarkDecapsulate("ML-KEM-1024", mlKem1024, mlKem1024Ciphertext, b)
 	benchmarkDecapsulate("X-Wing", xwingKem, xwingCiphertext, b)
+
+	for _, scheme := range hybridSchemes(b) {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		ciphertext, _, err := dk.EncapsulationKey().Encapsulate()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkDecapsulate(scheme.String(), dk, ciphertext, b)
+	}
+}
+
+// BenchmarkKeyGen benchmarks the generation of a random decapsulation key, as done for each
+// handshake by ephemeral-key protocols.
+func BenchmarkKeyGen(b *testing.B) {
+	for _, scheme := range dhkemSchemes {
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.GenerateKey()
+			return err
+		}, b)
+	}
+	benchmarkKeyGen("ML-KEM-768", func() error {
+		_, err := mlkem.GenerateKey768()
+		return err
+	}, b)
+	benchmarkKeyGen("ML-KEM-1024", func() error {
+		_, err := mlkem.GenerateKey1024()
+		return err
+	}, b)
+	benchmarkKeyGen("X-Wing", func() error {
+		_, err := xwing.GenerateKey()
+		return err
+	}, b)
+	for _, scheme := range hybridSchemes(b) {
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.GenerateKey()
+			return err
+		}, b)
+	}
+}
+
+// BenchmarkKeyGenFromSeed benchmarks the deterministic expansion of a seed into a decapsulation
+// key, which is what happens when loading a stored key.
+func BenchmarkKeyGenFromSeed(b *testing.B) {
+	mlKemSeed := utils.RandBytes(b, mlkem.SeedSize)
+	xwingSeed := utils.RandBytes(b, xwing.SeedSize)
+
+	for _, scheme := range dhkemSchemes {
+		ikm := utils.RandBytes(b, int64(scheme.DecapsulationKeySize()))
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.DeriveKeyPair(ikm)
+			return err
+		}, b)
+	}
+
+	benchmarkKeyGen("ML-KEM-768", func() error {
+		_, err := mlkem.NewDecapsulationKey768(mlKemSeed)
+		return err
+	}, b)
+	benchmarkKeyGen("ML-KEM-1024", func() error {
+		_, err := mlkem.NewDecapsulationKey1024(mlKemSeed)
+		return err
+	}, b)
+	benchmarkKeyGen("X-Wing", func() error {
+		_, err := xwing.NewKeyFromSeed(xwingSeed)
+		return err
+	}, b)
+	hybridSeed := utils.RandBytes(b, hybridkem.SeedSize)
+	for _, scheme := range hybridSchemes(b) {
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.NewKeyFromSeed(hybridSeed)
+			return err
+		}, b)
+	}
+}
+
+// BenchmarkParseEncapsulationKey benchmarks the parsing and validation of an encapsulation key
+// received from a peer, which happens before each encapsulation to a new key.
+func BenchmarkParseEncapsulationKey(b *testing.B) {
+	for _, scheme := range dhkemSchemes {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		encapsulationKey := dk.EncapsulationKey().Bytes()
+		benchmarkParseEncapsulationKey(scheme.String(), func() error {
+			_, err := scheme.NewEncapsulationKey(encapsulationKey)
+			return err
+		}, b)
+	}
+
+	mlKem768, err := mlkem.GenerateKey768()
+	if err != nil {
+		b.Fatal(err)
+	}
+	mlKem768EncapsulationKey := mlKem768.EncapsulationKey().Bytes()
+
+	mlKem1024, err := mlkem.GenerateKey1024()
+	if err != nil {
+		b.Fatal(err)
+	}
+	mlKem1024EncapsulationKey := mlKem1024.EncapsulationKey().Bytes()
+
+	xwingKem, err := xwing.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	xwingEncapsulationKey := xwingKem.EncapsulationKey().Bytes()
+
+	benchmarkParseEncapsulationKey("ML-KEM-768", func() error {
+		_, err := mlkem.NewEncapsulationKey768(mlKem768EncapsulationKey)
+		return err
+	}, b)
+	benchmarkParseEncapsulationKey("ML-KEM-1024", func() error {
+		_, err := mlkem.NewEncapsulationKey1024(mlKem1024EncapsulationKey)
+		return err
+	}, b)
+	benchmarkParseEncapsulationKey("X-Wing", func() error {
+		_, err := xwing.NewEncapsulationKey(xwingEncapsulationKey)
+		return err
+	}, b)
+	for _, scheme := range hybridSchemes(b) {
+		dk, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		encapsulationKey := dk.EncapsulationKey().Bytes()
+		benchmarkParseEncapsulationKey(scheme.String(), func() error {
+			_, err := scheme.NewEncapsulationKey(encapsulationKey)
+			return err
+		}, b)
+	}
+}
+
+// hybridSchemes returns the generic hybrid KEMs to benchmark: X-Wing built from its components,
+// to measure the cost of the abstraction, the NIST curves with the KitchenSink combiner, and the
+// ConcatKDF combiner to compare the cost of the combiners.
+func hybridSchemes(b *testing.B) []*hybridkem.Scheme {
+	var schemes []*hybridkem.Scheme
+	for _, params := range []struct {
+		mlKem    *hybridkem.MLKEM
+		curve    ecdh.Curve
+		combiner hybridkem.Combiner
+		label    string
+	}{
+		{hybridkem.MLKEM768, ecdh.X25519(), hybridkem.XWing, hybridkem.XWingLabel},
+		{hybridkem.MLKEM768, ecdh.P256(), hybridkem.KitchenSink, "MLKEM768-P256"},
+		{hybridkem.MLKEM768, ecdh.P256(), hybridkem.ConcatKDF, "MLKEM768-P256"},
+		{hybridkem.MLKEM1024, ecdh.P384(), hybridkem.KitchenSink, "MLKEM1024-P384"},
+	} {
+		scheme, err := hybridkem.New(params.mlKem, params.curve, params.combiner, []byte(params.label))
+		if err != nil {
+			b.Fatal(err)
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes
+}
+
+func benchmarkParseEncapsulationKey(algorithm string, parse func() error, b *testing.B) {
+	b.Run(algorithm, func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := parse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchmarkKeyGen(algorithm string, generateKey func() error, b *testing.B) {
+	b.Run(algorithm, func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := generateKey(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
 
 func benchmarkEncapsulate[K EncapulationKey](algorithm string, kem K, b *testing.B) {
@@ -81,7 +308,10 @@ func benchmarkEncapsulate[K EncapulationKey](algorithm string, kem K, b *testing
 		b.ReportAllocs()
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			_, _ = kem.Encapsulate()
+			_, _, err := kem.Encapsulate()
+			if err != nil {
+				b.Fatal(err)
+			}
 		}
 	})
 }
@@ -98,3 +328,23 @@ func benchmarkDecapsulate[K DecapsulationKey](algorithm string, kem K, ciphertex
 		}
 	})
 }
+
+// mlKem768EncapsulationKey adapts the crypto/mlkem API, which can't fail and returns the shared
+// key first, to EncapulationKey.
+type mlKem768EncapsulationKey struct {
+	*mlkem.EncapsulationKey768
+}
+
+func (ek mlKem768EncapsulationKey) Encapsulate() (ciphertext, sharedKey []byte, err error) {
+	sharedKey, ciphertext = ek.EncapsulationKey768.Encapsulate()
+	return ciphertext, sharedKey, nil
+}
+
+type mlKem1024EncapsulationKey struct {
+	*mlkem.EncapsulationKey1024
+}
+
+func (ek mlKem1024EncapsulationKey) Encapsulate() (ciphertext, sharedKey []byte, err error) {
+	sharedKey, ciphertext = ek.EncapsulationKey1024.Encapsulate()
+	return ciphertext, sharedKey, nil
+}
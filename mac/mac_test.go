@@ -1,14 +1,20 @@
 package mac
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha3"
 	"crypto/sha512"
+	"crypto/subtle"
 	"fmt"
 	"hash"
 	"testing"
 
+	"github.com/skerkour/go-benchmarks/crypto/aesmac"
+	ericlagergrensubtle "github.com/skerkour/go-benchmarks/crypto/ericlagergren/subtle"
 	"github.com/skerkour/go-benchmarks/crypto/kmac"
 	"github.com/skerkour/go-benchmarks/utils"
 	zeeboblake3 "github.com/zeebo/blake3"
@@ -18,8 +24,11 @@ import (
 	lukechampineblake3 "lukechampine.com/blake3"
 )
 
+// Mac appends the tag of input to output.
 type Mac interface {
 	Mac(key, input, output []byte)
+	// Verify reports whether tag is the tag of input, comparing them in constant time.
+	Verify(key, input, tag []byte) bool
 }
 
 func BenchmarkMac(b *testing.B) {
@@ -61,6 +70,10 @@ func BenchmarkMac(b *testing.B) {
 
 		// benchmarkMac("sha512/256", sha512_256Hasher{}, b)
 		benchmarkMac(size, "poly1305", poly1305Mac{}, output128, b)
+
+		benchmarkMac(size, "AES-256-CMAC", aesCmac{}, output128, b)
+		benchmarkMac(size, "AES-256-GMAC", aesGmac{}, output128, b)
+		benchmarkMac(size, "AES-256-GMAC_gcm", aesGcmGmac{}, output128, b)
 		// benchmarkMac(size, "blake2b_512", blake2b512Hasher{}, b)
 	}
 }
@@ -78,6 +91,112 @@ func benchmarkMac[H Mac](size int64, algorithm string, hasher H, output []byte,
 	})
 }
 
+// BenchmarkMacVerify times the verification of a valid tag: computing the tag of the input and
+// comparing it in constant time with the received one.
+func BenchmarkMacVerify(b *testing.B) {
+	benchmarks := []int64{
+		64,
+		1024,
+		16 * 1024,
+		64 * 1024,
+		1024 * 1024,
+		10 * 1024 * 1024,
+		100 * 1024 * 1024,
+	}
+
+	for _, size := range benchmarks {
+		benchmarkMacVerify(size, "HMAC-SHA2-256", sha256Mac{}, 32, b)
+		benchmarkMacVerify(size, "HMAC-SHA2-512", sha512Hasher{}, 64, b)
+
+		benchmarkMacVerify(size, "SHA3-256", sha3Mac{}, 32, b)
+		benchmarkMacVerify(size, "SHA3-512", sha3_512Mac{}, 64, b)
+		benchmarkMacVerify(size, "SHAKE256-512", shake256{}, 64, b)
+
+		benchmarkMacVerify(size, "KMAC-128", kmac128{}, 32, b)
+		benchmarkMacVerify(size, "KMAC-256", kmac256{}, 32, b)
+
+		benchmarkMacVerify(size, "HMAC-SHA3-256", sha3Hmac{}, 32, b)
+		benchmarkMacVerify(size, "HMAC-SHA3-512", sha3_512Hmac{}, 64, b)
+
+		benchmarkMacVerify(size, "BLAKE3-256_zeebo", zeeboBlake3Mac{}, 32, b)
+		benchmarkMacVerify(size, "BLAKE3-512_zeebo", zeeboBlake3_512Mac{}, 64, b)
+		benchmarkMacVerify(size, "BLAKE3-256_lukechampine", lukechampineBlake3Mac{}, 32, b)
+		benchmarkMacVerify(size, "BLAKE3-512_lukechampine", lukechampineBlake3_512Mac{}, 64, b)
+
+		benchmarkMacVerify(size, "BLAKE2b-256", blake2bMac{}, 32, b)
+		benchmarkMacVerify(size, "BLAKE2s-256", blake2sMac{}, 32, b)
+
+		benchmarkMacVerify(size, "poly1305", poly1305Mac{}, 16, b)
+
+		benchmarkMacVerify(size, "AES-256-CMAC", aesCmac{}, 16, b)
+		benchmarkMacVerify(size, "AES-256-GMAC", aesGmac{}, 16, b)
+		benchmarkMacVerify(size, "AES-256-GMAC_gcm", aesGcmGmac{}, 16, b)
+	}
+}
+
+func benchmarkMacVerify[H Mac](size int64, algorithm string, hasher H, tagSize int, b *testing.B) {
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+		key := utils.RandBytes(b, 32)
+		buf := utils.RandBytes(b, size)
+		tag := computeTag(hasher, key, buf, tagSize)
+		if !hasher.Verify(key, buf, tag) {
+			b.Fatal("valid tag rejected")
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			hasher.Verify(key, buf, tag)
+		}
+	})
+}
+
+// computeTag returns the first tagSize bytes written by mac.
+func computeTag(mac Mac, key, input []byte, tagSize int) []byte {
+	output := make([]byte, 0, 256)
+	mac.Mac(key, input, output)
+	return output[:tagSize]
+}
+
+// verifyTag computes the tag of input and compares it with tag in constant time, with
+// hmac.Equal, like most code verifying a MAC. Tags that are not tagSize bytes long are rejected,
+// otherwise a truncated tag would be easier to forge.
+func verifyTag(mac Mac, key, input, tag []byte, tagSize int) bool {
+	if len(tag) != tagSize {
+		return false
+	}
+	var computed [256]byte
+	mac.Mac(key, input, computed[:0])
+	return hmac.Equal(computed[:tagSize], tag)
+}
+
+// BenchmarkTagCompare compares the functions used to compare tags. bytes.Equal returns at the
+// first difference, it is the variable-time baseline.
+func BenchmarkTagCompare(b *testing.B) {
+	for _, size := range []int64{16, 32, 64} {
+		benchmarkTagCompare(size, "bytes.Equal", bytes.Equal, b)
+		benchmarkTagCompare(size, "hmac.Equal", hmac.Equal, b)
+		benchmarkTagCompare(size, "subtle.ConstantTimeCompare", func(x, y []byte) bool {
+			return subtle.ConstantTimeCompare(x, y) == 1
+		}, b)
+		benchmarkTagCompare(size, "ericlagergren_subtle.ConstantTimeCompare", func(x, y []byte) bool {
+			return ericlagergrensubtle.ConstantTimeCompare(x, y) == 1
+		}, b)
+	}
+}
+
+func benchmarkTagCompare(size int64, algorithm string, compare func(x, y []byte) bool, b *testing.B) {
+	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
+		b.ReportAllocs()
+		x := utils.RandBytes(b, size)
+		y := bytes.Clone(x)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			compare(x, y)
+		}
+	})
+}
+
 type lukechampineBlake3Mac struct{}
 
 func (lukechampineBlake3Mac) Mac(key, input, output []byte) {
@@ -86,6 +205,10 @@ func (lukechampineBlake3Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac lukechampineBlake3Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type lukechampineBlake3_512Mac struct{}
 
 func (lukechampineBlake3_512Mac) Mac(key, input, output []byte) {
@@ -94,6 +217,10 @@ func (lukechampineBlake3_512Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac lukechampineBlake3_512Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
+}
+
 type zeeboBlake3Mac struct{}
 
 func (zeeboBlake3Mac) Mac(key, input, output []byte) {
@@ -102,13 +229,21 @@ func (zeeboBlake3Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac zeeboBlake3Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type zeeboBlake3_512Mac struct{}
 
 func (zeeboBlake3_512Mac) Mac(key, input, output []byte) {
 	hasher, _ := zeeboblake3.NewKeyed(key)
 	hasher.Write(input)
 	digest := hasher.Digest()
-	digest.Read(output)
+	digest.Read(output[len(output) : len(output)+64])
+}
+
+func (mac zeeboBlake3_512Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
 }
 
 type blake2sMac struct{}
@@ -119,6 +254,10 @@ func (blake2sMac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac blake2sMac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type blake2bMac struct{}
 
 func (blake2bMac) Mac(key, input, output []byte) {
@@ -127,6 +266,10 @@ func (blake2bMac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac blake2bMac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type poly1305Mac struct{}
 
 func (poly1305Mac) Mac(key, input, output []byte) {
@@ -136,6 +279,55 @@ func (poly1305Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (poly1305Mac) Verify(key, input, tag []byte) bool {
+	polyKey := [32]byte(key[0:32])
+	return len(tag) == poly1305.TagSize && poly1305.Verify((*[poly1305.TagSize]byte)(tag), input, &polyKey)
+}
+
+type aesCmac struct{}
+
+func (aesCmac) Mac(key, input, output []byte) {
+	hasher, _ := aesmac.NewAESCMAC(key)
+	hasher.Write(input)
+	hasher.Sum(output)
+}
+
+func (mac aesCmac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 16)
+}
+
+// GMAC needs a unique nonce per message, the benchmarks use a fixed one
+var gmacNonce = make([]byte, aesmac.NonceSize)
+
+type aesGmac struct{}
+
+func (aesGmac) Mac(key, input, output []byte) {
+	hasher, _ := aesmac.NewAESGMAC(key, gmacNonce)
+	hasher.Write(input)
+	hasher.Sum(output)
+}
+
+func (mac aesGmac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 16)
+}
+
+// aesGcmGmac computes GMAC with the crypto/cipher GCM, which uses the hardware carry-less
+// multiplication but can't be streamed
+type aesGcmGmac struct{}
+
+func (aesGcmGmac) Mac(key, input, output []byte) {
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	gcm.Seal(output, gmacNonce, nil, input)
+}
+
+func (aesGcmGmac) Verify(key, input, tag []byte) bool {
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	_, err := gcm.Open(nil, gmacNonce, tag, input)
+	return err == nil
+}
+
 // type blake2b512Hasher struct{}
 
 // func (blake2b512Hasher) Hash(input []byte) {
@@ -150,6 +342,10 @@ func (sha256Mac) Mac(key, input, output []byte) {
 	hmac.Sum(output)
 }
 
+func (mac sha256Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type sha512Hasher struct{}
 
 func (sha512Hasher) Mac(key, input, output []byte) {
@@ -158,6 +354,10 @@ func (sha512Hasher) Mac(key, input, output []byte) {
 	hmac.Sum(output)
 }
 
+func (mac sha512Hasher) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
+}
+
 // type sha512_256Hasher struct{}
 // func (sha512_256Hasher) Hash(input []byte) {
 // 	sha512.Sum512_256(input)
@@ -172,6 +372,10 @@ func (sha3Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac sha3Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type sha3_512Mac struct{}
 
 func (sha3_512Mac) Mac(key, input, output []byte) {
@@ -181,6 +385,10 @@ func (sha3_512Mac) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac sha3_512Mac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
+}
+
 type sha3Hmac struct{}
 
 func (sha3Hmac) Mac(key, input, output []byte) {
@@ -189,6 +397,10 @@ func (sha3Hmac) Mac(key, input, output []byte) {
 	hmac.Sum(output)
 }
 
+func (mac sha3Hmac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type sha3_512Hmac struct{}
 
 func (sha3_512Hmac) Mac(key, input, output []byte) {
@@ -197,6 +409,10 @@ func (sha3_512Hmac) Mac(key, input, output []byte) {
 	hmac.Sum(output)
 }
 
+func (mac sha3_512Hmac) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
+}
+
 type kmac128 struct{}
 
 func (kmac128) Mac(key, input, output []byte) {
@@ -205,6 +421,10 @@ func (kmac128) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac kmac128) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type kmac256 struct{}
 
 func (kmac256) Mac(key, input, output []byte) {
@@ -213,11 +433,90 @@ func (kmac256) Mac(key, input, output []byte) {
 	hasher.Sum(output)
 }
 
+func (mac kmac256) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 32)
+}
+
 type shake256 struct{}
 
 func (shake256) Mac(key, input, output []byte) {
 	hasher := sha3.NewSHAKE256()
 	hasher.Write(input)
 	hasher.Write(key)
-	hasher.Read(output)
+	hasher.Read(output[len(output) : len(output)+64])
+}
+
+func (mac shake256) Verify(key, input, tag []byte) bool {
+	return verifyTag(mac, key, input, tag, 64)
+}
+
+var verifiedMacs = []struct {
+	name    string
+	mac     Mac
+	tagSize int
+}{
+	{"HMAC-SHA2-256", sha256Mac{}, 32},
+	{"HMAC-SHA2-512", sha512Hasher{}, 64},
+	{"SHA3-256", sha3Mac{}, 32},
+	{"SHA3-512", sha3_512Mac{}, 64},
+	{"SHAKE256-512", shake256{}, 64},
+	{"KMAC-128", kmac128{}, 32},
+	{"KMAC-256", kmac256{}, 32},
+	{"HMAC-SHA3-256", sha3Hmac{}, 32},
+	{"HMAC-SHA3-512", sha3_512Hmac{}, 64},
+	{"BLAKE3-256_zeebo", zeeboBlake3Mac{}, 32},
+	{"BLAKE3-512_zeebo", zeeboBlake3_512Mac{}, 64},
+	{"BLAKE3-256_lukechampine", lukechampineBlake3Mac{}, 32},
+	{"BLAKE3-512_lukechampine", lukechampineBlake3_512Mac{}, 64},
+	{"BLAKE2b-256", blake2bMac{}, 32},
+	{"BLAKE2s-256", blake2sMac{}, 32},
+	{"poly1305", poly1305Mac{}, 16},
+	{"AES-256-CMAC", aesCmac{}, 16},
+	{"AES-256-GMAC", aesGmac{}, 16},
+	{"AES-256-GMAC_gcm", aesGcmGmac{}, 16},
+}
+
+func TestMacVerify(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	input := []byte("message")
+
+	for _, m := range verifiedMacs {
+		tag := computeTag(m.mac, key, input, m.tagSize)
+		if !m.mac.Verify(key, input, tag) {
+			t.Errorf("%s: valid tag rejected", m.name)
+		}
+		for _, i := range []int{0, m.tagSize - 1} {
+			forged := bytes.Clone(tag)
+			forged[i] ^= 1
+			if m.mac.Verify(key, input, forged) {
+				t.Errorf("%s: tag modified at byte %d accepted", m.name, i)
+			}
+		}
+		if m.mac.Verify(key, input, tag[:m.tagSize-1]) {
+			t.Errorf("%s: truncated tag accepted", m.name)
+		}
+	}
+}
+
+// TestMacVerifyTiming checks with a dudect-style test that rejecting a tag that differs in its
+// first byte takes as long as accepting a valid tag.
+func TestMacVerifyTiming(t *testing.T) {
+	utils.SkipTimingTest(t)
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+	input := bytes.Repeat([]byte{0x17}, 64)
+
+	for _, m := range verifiedMacs {
+		tag := computeTag(m.mac, key, input, m.tagSize)
+		forged := bytes.Clone(tag)
+		forged[0] ^= 1
+
+		leak := utils.TimingLeak(5_000, 4,
+			func() { m.mac.Verify(key, input, tag) },
+			func() { m.mac.Verify(key, input, forged) },
+		)
+		if leak > utils.TimingLeakThreshold {
+			t.Errorf("%s: verification time depends on the tag (t = %.1f)", m.name, leak)
+		}
+	}
 }
@@ -0,0 +1,272 @@
+// Package password_hashing benchmarks the password hashing functions of golang.org/x/crypto and
+// provides helpers to pick their parameters for a target latency, e.g. to size login servers.
+package password_hashing
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeySize is the size in bytes of the keys derived by the hashers, bcrypt excepted.
+const KeySize = 32
+
+// Hasher is a password hashing function with fixed parameters.
+type Hasher interface {
+	Hash(password, salt []byte) ([]byte, error)
+	// Threads is the number of goroutines used to hash one password.
+	Threads() int
+	// String returns the algorithm and its parameters.
+	String() string
+}
+
+// Argon2id is Argon2id with Memory in KiB.
+type Argon2id struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (h Argon2id) Hash(password, salt []byte) ([]byte, error) {
+	return argon2.IDKey(password, salt, h.Iterations, h.Memory, h.Parallelism, KeySize), nil
+}
+
+func (h Argon2id) Threads() int {
+	return int(h.Parallelism)
+}
+
+func (h Argon2id) String() string {
+	return fmt.Sprintf("Argon2id-m=%dMiB,t=%d,p=%d", h.Memory/1024, h.Iterations, h.Parallelism)
+}
+
+// Argon2i is Argon2i with Memory in KiB.
+type Argon2i struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (h Argon2i) Hash(password, salt []byte) ([]byte, error) {
+	return argon2.Key(password, salt, h.Iterations, h.Memory, h.Parallelism, KeySize), nil
+}
+
+func (h Argon2i) Threads() int {
+	return int(h.Parallelism)
+}
+
+func (h Argon2i) String() string {
+	return fmt.Sprintf("Argon2i-m=%dMiB,t=%d,p=%d", h.Memory/1024, h.Iterations, h.Parallelism)
+}
+
+// Scrypt is scrypt with N = 2^LogN. golang.org/x/crypto/scrypt computes the P lanes
+// sequentially.
+type Scrypt struct {
+	LogN int
+	R    int
+	P    int
+}
+
+func (h Scrypt) Hash(password, salt []byte) ([]byte, error) {
+	return scrypt.Key(password, salt, 1<<h.LogN, h.R, h.P, KeySize)
+}
+
+func (h Scrypt) Threads() int {
+	return 1
+}
+
+func (h Scrypt) String() string {
+	return fmt.Sprintf("scrypt-N=2^%d,r=%d,p=%d", h.LogN, h.R, h.P)
+}
+
+// Bcrypt is bcrypt with 2^Cost rounds. It generates its own salt, the salt argument of Hash is
+// ignored.
+type Bcrypt struct {
+	Cost int
+}
+
+func (h Bcrypt) Hash(password, _ []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, h.Cost)
+}
+
+func (h Bcrypt) Threads() int {
+	return 1
+}
+
+func (h Bcrypt) String() string {
+	return fmt.Sprintf("bcrypt-cost=%d", h.Cost)
+}
+
+// PBKDF2SHA256 is PBKDF2-HMAC-SHA256.
+type PBKDF2SHA256 struct {
+	Iterations int
+}
+
+func (h PBKDF2SHA256) Hash(password, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(password, salt, h.Iterations, KeySize, sha256.New), nil
+}
+
+func (h PBKDF2SHA256) Threads() int {
+	return 1
+}
+
+func (h PBKDF2SHA256) String() string {
+	return fmt.Sprintf("PBKDF2-HMAC-SHA256-i=%d", h.Iterations)
+}
+
+// TuneCost returns the largest cost in [minCost, maxCost] for which hashing a password with
+// newHasher(cost) takes at most target, and the latency measured for that cost. The hashing
+// time must increase with the cost.
+//
+// The cost is doubled from minCost until the target is exceeded, then binary searched until
+// it is known within 2%. When logarithmic is true, the cost is the base-2 logarithm of the
+// work, like the bcrypt cost, and it is incremented instead of doubled. If even minCost is
+// slower than target, minCost is returned.
+func TuneCost(newHasher func(cost int) Hasher, minCost, maxCost int, target time.Duration, logarithmic bool) (int, time.Duration, error) {
+	if minCost <= 0 || minCost > maxCost {
+		return 0, 0, errors.New("password_hashing: invalid cost range")
+	}
+
+	low := minCost
+	lowLatency, err := measureLatency(newHasher(low))
+	if err != nil {
+		return 0, 0, err
+	}
+	if lowLatency > target {
+		return low, lowLatency, nil
+	}
+
+	// find a cost that exceeds the target
+	high := 0
+	for cost := low; ; {
+		if logarithmic {
+			cost = min(cost+1, maxCost)
+		} else {
+			cost = min(cost*2, maxCost)
+		}
+		latency, err := measureLatency(newHasher(cost))
+		if err != nil {
+			return 0, 0, err
+		}
+		if latency > target {
+			high = cost
+			break
+		}
+		low, lowLatency = cost, latency
+		if cost == maxCost {
+			return low, lowLatency, nil
+		}
+	}
+
+	for high-low > max(1, low/50) {
+		cost := low + (high-low)/2
+		latency, err := measureLatency(newHasher(cost))
+		if err != nil {
+			return 0, 0, err
+		}
+		if latency > target {
+			high = cost
+		} else {
+			low, lowLatency = cost, latency
+		}
+	}
+
+	return low, lowLatency, nil
+}
+
+// measureLatency returns the fastest of a few hashes, to ignore the ones slowed down by the
+// scheduler or the garbage collector.
+func measureLatency(hasher Hasher) (time.Duration, error) {
+	password := []byte("correct horse battery staple")
+	salt := []byte("0123456789abcdef")
+
+	latency := time.Duration(0)
+	for i := range 3 {
+		start := time.Now()
+		if _, err := hasher.Hash(password, salt); err != nil {
+			return 0, err
+		}
+		elapsed := time.Since(start)
+		if i == 0 || elapsed < latency {
+			latency = elapsed
+		}
+	}
+	return latency, nil
+}
+
+// TuneArgon2id returns the Argon2id parameters with the given memory (in KiB) and parallelism
+// and the largest number of iterations that hash a password in at most target.
+func TuneArgon2id(memory uint32, parallelism uint8, target time.Duration) (Argon2id, time.Duration, error) {
+	iterations, latency, err := TuneCost(func(cost int) Hasher {
+		return Argon2id{Memory: memory, Iterations: uint32(cost), Parallelism: parallelism}
+	}, 1, 64, target, false)
+	return Argon2id{Memory: memory, Iterations: uint32(iterations), Parallelism: parallelism}, latency, err
+}
+
+// TuneScrypt returns the scrypt parameters with the given r and p and the largest N that hash
+// a password in at most target.
+func TuneScrypt(r, p int, target time.Duration) (Scrypt, time.Duration, error) {
+	logN, latency, err := TuneCost(func(cost int) Hasher {
+		return Scrypt{LogN: cost, R: r, P: p}
+	}, 10, 24, target, true)
+	return Scrypt{LogN: logN, R: r, P: p}, latency, err
+}
+
+// TuneBcrypt returns the largest bcrypt cost that hashes a password in at most target.
+func TuneBcrypt(target time.Duration) (Bcrypt, time.Duration, error) {
+	cost, latency, err := TuneCost(func(cost int) Hasher {
+		return Bcrypt{Cost: cost}
+	}, bcrypt.MinCost, bcrypt.MaxCost, target, true)
+	return Bcrypt{Cost: cost}, latency, err
+}
+
+// TunePBKDF2SHA256 returns the largest number of PBKDF2-HMAC-SHA256 iterations that hash a
+// password in at most target.
+func TunePBKDF2SHA256(target time.Duration) (PBKDF2SHA256, time.Duration, error) {
+	iterations, latency, err := TuneCost(func(cost int) Hasher {
+		return PBKDF2SHA256{Iterations: cost}
+	}, 1000, 100_000_000, target, false)
+	return PBKDF2SHA256{Iterations: iterations}, latency, err
+}
+
+// ResetPeakRSS resets the peak resident set size of the process, so PeakRSS returns the peak
+// since the call. It is only supported on Linux.
+func ResetPeakRSS() error {
+	return os.WriteFile("/proc/self/clear_refs", []byte("5"), 0)
+}
+
+// PeakRSS returns the peak resident set size of the process in bytes (VmHWM). It is only
+// supported on Linux.
+func PeakRSS() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), "VmHWM:")
+		if !found {
+			continue
+		}
+		kib, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kib * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("password_hashing: VmHWM not found in /proc/self/status")
+}
@@ -0,0 +1,187 @@
+package password_hashing
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/skerkour/go-benchmarks/utils"
+)
+
+const targetLatency = 250 * time.Millisecond
+
+func BenchmarkPasswordHashing(b *testing.B) {
+	for _, memory := range []uint32{16 * 1024, 64 * 1024, 256 * 1024} {
+		for _, iterations := range []uint32{1, 3} {
+			for _, parallelism := range []uint8{1, 4} {
+				benchmarkPasswordHasher(Argon2id{Memory: memory, Iterations: iterations, Parallelism: parallelism}, b)
+				benchmarkPasswordHasher(Argon2i{Memory: memory, Iterations: iterations, Parallelism: parallelism}, b)
+			}
+		}
+	}
+
+	for _, logN := range []int{14, 15, 16, 17, 20} {
+		for _, p := range []int{1, 2} {
+			benchmarkPasswordHasher(Scrypt{LogN: logN, R: 8, P: p}, b)
+		}
+	}
+
+	for _, cost := range []int{10, 11, 12, 13, 14} {
+		benchmarkPasswordHasher(Bcrypt{Cost: cost}, b)
+	}
+
+	for _, iterations := range []int{100_000, 310_000, 600_000, 1_000_000} {
+		benchmarkPasswordHasher(PBKDF2SHA256{Iterations: iterations}, b)
+	}
+}
+
+// BenchmarkTunedPasswordHashing picks, for each algorithm, the parameters that hash a password
+// in about targetLatency on this machine and benchmarks them.
+func BenchmarkTunedPasswordHashing(b *testing.B) {
+	// OWASP recommends at least 19 MiB for Argon2id
+	argon2id, _, err := TuneArgon2id(19*1024, 1, targetLatency)
+	if err != nil {
+		b.Fatal(err)
+	}
+	argon2id64, _, err := TuneArgon2id(64*1024, 4, targetLatency)
+	if err != nil {
+		b.Fatal(err)
+	}
+	scrypt, _, err := TuneScrypt(8, 1, targetLatency)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bcrypt, _, err := TuneBcrypt(targetLatency)
+	if err != nil {
+		b.Fatal(err)
+	}
+	pbkdf2, _, err := TunePBKDF2SHA256(targetLatency)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkPasswordHasher(argon2id, b)
+	benchmarkPasswordHasher(argon2id64, b)
+	benchmarkPasswordHasher(scrypt, b)
+	benchmarkPasswordHasher(bcrypt, b)
+	benchmarkPasswordHasher(pbkdf2, b)
+}
+
+// benchmarkPasswordHasher reports the wall time per hash (ns/op), the peak RSS of the process
+// during the benchmark and the number of hashes per second per core used by the hasher, which is
+// what a login server can sustain per core.
+func benchmarkPasswordHasher[H Hasher](hasher H, b *testing.B) {
+	b.Run(hasher.String(), func(b *testing.B) {
+		password := utils.RandBytes(b, 16)
+		salt := utils.RandBytes(b, 16)
+
+		// return the memory of the previous benchmarks to the OS so it doesn't count in the peak
+		debug.FreeOSMemory()
+		resetErr := ResetPeakRSS()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := hasher.Hash(password, salt); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StopTimer()
+
+		hashesPerSecond := float64(b.N) / b.Elapsed().Seconds()
+		cores := min(hasher.Threads(), runtime.GOMAXPROCS(0))
+		b.ReportMetric(hashesPerSecond/float64(cores), "hashes/s/core")
+		if peakRSS, err := PeakRSS(); resetErr == nil && err == nil {
+			b.ReportMetric(float64(peakRSS)/(1024*1024), "peak-RSS-MiB")
+		}
+	})
+}
+
+// sleepHasher takes cost milliseconds to hash a password.
+type sleepHasher struct {
+	cost int
+}
+
+func (h sleepHasher) Hash(password, salt []byte) ([]byte, error) {
+	time.Sleep(time.Duration(h.cost) * time.Millisecond)
+	return nil, nil
+}
+
+func (h sleepHasher) Threads() int {
+	return 1
+}
+
+func (h sleepHasher) String() string {
+	return fmt.Sprintf("sleep-%d", h.cost)
+}
+
+func TestTuneCost(t *testing.T) {
+	newHasher := func(cost int) Hasher { return sleepHasher{cost: cost} }
+
+	cost, latency, err := TuneCost(newHasher, 1, 1000, 20*time.Millisecond, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sleeping can take longer than asked, never shorter
+	if cost > 20 || cost < 10 {
+		t.Errorf("expected a cost of at most 20 and close to it, got %d (%s)", cost, latency)
+	}
+
+	cost, _, err = TuneCost(newHasher, 1, 5, time.Second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 5 {
+		t.Errorf("expected the maximum cost 5 when the target can't be reached, got %d", cost)
+	}
+
+	cost, _, err = TuneCost(newHasher, 30, 100, 10*time.Millisecond, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 30 {
+		t.Errorf("expected the minimum cost 30 when it is already too slow, got %d", cost)
+	}
+}
+
+func TestTuneBcrypt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("tuning bcrypt is slow")
+	}
+	hasher, latency, err := TuneBcrypt(50 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latency > 50*time.Millisecond && hasher.Cost > 4 {
+		t.Errorf("%s: latency %s above the target", hasher, latency)
+	}
+}
+
+func TestPeakRSS(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("peak RSS is only supported on Linux")
+	}
+	if err := ResetPeakRSS(); err != nil {
+		t.Skip("can't reset the peak RSS:", err)
+	}
+	before, err := PeakRSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 64*1024*1024)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	after, err := PeakRSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.KeepAlive(buf)
+
+	if after < before+32*1024*1024 {
+		t.Errorf("expected the peak RSS to grow by 64 MiB, got %d -> %d", before, after)
+	}
+}
@@ -0,0 +1,49 @@
+package signatures
+
+import (
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/compositesig"
+)
+
+var compositeSchemes = []*compositesig.Scheme{
+	compositesig.MLDSA44Ed25519,
+	compositesig.MLDSA44P256,
+	compositesig.MLDSA65Ed25519,
+	compositesig.MLDSA65P256,
+}
+
+// compositeSigner signs with both ML-DSA and Ed25519 or ECDSA P-256, with an empty context. Its
+// overhead over the components alone is the pre-hash of the message and the hashing of the
+// message representative by each component.
+type compositeSigner struct {
+	privateKey *compositesig.PrivateKey
+	publicKey  *compositesig.PublicKey
+}
+
+func newCompositeSigner(tb testing.TB, scheme *compositesig.Scheme) compositeSigner {
+	privateKey, err := scheme.GenerateKey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return compositeSigner{
+		privateKey: privateKey,
+		publicKey:  privateKey.PublicKey(),
+	}
+}
+
+func (signer compositeSigner) Sign(message []byte) []byte {
+	signature, err := signer.privateKey.Sign(message, nil)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer compositeSigner) Verify(message, signature []byte) bool {
+	return compositesig.Verify(signer.publicKey, message, signature, nil)
+}
+
+func (signer compositeSigner) PublicKeySize() int {
+	return signer.publicKey.Scheme().PublicKeySize()
+}
@@ -0,0 +1,229 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+var ecdsaCurves = []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()}
+
+// ecdsaModes are the nonce generations and signature encodings of the ECDSA signers: randomized
+// or RFC 6979 deterministic nonces, and ASN.1 DER or IEEE P1363 raw r || s signatures, as in
+// JOSE ES256.
+var ecdsaModes = []struct {
+	suffix        string
+	deterministic bool
+	raw           bool
+}{
+	{"", false, false},
+	{"-deterministic", true, false},
+	{"-raw", false, true},
+	{"-deterministic-raw", true, true},
+}
+
+// ecdsaSigner signs the SHA-256 hash of the message with ECDSA.
+type ecdsaSigner struct {
+	privateKey    *ecdsa.PrivateKey
+	publicKey     ecdsa.PublicKey
+	deterministic bool
+	raw           bool
+}
+
+func newECDSASigner(tb testing.TB, curve elliptic.Curve, deterministic, raw bool) ecdsaSigner {
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return ecdsaSigner{
+		privateKey:    privateKey,
+		publicKey:     privateKey.PublicKey,
+		deterministic: deterministic,
+		raw:           raw,
+	}
+}
+
+func (signer ecdsaSigner) Sign(message []byte) []byte {
+	hash := sha256.Sum256(message)
+	var signature []byte
+	var err error
+	if signer.deterministic {
+		// a nil random source selects the RFC 6979 nonces
+		signature, err = signer.privateKey.Sign(nil, hash[:], crypto.SHA256)
+	} else {
+		signature, err = ecdsa.SignASN1(rand.Reader, signer.privateKey, hash[:])
+	}
+	if err != nil {
+		panic(err)
+	}
+	if signer.raw {
+		if signature, err = ecdsaASN1ToRaw(signature, ecdsaScalarSize(signer.publicKey.Curve)); err != nil {
+			panic(err)
+		}
+	}
+	return signature
+}
+
+func (signer ecdsaSigner) Verify(message, signature []byte) bool {
+	hash := sha256.Sum256(message)
+	if signer.raw {
+		var err error
+		if signature, err = ecdsaRawToASN1(signature, ecdsaScalarSize(signer.publicKey.Curve)); err != nil {
+			return false
+		}
+	}
+	return ecdsa.VerifyASN1(&signer.publicKey, hash[:], signature)
+}
+
+// ecdsaScalarSize returns the size of the encoded scalars of curve, half the size of its raw
+// signatures.
+func ecdsaScalarSize(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 7) / 8
+}
+
+// ecdsaASN1ToRaw converts an ASN.1 DER ECDSA signature, SEQUENCE { r INTEGER, s INTEGER }, to
+// the fixed-size r || s encoding of IEEE P1363, where r and s are size-byte big-endian integers.
+func ecdsaASN1ToRaw(signature []byte, size int) ([]byte, error) {
+	var r, s []byte
+	input := cryptobyte.String(signature)
+	var inner cryptobyte.String
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) || !input.Empty() ||
+		!inner.ReadASN1Integer(&r) || !inner.ReadASN1Integer(&s) || !inner.Empty() {
+		return nil, errors.New("invalid ASN.1 ECDSA signature")
+	}
+	if len(r) > size || len(s) > size {
+		return nil, errors.New("ECDSA signature integer too large")
+	}
+	raw := make([]byte, 2*size)
+	copy(raw[size-len(r):size], r)
+	copy(raw[2*size-len(s):], s)
+	return raw, nil
+}
+
+// ecdsaRawToASN1 converts an r || s signature of size-byte integers to ASN.1 DER.
+func ecdsaRawToASN1(raw []byte, size int) ([]byte, error) {
+	if len(raw) != 2*size {
+		return nil, fmt.Errorf("expected a %d-byte raw ECDSA signature, got %d bytes", 2*size, len(raw))
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		addASN1IntBytes(b, raw[:size])
+		addASN1IntBytes(b, raw[size:])
+	})
+	return b.Bytes()
+}
+
+// addASN1IntBytes encodes the big-endian unsigned integer x as a minimal ASN.1 INTEGER.
+func addASN1IntBytes(b *cryptobyte.Builder, x []byte) {
+	for len(x) > 1 && x[0] == 0 {
+		x = x[1:]
+	}
+	b.AddASN1(asn1.INTEGER, func(c *cryptobyte.Builder) {
+		// a leading zero keeps the integer positive
+		if x[0]&0x80 != 0 {
+			c.AddUint8(0)
+		}
+		c.AddBytes(x)
+	})
+}
+
+// BenchmarkECDSAEncoding benchmarks the conversions between the ASN.1 DER and raw r || s
+// signatures, which the raw ECDSA signers add to the standard library's ASN.1 API.
+func BenchmarkECDSAEncoding(b *testing.B) {
+	for _, curve := range ecdsaCurves {
+		signer := newECDSASigner(b, curve, false, false)
+		der := signer.Sign([]byte("message"))
+		size := ecdsaScalarSize(curve)
+		raw, err := ecdsaASN1ToRaw(der, size)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run("ASN1ToRaw-"+curve.Params().Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ecdsaASN1ToRaw(der, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("RawToASN1-"+curve.Params().Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ecdsaRawToASN1(raw, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestECDSARFC6979 checks the deterministic raw signer against the P-256 SHA-256 vector of
+// RFC 6979, Appendix A.2.5, for the message "sample".
+func TestECDSARFC6979(t *testing.T) {
+	d, _ := hex.DecodeString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	privateKey, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := ecdsaSigner{privateKey: privateKey, publicKey: privateKey.PublicKey, deterministic: true, raw: true}
+	expected := "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716" +
+		"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8"
+	if got := hex.EncodeToString(signer.Sign([]byte("sample"))); !strings.EqualFold(got, expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestECDSAEncoding(t *testing.T) {
+	for _, curve := range ecdsaCurves {
+		size := ecdsaScalarSize(curve)
+		signer := newECDSASigner(t, curve, false, false)
+		// enough signatures for some r and s to be short or have their top bit set
+		for range 64 {
+			der := signer.Sign([]byte("message"))
+			raw, err := ecdsaASN1ToRaw(der, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) != 2*size {
+				t.Fatalf("%s: expected a %d-byte raw signature, got %d bytes", curve.Params().Name, 2*size, len(raw))
+			}
+			roundTrip, err := ecdsaRawToASN1(raw, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(roundTrip, der) {
+				t.Fatalf("%s: %X round-tripped to %X", curve.Params().Name, der, roundTrip)
+			}
+		}
+	}
+
+	for _, invalid := range [][]byte{
+		nil,
+		// trailing data
+		{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01, 0x00},
+		// negative r
+		{0x30, 0x06, 0x02, 0x01, 0x81, 0x02, 0x01, 0x01},
+		// non-minimal r
+		{0x30, 0x07, 0x02, 0x02, 0x00, 0x01, 0x02, 0x01, 0x01},
+		// r too large for 1-byte integers
+		{0x30, 0x07, 0x02, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01},
+	} {
+		if _, err := ecdsaASN1ToRaw(invalid, 1); err == nil {
+			t.Errorf("invalid signature %X accepted", invalid)
+		}
+	}
+	if _, err := ecdsaRawToASN1(make([]byte, 63), 32); err == nil {
+		t.Error("raw signature of the wrong size accepted")
+	}
+}
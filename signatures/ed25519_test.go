@@ -0,0 +1,205 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/ed25519batch"
+	"github.com/skerkour/go-benchmarks/utils"
+)
+
+// ed25519ctxSigner is Ed25519ctx: Ed25519 with a context string for domain separation.
+type ed25519ctxSigner struct {
+	privakeKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	options    *ed25519.Options
+}
+
+func newEd25519ctxSigner(tb testing.TB, context string) ed25519ctxSigner {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return ed25519ctxSigner{
+		privakeKey: private,
+		publicKey:  public,
+		options:    &ed25519.Options{Context: context},
+	}
+}
+
+func (signer ed25519ctxSigner) Sign(message []byte) []byte {
+	signature, err := signer.privakeKey.Sign(nil, message, signer.options)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer ed25519ctxSigner) Verify(message, signature []byte) bool {
+	return ed25519.VerifyWithOptions(signer.publicKey, message, signature, signer.options) == nil
+}
+
+// ed25519phSigner is Ed25519ph: the message is hashed with SHA-512 before being signed, so it
+// is read once instead of twice by Ed25519.
+type ed25519phSigner struct {
+	privakeKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+var ed25519phOptions = &ed25519.Options{Hash: crypto.SHA512}
+
+func newEd25519phSigner(tb testing.TB) ed25519phSigner {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return ed25519phSigner{
+		privakeKey: private,
+		publicKey:  public,
+	}
+}
+
+func (signer ed25519phSigner) Sign(message []byte) []byte {
+	hash := sha512.Sum512(message)
+	signature, err := signer.privakeKey.Sign(nil, hash[:], ed25519phOptions)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer ed25519phSigner) Verify(message, signature []byte) bool {
+	hash := sha512.Sum512(message)
+	return ed25519.VerifyWithOptions(signer.publicKey, hash[:], signature, ed25519phOptions) == nil
+}
+
+// Test vectors of sections 7.2 (Ed25519ctx) and 7.3 (Ed25519ph) of RFC 8032.
+var ed25519VariantVectors = []struct {
+	name      string
+	seed      string
+	publicKey string
+	message   string
+	context   string
+	prehash   bool
+	signature string
+}{
+	{
+		name:      "Ed25519ctx foo",
+		seed:      "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+		publicKey: "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+		message:   "f726936d19c800494e3fdaff20b276a8",
+		context:   "foo",
+		signature: "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d",
+	},
+	{
+		name:      "Ed25519ctx bar",
+		seed:      "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+		publicKey: "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+		message:   "f726936d19c800494e3fdaff20b276a8",
+		context:   "bar",
+		signature: "fc60d5872fc46b3aa69f8b5b4351d5808f92bcc044606db097abab6dbcb1aee3216c48e8b3b66431b5b186d1d28f8ee15a5ca2df6668346291c2043d4eb3e90d",
+	},
+	{
+		name:      "Ed25519ctx foo other message",
+		seed:      "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+		publicKey: "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+		message:   "508e9e6882b979fea900f62adceaca35",
+		context:   "foo",
+		signature: "8b70c1cc8310e1de20ac53ce28ae6e7207f33c3295e03bb5c0732a1d20dc64908922a8b052cf99b7c4fe107a5abb5b2c4085ae75890d02df26269d8945f84b0b",
+	},
+	{
+		name:      "Ed25519ctx foo other key",
+		seed:      "ab9c2853ce297ddab85c993b3ae14bcad39b2c682beabc27d6d4eb20711d6560",
+		publicKey: "0f1d1274943b91415889152e893d80e93275a1fc0b65fd71b4b0dda10ad7d772",
+		message:   "f726936d19c800494e3fdaff20b276a8",
+		context:   "foo",
+		signature: "21655b5f1aa965996b3f97b3c849eafba922a0a62992f73b3d1b73106a84ad85e9b86a7b6005ea868337ff2d20a7f5fbd4cd10b0be49a68da2b2e0dc0ad8960f",
+	},
+	{
+		name:      "Ed25519ph abc",
+		seed:      "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42",
+		publicKey: "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf",
+		message:   "616263",
+		prehash:   true,
+		signature: "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406",
+	},
+}
+
+func TestEd25519Variants(t *testing.T) {
+	for _, vector := range ed25519VariantVectors {
+		t.Run(vector.name, func(t *testing.T) {
+			seed, _ := hex.DecodeString(vector.seed)
+			message, _ := hex.DecodeString(vector.message)
+			expected, _ := hex.DecodeString(vector.signature)
+
+			privateKey := ed25519.NewKeyFromSeed(seed)
+			publicKey := privateKey.Public().(ed25519.PublicKey)
+			if hex.EncodeToString(publicKey) != vector.publicKey {
+				t.Fatalf("expected public key %s, got %x", vector.publicKey, publicKey)
+			}
+
+			var signer Signer
+			if vector.prehash {
+				signer = ed25519phSigner{privakeKey: privateKey, publicKey: publicKey}
+			} else {
+				signer = ed25519ctxSigner{privakeKey: privateKey, publicKey: publicKey, options: &ed25519.Options{Context: vector.context}}
+			}
+
+			signature := signer.Sign(message)
+			if !bytes.Equal(signature, expected) {
+				t.Errorf("expected signature %x, got %x", expected, signature)
+			}
+			if !signer.Verify(message, expected) {
+				t.Error("valid signature rejected")
+			}
+			if ed25519.Verify(publicKey, message, expected) {
+				t.Error("signature accepted by pure Ed25519")
+			}
+		})
+	}
+}
+
+// BenchmarkEd25519BatchVerify compares the verification of n Ed25519 signatures of 64-byte
+// messages with a single batch verification and with n calls to ed25519.Verify.
+func BenchmarkEd25519BatchVerify(b *testing.B) {
+	for n := 1; n <= 1024; n *= 2 {
+		publicKeys := make([]ed25519.PublicKey, n)
+		messages := make([][]byte, n)
+		signatures := make([][]byte, n)
+		for i := range n {
+			signer := newEd25519Signer(b)
+			publicKeys[i] = signer.publicKey
+			messages[i] = utils.RandBytes(b, 64)
+			signatures[i] = signer.Sign(messages[i])
+		}
+
+		b.Run(fmt.Sprintf("%d-batch", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !ed25519batch.Verify(publicKeys, messages, signatures) {
+					b.Fatal("batch verification failed")
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/signature")
+		})
+
+		b.Run(fmt.Sprintf("%d-individual", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := range n {
+					if !ed25519.Verify(publicKeys[j], messages[j], signatures[j]) {
+						b.Fatal("verification failed")
+					}
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/signature")
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package signatures
+
+import (
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/mldsa"
+)
+
+var mldsaParameters = []*mldsa.Parameters{mldsa.MLDSA44, mldsa.MLDSA65, mldsa.MLDSA87}
+
+// mldsaSigner is ML-DSA of FIPS 204 with an empty context, with hedged signatures by default or
+// deterministic ones, which skip reading 32 random bytes.
+type mldsaSigner struct {
+	privateKey    *mldsa.PrivateKey
+	publicKey     *mldsa.PublicKey
+	deterministic bool
+}
+
+func newMLDSASigner(tb testing.TB, params *mldsa.Parameters, deterministic bool) mldsaSigner {
+	privateKey, err := mldsa.GenerateKey(params)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return mldsaSigner{
+		privateKey:    privateKey,
+		publicKey:     privateKey.PublicKey(),
+		deterministic: deterministic,
+	}
+}
+
+func (signer mldsaSigner) Sign(message []byte) []byte {
+	sign := signer.privateKey.Sign
+	if signer.deterministic {
+		sign = signer.privateKey.SignDeterministic
+	}
+	signature, err := sign(message, nil)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer mldsaSigner) Verify(message, signature []byte) bool {
+	return mldsa.Verify(signer.publicKey, message, signature, nil)
+}
+
+func (signer mldsaSigner) PublicKeySize() int {
+	return signer.publicKey.Parameters().PublicKeySize()
+}
@@ -0,0 +1,303 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/utils"
+)
+
+// The RSA keys are fixed PKCS#8 fixtures in testdata, generated with
+//
+//	openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:<bits> [-pkeyopt rsa_keygen_pubexp:<e>] [-pkeyopt rsa_keygen_primes:3]
+//
+// so that the numbers are comparable across runs: the cost of the private operations slightly
+// depends on the primes.
+
+var rsaKeySizes = []int{2048, 3072, 4096}
+
+// rsaExponents are the public exponents of the 2048-bit fixtures. crypto/rsa rejects exponents
+// larger than 2^31-1.
+var rsaExponents = []int{3, 65537, 2147483647}
+
+func rsaFixture(bits int) string {
+	return fmt.Sprintf("rsa-%d.pem", bits)
+}
+
+func rsaExponentFixture(e int) string {
+	if e == 65537 {
+		return rsaFixture(2048)
+	}
+	return fmt.Sprintf("rsa-2048-e%d.pem", e)
+}
+
+const rsaMultiPrimeFixture = "rsa-2048-3primes.pem"
+
+func loadRSAKey(tb testing.TB, fixture string) *rsa.PrivateKey {
+	tb.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		tb.Fatalf("%s: no PEM block", fixture)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		tb.Fatalf("%s: %v", fixture, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		tb.Fatalf("%s: not an RSA key", fixture)
+	}
+	return rsaKey
+}
+
+// rsaPrecomputation selects how much of the CRT parameters of a private key are available.
+type rsaPrecomputation int
+
+const (
+	// rsaCRT is a key as returned by the parsing functions: primes and precomputed CRT values.
+	rsaCRT rsaPrecomputation = iota
+	// rsaCRTNotPrecomputed only has the primes, the CRT values are computed at each operation.
+	rsaCRTNotPrecomputed
+	// rsaNoCRT only has the modulus and the private exponent, the private operations are done
+	// modulo N.
+	rsaNoCRT
+)
+
+func (precomputation rsaPrecomputation) String() string {
+	switch precomputation {
+	case rsaCRT:
+		return "CRT"
+	case rsaCRTNotPrecomputed:
+		return "CRT-not-precomputed"
+	default:
+		return "no-CRT"
+	}
+}
+
+// withPrecomputation returns a copy of key with only the parameters selected by
+// precomputation.
+func withPrecomputation(key *rsa.PrivateKey, precomputation rsaPrecomputation) *rsa.PrivateKey {
+	switch precomputation {
+	case rsaCRT:
+		return key
+	case rsaCRTNotPrecomputed:
+		return &rsa.PrivateKey{PublicKey: key.PublicKey, D: key.D, Primes: key.Primes}
+	default:
+		return &rsa.PrivateKey{PublicKey: key.PublicKey, D: key.D}
+	}
+}
+
+type rsaSigner struct {
+	privakeKey *rsa.PrivateKey
+	publicKey  rsa.PublicKey
+	pss        bool
+}
+
+// newRSASigner returns a signer using the key of the given fixture, with PKCS #1 v1.5 or PSS
+// padding and SHA-256.
+func newRSASigner(tb testing.TB, fixture string, pss bool) rsaSigner {
+	privateKey := loadRSAKey(tb, fixture)
+	return rsaSigner{
+		privakeKey: privateKey,
+		publicKey:  privateKey.PublicKey,
+		pss:        pss,
+	}
+}
+
+func (signer rsaSigner) Sign(message []byte) []byte {
+	hash := sha256.Sum256(message)
+	var signature []byte
+	var err error
+	if signer.pss {
+		signature, err = rsa.SignPSS(rand.Reader, signer.privakeKey, crypto.SHA256, hash[:], nil)
+	} else {
+		signature, err = rsa.SignPKCS1v15(nil, signer.privakeKey, crypto.SHA256, hash[:])
+	}
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer rsaSigner) Verify(message, signature []byte) bool {
+	hash := sha256.Sum256(message)
+	if signer.pss {
+		return rsa.VerifyPSS(&signer.publicKey, crypto.SHA256, hash[:], signature, nil) == nil
+	}
+	return rsa.VerifyPKCS1v15(&signer.publicKey, crypto.SHA256, hash[:], signature) == nil
+}
+
+type Encrypter interface {
+	Encrypt(plaintext []byte) []byte
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+type rsaEncrypter struct {
+	privakeKey *rsa.PrivateKey
+	publicKey  rsa.PublicKey
+	oaep       bool
+}
+
+// newRSAEncrypter returns an encrypter using the key of the given fixture, with OAEP-SHA256 or
+// PKCS #1 v1.5 padding.
+func newRSAEncrypter(tb testing.TB, fixture string, oaep bool) rsaEncrypter {
+	privateKey := loadRSAKey(tb, fixture)
+	return rsaEncrypter{
+		privakeKey: privateKey,
+		publicKey:  privateKey.PublicKey,
+		oaep:       oaep,
+	}
+}
+
+func (encrypter rsaEncrypter) Encrypt(plaintext []byte) []byte {
+	var ciphertext []byte
+	var err error
+	if encrypter.oaep {
+		ciphertext, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &encrypter.publicKey, plaintext, nil)
+	} else {
+		ciphertext, err = rsa.EncryptPKCS1v15(rand.Reader, &encrypter.publicKey, plaintext)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return ciphertext
+}
+
+func (encrypter rsaEncrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	if encrypter.oaep {
+		return rsa.DecryptOAEP(sha256.New(), nil, encrypter.privakeKey, ciphertext, nil)
+	}
+	return rsa.DecryptPKCS1v15(nil, encrypter.privakeKey, ciphertext)
+}
+
+// BenchmarkRSAEncrypt benchmarks the encryption of a 32-byte key, the typical RSA encryption
+// payload.
+func BenchmarkRSAEncrypt(b *testing.B) {
+	for _, bits := range rsaKeySizes {
+		benchmarkEncrypt(fmt.Sprintf("RSA-OAEP-%d-SHA256", bits), newRSAEncrypter(b, rsaFixture(bits), true), b)
+		benchmarkEncrypt(fmt.Sprintf("RSA-PKCS-1-v1.5-%d", bits), newRSAEncrypter(b, rsaFixture(bits), false), b)
+	}
+}
+
+func BenchmarkRSADecrypt(b *testing.B) {
+	for _, bits := range rsaKeySizes {
+		benchmarkDecrypt(fmt.Sprintf("RSA-OAEP-%d-SHA256", bits), newRSAEncrypter(b, rsaFixture(bits), true), b)
+		benchmarkDecrypt(fmt.Sprintf("RSA-PKCS-1-v1.5-%d", bits), newRSAEncrypter(b, rsaFixture(bits), false), b)
+	}
+}
+
+func benchmarkEncrypt[E Encrypter](algorithm string, encrypter E, b *testing.B) {
+	b.Run(algorithm, func(b *testing.B) {
+		plaintext := utils.RandBytes(b, 32)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			encrypter.Encrypt(plaintext)
+		}
+	})
+}
+
+func benchmarkDecrypt[E Encrypter](algorithm string, encrypter E, b *testing.B) {
+	b.Run(algorithm, func(b *testing.B) {
+		ciphertext := encrypter.Encrypt(utils.RandBytes(b, 32))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := encrypter.Decrypt(ciphertext)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkRSAVerifyExponent benchmarks PKCS #1 v1.5 verification with 2048-bit keys of
+// different public exponents: the cost of the public operation grows with the size of e.
+func BenchmarkRSAVerifyExponent(b *testing.B) {
+	for _, e := range rsaExponents {
+		benchmarkVerify(64, fmt.Sprintf("RSA-PKCS-1-v1.5-2048-e=%d-SHA256", e), newRSASigner(b, rsaExponentFixture(e), false), b)
+	}
+}
+
+// BenchmarkRSAPrivateKey benchmarks PKCS #1 v1.5 signing, the raw private key operation, with and
+// without the CRT precomputations, and with a 3-prime key.
+func BenchmarkRSAPrivateKey(b *testing.B) {
+	for _, bits := range rsaKeySizes {
+		for _, precomputation := range []rsaPrecomputation{rsaCRT, rsaCRTNotPrecomputed, rsaNoCRT} {
+			signer := newRSASigner(b, rsaFixture(bits), false)
+			signer.privakeKey = withPrecomputation(signer.privakeKey, precomputation)
+			benchmarkSign(64, fmt.Sprintf("RSA-PKCS-1-v1.5-%d-%s-SHA256", bits, precomputation), signer, b)
+		}
+	}
+	// crypto/rsa doesn't use the CRT for keys of more than 2 primes
+	benchmarkSign(64, "RSA-PKCS-1-v1.5-2048-3-primes-SHA256", newRSASigner(b, rsaMultiPrimeFixture, false), b)
+}
+
+func TestRSAFixtures(t *testing.T) {
+	for _, bits := range rsaKeySizes {
+		key := loadRSAKey(t, rsaFixture(bits))
+		if key.N.BitLen() != bits || key.E != 65537 || len(key.Primes) != 2 {
+			t.Errorf("%s: expected a %d-bit key with e=65537 and 2 primes, got %d bits, e=%d and %d primes",
+				rsaFixture(bits), bits, key.N.BitLen(), key.E, len(key.Primes))
+		}
+	}
+	for _, e := range rsaExponents {
+		if key := loadRSAKey(t, rsaExponentFixture(e)); key.E != e {
+			t.Errorf("%s: expected e=%d, got %d", rsaExponentFixture(e), e, key.E)
+		}
+	}
+	if key := loadRSAKey(t, rsaMultiPrimeFixture); len(key.Primes) != 3 {
+		t.Errorf("%s: expected 3 primes, got %d", rsaMultiPrimeFixture, len(key.Primes))
+	}
+}
+
+func TestRSA(t *testing.T) {
+	message := []byte("message")
+
+	fixtures := []string{rsaMultiPrimeFixture}
+	for _, bits := range rsaKeySizes {
+		fixtures = append(fixtures, rsaFixture(bits))
+	}
+	for _, e := range rsaExponents {
+		fixtures = append(fixtures, rsaExponentFixture(e))
+	}
+
+	for _, fixture := range fixtures {
+		for _, precomputation := range []rsaPrecomputation{rsaCRT, rsaCRTNotPrecomputed, rsaNoCRT} {
+			for _, padding := range []bool{false, true} {
+				signer := newRSASigner(t, fixture, padding)
+				signer.privakeKey = withPrecomputation(signer.privakeKey, precomputation)
+				signature := signer.Sign(message)
+				if !signer.Verify(message, signature) {
+					t.Errorf("%s %s pss=%t: signature doesn't verify", fixture, precomputation, padding)
+				}
+				if signer.Verify([]byte("other message"), signature) {
+					t.Errorf("%s %s pss=%t: signature verifies another message", fixture, precomputation, padding)
+				}
+
+				encrypter := newRSAEncrypter(t, fixture, padding)
+				encrypter.privakeKey = withPrecomputation(encrypter.privakeKey, precomputation)
+				plaintext, err := encrypter.Decrypt(encrypter.Encrypt(message))
+				if err != nil {
+					t.Fatalf("%s %s oaep=%t: %v", fixture, precomputation, padding, err)
+				}
+				if !bytes.Equal(plaintext, message) {
+					t.Errorf("%s %s oaep=%t: decrypted %q", fixture, precomputation, padding, plaintext)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package signatures
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/secp256k1"
+)
+
+// secp256k1ECDSASigner signs the SHA-256 hash of the message with ECDSA over secp256k1, with a low
+// s as in Bitcoin and Ethereum.
+type secp256k1ECDSASigner struct {
+	privateKey *secp256k1.PrivateKey
+	publicKey  *secp256k1.PublicKey
+}
+
+func newSecp256k1ECDSASigner(tb testing.TB) secp256k1ECDSASigner {
+	privateKey, err := secp256k1.GenerateKey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return secp256k1ECDSASigner{
+		privateKey: privateKey,
+		publicKey:  privateKey.PublicKey(),
+	}
+}
+
+func (signer secp256k1ECDSASigner) Sign(message []byte) []byte {
+	hash := sha256.Sum256(message)
+	signature, err := signer.privateKey.SignECDSA(hash[:])
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer secp256k1ECDSASigner) Verify(message, signature []byte) bool {
+	hash := sha256.Sum256(message)
+	return secp256k1.VerifyECDSA(signer.publicKey, hash[:], signature)
+}
+
+func (signer secp256k1ECDSASigner) PublicKeySize() int {
+	return secp256k1.PublicKeySize
+}
+
+// secp256k1SchnorrSigner is the Schnorr signature of BIP-340, with x-only public keys.
+type secp256k1SchnorrSigner struct {
+	privateKey *secp256k1.PrivateKey
+	publicKey  *secp256k1.PublicKey
+}
+
+func newSecp256k1SchnorrSigner(tb testing.TB) secp256k1SchnorrSigner {
+	privateKey, err := secp256k1.GenerateKey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return secp256k1SchnorrSigner{
+		privateKey: privateKey,
+		publicKey:  privateKey.PublicKey(),
+	}
+}
+
+func (signer secp256k1SchnorrSigner) Sign(message []byte) []byte {
+	signature, err := signer.privateKey.SignSchnorr(message)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer secp256k1SchnorrSigner) Verify(message, signature []byte) bool {
+	return secp256k1.VerifySchnorr(signer.publicKey, message, signature)
+}
+
+func (signer secp256k1SchnorrSigner) PublicKeySize() int {
+	return secp256k1.XOnlyPublicKeySize
+}
+
+// BenchmarkSecp256k1RecoverPublicKey benchmarks the recovery of the public key from a recoverable
+// ECDSA signature of a SHA-256 hash, which Ethereum does instead of a verification.
+func BenchmarkSecp256k1RecoverPublicKey(b *testing.B) {
+	privateKey, err := secp256k1.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("message"))
+	signature, err := privateKey.SignECDSARecoverable(hash[:])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := secp256k1.RecoverPublicKey(hash[:], signature); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
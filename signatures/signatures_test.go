@@ -1,16 +1,17 @@
 package signatures
 
 import (
-	"crypto"
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/ed25519"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha256"
 	"fmt"
 	"testing"
 
+	"github.com/skerkour/go-benchmarks/crypto/mldsa"
+	"github.com/skerkour/go-benchmarks/crypto/secp256k1"
+	"github.com/skerkour/go-benchmarks/crypto/slhdsa"
 	"github.com/skerkour/go-benchmarks/utils"
 )
 
@@ -19,6 +20,12 @@ type Signer interface {
 	Verify(message, signature []byte) bool
 }
 
+// A publicKeySizer is a Signer which reports the size of its encoded public key, that the sign
+// benchmarks report next to the size of the signatures.
+type publicKeySizer interface {
+	PublicKeySize() int
+}
+
 func BenchmarkSign(b *testing.B) {
 	benchmarks := []int64{
 		64,
@@ -30,11 +37,29 @@ func BenchmarkSign(b *testing.B) {
 
 	for _, size := range benchmarks {
 		benchmarkSign(size, "Ed25519", newEd25519Signer(b), b)
-		benchmarkSign(size, "ECDSA-P-256", newP256Signer(), b)
-		benchmarkSign(size, "ECDSA-P-384", newP384Signer(), b)
-		benchmarkSign(size, "ECDSA-P-521", newP521Signer(), b)
-		benchmarkSign(size, "RSA-PKCS-1-v1.5-2048-SHA256", newRsaSha256Signer(b, 4096), b)
-		benchmarkSign(size, "RSA-PKCS-1-v1.5-4096-SHA256", newRsaSha256Signer(b, 4096), b)
+		benchmarkSign(size, "Ed25519ctx", newEd25519ctxSigner(b, "benchmark"), b)
+		benchmarkSign(size, "Ed25519ph", newEd25519phSigner(b), b)
+		for _, curve := range ecdsaCurves {
+			for _, mode := range ecdsaModes {
+				benchmarkSign(size, "ECDSA-"+curve.Params().Name+mode.suffix, newECDSASigner(b, curve, mode.deterministic, mode.raw), b)
+			}
+		}
+		benchmarkSign(size, "ECDSA-secp256k1", newSecp256k1ECDSASigner(b), b)
+		benchmarkSign(size, "Schnorr-BIP340-secp256k1", newSecp256k1SchnorrSigner(b), b)
+		for _, params := range mldsaParameters {
+			benchmarkSign(size, params.String(), newMLDSASigner(b, params, false), b)
+			benchmarkSign(size, params.String()+"-deterministic", newMLDSASigner(b, params, true), b)
+		}
+		for _, scheme := range compositeSchemes {
+			benchmarkSign(size, scheme.String(), newCompositeSigner(b, scheme), b)
+		}
+		for _, params := range slhdsaParameters {
+			benchmarkSign(size, params.String(), newSLHDSASigner(b, params), b)
+		}
+		for _, bits := range rsaKeySizes {
+			benchmarkSign(size, fmt.Sprintf("RSA-PKCS-1-v1.5-%d-SHA256", bits), newRSASigner(b, rsaFixture(bits), false), b)
+			benchmarkSign(size, fmt.Sprintf("RSA-PSS-%d-SHA256", bits), newRSASigner(b, rsaFixture(bits), true), b)
+		}
 	}
 }
 
@@ -49,22 +74,165 @@ func BenchmarkVerify(b *testing.B) {
 
 	for _, size := range benchmarks {
 		benchmarkVerify(size, "Ed25519", newEd25519Signer(b), b)
-		benchmarkVerify(size, "ECDSA-P-256", newP256Signer(), b)
-		benchmarkVerify(size, "ECDSA-P-384", newP384Signer(), b)
-		benchmarkVerify(size, "ECDSA-P-521", newP521Signer(), b)
-		benchmarkVerify(size, "RSA-PKCS-1-v1.5-2048-SHA256", newRsaSha256Signer(b, 4096), b)
-		benchmarkVerify(size, "RSA-PKCS-1-v1.5-4096-SHA256", newRsaSha256Signer(b, 4096), b)
+		benchmarkVerify(size, "Ed25519ctx", newEd25519ctxSigner(b, "benchmark"), b)
+		benchmarkVerify(size, "Ed25519ph", newEd25519phSigner(b), b)
+		for _, curve := range ecdsaCurves {
+			for _, mode := range ecdsaModes {
+				// verification doesn't depend on the nonces, only on the encoding
+				if mode.deterministic {
+					continue
+				}
+				benchmarkVerify(size, "ECDSA-"+curve.Params().Name+mode.suffix, newECDSASigner(b, curve, mode.deterministic, mode.raw), b)
+			}
+		}
+		benchmarkVerify(size, "ECDSA-secp256k1", newSecp256k1ECDSASigner(b), b)
+		benchmarkVerify(size, "Schnorr-BIP340-secp256k1", newSecp256k1SchnorrSigner(b), b)
+		for _, params := range mldsaParameters {
+			benchmarkVerify(size, params.String(), newMLDSASigner(b, params, false), b)
+		}
+		for _, scheme := range compositeSchemes {
+			benchmarkVerify(size, scheme.String(), newCompositeSigner(b, scheme), b)
+		}
+		for _, params := range slhdsaParameters {
+			benchmarkVerify(size, params.String(), newSLHDSASigner(b, params), b)
+		}
+		for _, bits := range rsaKeySizes {
+			benchmarkVerify(size, fmt.Sprintf("RSA-PKCS-1-v1.5-%d-SHA256", bits), newRSASigner(b, rsaFixture(bits), false), b)
+			benchmarkVerify(size, fmt.Sprintf("RSA-PSS-%d-SHA256", bits), newRSASigner(b, rsaFixture(bits), true), b)
+		}
+	}
+}
+
+// BenchmarkKeyGen benchmarks the generation of a random key pair, as done by ephemeral-key
+// protocols or when provisioning RSA keys.
+func BenchmarkKeyGen(b *testing.B) {
+	benchmarkKeyGen("Ed25519", func() error {
+		_, _, err := ed25519.GenerateKey(nil)
+		return err
+	}, b)
+	for _, curve := range ecdsaCurves {
+		benchmarkKeyGen("ECDSA-"+curve.Params().Name, func() error {
+			_, err := ecdsa.GenerateKey(curve, rand.Reader)
+			return err
+		}, b)
+	}
+	benchmarkKeyGen("secp256k1", func() error {
+		_, err := secp256k1.GenerateKey()
+		return err
+	}, b)
+	for _, params := range mldsaParameters {
+		benchmarkKeyGen(params.String(), func() error {
+			_, err := mldsa.GenerateKey(params)
+			return err
+		}, b)
+	}
+	for _, scheme := range compositeSchemes {
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.GenerateKey()
+			return err
+		}, b)
+	}
+	for _, params := range slhdsaParameters {
+		benchmarkKeyGen(params.String(), func() error {
+			_, err := slhdsa.GenerateKey(params)
+			return err
+		}, b)
+	}
+	for _, bits := range []int{2048, 3072, 4096} {
+		benchmarkKeyGen(fmt.Sprintf("RSA-%d", bits), func() error {
+			_, err := rsa.GenerateKey(rand.Reader, bits)
+			return err
+		}, b)
 	}
 }
 
+// BenchmarkKeyGenFromSeed benchmarks the deterministic derivation of a key pair from a seed,
+// which for ECDSA is the private scalar. There is no deterministic RSA key generation in the
+// standard library.
+func BenchmarkKeyGenFromSeed(b *testing.B) {
+	ed25519Seed := utils.RandBytes(b, ed25519.SeedSize)
+	benchmarkKeyGen("Ed25519", func() error {
+		ed25519.NewKeyFromSeed(ed25519Seed)
+		return nil
+	}, b)
+	for _, curve := range ecdsaCurves {
+		privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		scalar, err := privateKey.Bytes()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkKeyGen("ECDSA-"+curve.Params().Name, func() error {
+			_, err := ecdsa.ParseRawPrivateKey(curve, scalar)
+			return err
+		}, b)
+	}
+	secp256k1PrivateKey, err := secp256k1.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	secp256k1Scalar := secp256k1PrivateKey.Bytes()
+	benchmarkKeyGen("secp256k1", func() error {
+		_, err := secp256k1.NewPrivateKey(secp256k1Scalar)
+		return err
+	}, b)
+	mldsaSeed := utils.RandBytes(b, mldsa.SeedSize)
+	for _, params := range mldsaParameters {
+		benchmarkKeyGen(params.String(), func() error {
+			_, err := mldsa.NewPrivateKey(params, mldsaSeed)
+			return err
+		}, b)
+	}
+	for _, scheme := range compositeSchemes {
+		privateKey, err := scheme.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		encoded, err := privateKey.Bytes()
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkKeyGen(scheme.String(), func() error {
+			_, err := scheme.NewPrivateKey(encoded)
+			return err
+		}, b)
+	}
+	for _, params := range slhdsaParameters {
+		slhdsaSeed := utils.RandBytes(b, int64(params.SeedSize()))
+		benchmarkKeyGen(params.String(), func() error {
+			_, err := slhdsa.NewPrivateKeyFromSeed(params, slhdsaSeed)
+			return err
+		}, b)
+	}
+}
+
+func benchmarkKeyGen(algorithm string, generateKey func() error, b *testing.B) {
+	b.Run(algorithm, func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := generateKey(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func benchmarkSign[S Signer](size int64, algorithm string, signer S, b *testing.B) {
 	b.Run(fmt.Sprintf("%s-%s", utils.BytesCount(size), algorithm), func(b *testing.B) {
 		b.ReportAllocs()
 		b.SetBytes(size)
 		buf := utils.RandBytes(b, size)
+		var signature []byte
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			signer.Sign(buf)
+			signature = signer.Sign(buf)
+		}
+		b.ReportMetric(float64(len(signature)), "signature-bytes")
+		if sizer, ok := any(signer).(publicKeySizer); ok {
+			b.ReportMetric(float64(sizer.PublicKeySize()), "public-key-bytes")
 		}
 	})
 }
@@ -82,157 +250,99 @@ func benchmarkVerify[S Signer](size int64, algorithm string, signer S, b *testin
 	})
 }
 
-type ed25519Signer struct {
-	privakeKey ed25519.PrivateKey
-	publicKey  ed25519.PublicKey
-}
-
-func newEd25519Signer(b *testing.B) (signer ed25519Signer) {
-	public, private, err := ed25519.GenerateKey(nil)
-	if err != nil {
-		b.Error(err)
+// TestSigners checks that the signatures of the benchmarked signers verify for the signed message
+// only, have the expected size and, for the deterministic signers, are the same each time.
+func TestSigners(t *testing.T) {
+	type signerTest struct {
+		name   string
+		signer Signer
+		// signatureSize is 0 for the variable-size signatures
+		signatureSize int
+		deterministic bool
 	}
-
-	signer = ed25519Signer{
-		privakeKey: private,
-		publicKey:  public,
+	tests := []signerTest{
+		{"Ed25519", newEd25519Signer(t), ed25519.SignatureSize, true},
+		{"Ed25519ctx", newEd25519ctxSigner(t, "test"), ed25519.SignatureSize, true},
+		{"Ed25519ph", newEd25519phSigner(t), ed25519.SignatureSize, true},
+		{"ECDSA-secp256k1", newSecp256k1ECDSASigner(t), secp256k1.ECDSASignatureSize, false},
+		{"Schnorr-BIP340-secp256k1", newSecp256k1SchnorrSigner(t), secp256k1.SchnorrSignatureSize, false},
 	}
-	return
-}
-
-func (signer ed25519Signer) Sign(message []byte) []byte {
-	return ed25519.Sign(signer.privakeKey, message)
-}
-
-func (signer ed25519Signer) Verify(message, signature []byte) bool {
-	return ed25519.Verify(signer.publicKey, message, signature)
-}
-
-type p256Signer struct {
-	privakeKey *ecdsa.PrivateKey
-	publicKey  ecdsa.PublicKey
-}
-
-func newP256Signer() (signer p256Signer) {
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		panic(err)
+	for _, curve := range ecdsaCurves {
+		for _, mode := range ecdsaModes {
+			signatureSize := 0
+			if mode.raw {
+				signatureSize = 2 * ecdsaScalarSize(curve)
+			}
+			tests = append(tests, signerTest{"ECDSA-" + curve.Params().Name + mode.suffix,
+				newECDSASigner(t, curve, mode.deterministic, mode.raw), signatureSize, mode.deterministic})
+		}
 	}
-
-	signer = p256Signer{
-		privakeKey: privateKey,
-		publicKey:  privateKey.PublicKey,
+	for _, params := range mldsaParameters {
+		tests = append(tests,
+			signerTest{params.String(), newMLDSASigner(t, params, false), params.SignatureSize(), false},
+			signerTest{params.String() + "-deterministic", newMLDSASigner(t, params, true), params.SignatureSize(), true},
+		)
 	}
-	return
-}
-
-func (signer p256Signer) Sign(message []byte) []byte {
-	hash := sha256.Sum256(message)
-	ret, err := ecdsa.SignASN1(rand.Reader, signer.privakeKey, hash[:])
-	if err != nil {
-		panic(err)
+	for _, scheme := range compositeSchemes {
+		// the DER encoding of the ECDSA component makes the size of the P-256 signatures vary
+		tests = append(tests, signerTest{scheme.String(), newCompositeSigner(t, scheme), 0, false})
 	}
-	return ret
-}
-
-func (signer p256Signer) Verify(message, signature []byte) bool {
-	hash := sha256.Sum256(message)
-	return ecdsa.VerifyASN1(&signer.publicKey, hash[:], signature)
-}
-
-type p384Signer struct {
-	privakeKey *ecdsa.PrivateKey
-	publicKey  ecdsa.PublicKey
-}
-
-func newP384Signer() (signer p384Signer) {
-	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
-	if err != nil {
-		panic(err)
+	for _, params := range slhdsaParameters {
+		tests = append(tests, signerTest{params.String(), newSLHDSASigner(t, params), params.SignatureSize(), false})
 	}
-
-	signer = p384Signer{
-		privakeKey: privateKey,
-		publicKey:  privateKey.PublicKey,
+	for _, bits := range rsaKeySizes {
+		tests = append(tests,
+			signerTest{fmt.Sprintf("RSA-PKCS-1-v1.5-%d-SHA256", bits), newRSASigner(t, rsaFixture(bits), false), bits / 8, true},
+			signerTest{fmt.Sprintf("RSA-PSS-%d-SHA256", bits), newRSASigner(t, rsaFixture(bits), true), bits / 8, false},
+		)
 	}
-	return
-}
 
-func (signer p384Signer) Sign(message []byte) []byte {
-	hash := sha256.Sum256(message)
-	ret, err := ecdsa.SignASN1(rand.Reader, signer.privakeKey, hash[:])
-	if err != nil {
-		panic(err)
+	message := []byte("message")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signature := test.signer.Sign(message)
+			if test.signatureSize != 0 && len(signature) != test.signatureSize {
+				t.Errorf("expected a %d-byte signature, got %d bytes", test.signatureSize, len(signature))
+			}
+			if !test.signer.Verify(message, signature) {
+				t.Error("valid signature rejected")
+			}
+			if test.signer.Verify([]byte("other message"), signature) {
+				t.Error("signature of another message accepted")
+			}
+			if test.deterministic && !bytes.Equal(test.signer.Sign(message), signature) {
+				t.Error("deterministic signer returned different signatures")
+			}
+		})
 	}
-	return ret
-}
-
-func (signer p384Signer) Verify(message, signature []byte) bool {
-	hash := sha256.Sum256(message)
-	return ecdsa.VerifyASN1(&signer.publicKey, hash[:], signature)
 }
 
-type p521Signer struct {
-	privakeKey *ecdsa.PrivateKey
-	publicKey  ecdsa.PublicKey
+type ed25519Signer struct {
+	privakeKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
 }
 
-func newP521Signer() (signer p521Signer) {
-	privateKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+func newEd25519Signer(tb testing.TB) (signer ed25519Signer) {
+	public, private, err := ed25519.GenerateKey(nil)
 	if err != nil {
-		panic(err)
+		tb.Error(err)
 	}
 
-	signer = p521Signer{
-		privakeKey: privateKey,
-		publicKey:  privateKey.PublicKey,
+	signer = ed25519Signer{
+		privakeKey: private,
+		publicKey:  public,
 	}
 	return
 }
 
-func (signer p521Signer) Sign(message []byte) []byte {
-	hash := sha256.Sum256(message)
-	ret, err := ecdsa.SignASN1(rand.Reader, signer.privakeKey, hash[:])
-	if err != nil {
-		panic(err)
-	}
-	return ret
-}
-
-func (signer p521Signer) Verify(message, signature []byte) bool {
-	hash := sha256.Sum256(message)
-	return ecdsa.VerifyASN1(&signer.publicKey, hash[:], signature)
-}
-
-type rsaSha256Signer struct {
-	privakeKey *rsa.PrivateKey
-	publicKey  rsa.PublicKey
-}
-
-func newRsaSha256Signer(b *testing.B, bits int) (signer rsaSha256Signer) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
-	if err != nil {
-		b.Error(err)
-	}
-
-	signer = rsaSha256Signer{
-		privakeKey: privateKey,
-		publicKey:  privateKey.PublicKey,
-	}
-	return
+func (signer ed25519Signer) Sign(message []byte) []byte {
+	return ed25519.Sign(signer.privakeKey, message)
 }
 
-func (signer rsaSha256Signer) Sign(message []byte) []byte {
-	sha256Hash := sha256.Sum256(message)
-	signature, err := rsa.SignPKCS1v15(nil, signer.privakeKey, crypto.SHA256, sha256Hash[:])
-	if err != nil {
-		panic(err)
-	}
-	return signature
+func (signer ed25519Signer) Verify(message, signature []byte) bool {
+	return ed25519.Verify(signer.publicKey, message, signature)
 }
 
-func (signer rsaSha256Signer) Verify(message, signature []byte) bool {
-	sha256Hash := sha256.Sum256(message)
-	err := rsa.VerifyPKCS1v15(&signer.publicKey, crypto.SHA256, sha256Hash[:], signature)
-	return err == nil
+func (signer ed25519Signer) PublicKeySize() int {
+	return len(signer.publicKey)
 }
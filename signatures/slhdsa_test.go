@@ -0,0 +1,42 @@
+package signatures
+
+import (
+	"testing"
+
+	"github.com/skerkour/go-benchmarks/crypto/slhdsa"
+)
+
+var slhdsaParameters = []*slhdsa.Parameters{slhdsa.SHA2_128s, slhdsa.SHAKE_128s, slhdsa.SHA2_128f, slhdsa.SHAKE_128f}
+
+// slhdsaSigner is pure SLH-DSA of FIPS 205 with an empty context and hedged signatures.
+type slhdsaSigner struct {
+	privateKey *slhdsa.PrivateKey
+	publicKey  *slhdsa.PublicKey
+}
+
+func newSLHDSASigner(tb testing.TB, params *slhdsa.Parameters) slhdsaSigner {
+	privateKey, err := slhdsa.GenerateKey(params)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return slhdsaSigner{
+		privateKey: privateKey,
+		publicKey:  privateKey.PublicKey(),
+	}
+}
+
+func (signer slhdsaSigner) Sign(message []byte) []byte {
+	signature, err := signer.privateKey.Sign(message, nil)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+func (signer slhdsaSigner) Verify(message, signature []byte) bool {
+	return slhdsa.Verify(signer.publicKey, message, signature, nil)
+}
+
+func (signer slhdsaSigner) PublicKeySize() int {
+	return signer.publicKey.Parameters().PublicKeySize()
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TimingLeak runs a dudect-style [1] test: class0 and class1 are run in a random order, each
+// sample timing batch calls, and it returns the largest absolute value of Welch's t statistic
+// between the two timing distributions, over the full distribution and the distributions cropped
+// at a few percentiles to remove the measurements disturbed by the scheduler or interrupts.
+//
+// A value above 4.5 is evidence that the timing depends on the class, values above
+// TimingLeakThreshold are considered a definite leak. On a shared machine, noise alone sometimes goes above 10 so the
+// test is run timingLeakRuns times and the smallest value is returned: a real leak shows up in
+// every run.
+//
+// [1] https://eprint.iacr.org/2016/1123.pdf
+func TimingLeak(samples, batch int, class0, class1 func()) float64 {
+	leak := math.Inf(1)
+	for range timingLeakRuns {
+		leak = min(leak, timingLeak(samples, batch, class0, class1))
+	}
+	return leak
+}
+
+const timingLeakRuns = 3
+
+// TimingLeakThreshold is the dudect threshold for a definite leak. The lower 4.5 threshold gives
+// false positives on shared machines.
+const TimingLeakThreshold = 10
+
+// timingTestsEnv is the environment variable which enables the timing tests: they take a few
+// seconds each and their result depends on the load of the machine.
+const timingTestsEnv = "TIMING_TESTS"
+
+func timingLeak(samples, batch int, class0, class1 func()) float64 {
+	classes := make([]bool, samples)
+	timings := make([]float64, samples)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := range classes {
+		classes[i] = rng.Intn(2) == 1
+	}
+	for i, class := range classes {
+		f := class0
+		if class {
+			f = class1
+		}
+		start := time.Now()
+		for range batch {
+			f()
+		}
+		timings[i] = float64(time.Since(start).Nanoseconds())
+	}
+
+	sorted := slices.Clone(timings)
+	slices.Sort(sorted)
+
+	maxT := 0.0
+	for _, percentile := range []float64{1, 0.9, 0.75, 0.5} {
+		threshold := sorted[int(percentile*float64(len(sorted)-1))]
+		var stats [2]welford
+		for i, timing := range timings {
+			if timing > threshold {
+				continue
+			}
+			if classes[i] {
+				stats[1].add(timing)
+			} else {
+				stats[0].add(timing)
+			}
+		}
+		maxT = max(maxT, math.Abs(welchT(stats[0], stats[1])))
+	}
+
+	return maxT
+}
+
+// welford computes the mean and variance of a distribution online.
+type welford struct {
+	n    float64
+	mean float64
+	m2   float64
+}
+
+func (w *welford) add(x float64) {
+	w.n++
+	delta := x - w.mean
+	w.mean += delta / w.n
+	w.m2 += delta * (x - w.mean)
+}
+
+func (w *welford) variance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / (w.n - 1)
+}
+
+func welchT(a, b welford) float64 {
+	if a.n < 2 || b.n < 2 {
+		return 0
+	}
+	denominator := math.Sqrt(a.variance()/a.n + b.variance()/b.n)
+	if denominator == 0 {
+		return 0
+	}
+	return (a.mean - b.mean) / denominator
+}
+
+var timingSink bool
+
+// TimingControlLeak returns the TimingLeak statistic of bytes.Equal on 4 KiB inputs that are
+// equal or differ in their first byte, a known leak since bytes.Equal returns at the first
+// difference. When it is low, the timer or the machine is too noisy for TimingLeak to detect
+// anything and timing tests should be skipped.
+func TimingControlLeak() float64 {
+	x := make([]byte, 4096)
+	equal := bytes.Clone(x)
+	different := bytes.Clone(x)
+	different[0] ^= 1
+
+	return TimingLeak(10_000, 16,
+		func() { timingSink = bytes.Equal(x, equal) },
+		func() { timingSink = bytes.Equal(x, different) },
+	)
+}
+
+// SkipTimingTest skips a timing test unless the TIMING_TESTS environment variable is set to 1,
+// and when the harness can't detect the known leak of TimingControlLeak, e.g. because the timer
+// is too coarse.
+func SkipTimingTest(tb testing.TB) {
+	tb.Helper()
+	if os.Getenv(timingTestsEnv) != "1" {
+		tb.Skipf("timing tests only run with %s=1", timingTestsEnv)
+	}
+	if leak := TimingControlLeak(); leak < TimingLeakThreshold {
+		tb.Skipf("the timing harness doesn't detect the bytes.Equal leak (t = %.1f)", leak)
+	}
+}